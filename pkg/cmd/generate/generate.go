@@ -14,6 +14,7 @@ import (
 	"github.com/DanielLiu1123/gencoder/pkg/jsruntime"
 	"github.com/DanielLiu1123/gencoder/pkg/model"
 	"github.com/DanielLiu1123/gencoder/pkg/util"
+	"github.com/dop251/goja"
 	"github.com/spf13/cobra"
 )
 
@@ -23,9 +24,10 @@ type generateOptions struct {
 	includeNonTpl bool
 
 	// Override config file gencoder.yaml
-	Templates  string
-	Properties map[string]string // Add properties, will override properties in config file
-	output     string
+	Templates      string
+	Properties     map[string]string // Add properties, will override properties in config file
+	output         string
+	schemaSnapshot string // Read tables from the snapshot instead of the databases
 }
 
 func NewCmdGenerate(globalOptions *model.GlobalOptions) *cobra.Command {
@@ -44,7 +46,10 @@ func NewCmdGenerate(globalOptions *model.GlobalOptions) *cobra.Command {
   $ gencoder generate --templates "https://github.com/user/template-project" --properties "package=com.example,author=Freeman" --include-non-tpl
 
   # Generate code using custom helpers, build-in helpers: https://github.com/DanielLiu1123/gencoder/blob/main/pkg/jsruntime/helper.js
-  $ gencoder generate --helpers helpers.js`,
+  $ gencoder generate --helpers helpers.js
+
+  # Generate code from a snapshot captured by 'gencoder introspect -o yaml > schema.yaml', no database connection is needed
+  $ gencoder generate --schema-snapshot schema.yaml`,
 		PreRun: func(cmd *cobra.Command, args []string) {
 			validateArgs(args)
 			opt.Properties = parseProperties(props)
@@ -66,6 +71,7 @@ func NewCmdGenerate(globalOptions *model.GlobalOptions) *cobra.Command {
 	c.Flags().StringVarP(&opt.Templates, "templates", "t", "", "Override templates directory, can be path or URL, e.g. https://github.com/DanielLiu1123/gencoder/tree/main/templates")
 	c.Flags().BoolVarP(&opt.includeNonTpl, "include-non-tpl", "a", false, "Include non-template files in the 'templates' option")
 	c.Flags().StringVarP(&opt.output, "output", "o", "", "Output directory for generated files, default is the current directory")
+	c.Flags().StringVar(&opt.schemaSnapshot, "schema-snapshot", "", "Read tables from a JSON/YAML snapshot written by 'gencoder introspect' instead of connecting to the databases")
 
 	return c
 }
@@ -154,6 +160,7 @@ func run(_ *cobra.Command, _ []string, opt *generateOptions, _ *model.GlobalOpti
 	}
 
 	registerPartials(files)
+	typeCatalog := registerTypeCatalog(cfg)
 
 	renderContexts := util.CollectRenderContexts(cfg, opt.Properties)
 
@@ -164,12 +171,15 @@ func run(_ *cobra.Command, _ []string, opt *generateOptions, _ *model.GlobalOpti
 	}
 
 	if len(renderContexts) > 0 {
-		generateForAllContexts(cfg, files, renderContexts)
+		generateForAllContexts(cfg, files, renderContexts, opt.Properties, typeCatalog)
 	} else {
 		properties := mergeProperties(cfg.Properties, opt.Properties)
 		renderContext := &model.RenderContext{Properties: properties, Config: cfg}
+		context := newSharedViews(typeCatalog).templateContext(renderContext)
 		for _, t := range files {
-			generateForTemplateFiles(cfg, t, renderContext)
+			if isInScope(t, model.FileScopeTable) || isInScope(t, model.FileScopeGlobal) {
+				generateForTemplateFiles(cfg, t, context)
+			}
 		}
 	}
 }
@@ -181,6 +191,9 @@ func mergeCmdOptionsToConfig(cfg *model.Config, opt *generateOptions) {
 	if opt.Templates != "" {
 		cfg.Templates = opt.Templates
 	}
+	if opt.schemaSnapshot != "" {
+		util.ApplySchemaSnapshot(cfg, opt.schemaSnapshot)
+	}
 }
 
 func loadConfig(opt *generateOptions) (*model.Config, error) {
@@ -199,12 +212,108 @@ func registerPartials(files []*model.File) {
 	}
 }
 
-func generateForAllContexts(cfg *model.Config, files []*model.File, renderContexts []*model.RenderContext) {
+// registerTypeCatalog sets the type catalog used by the _typeOf helper, it is converted once and shared by all renders.
+func registerTypeCatalog(cfg *model.Config) goja.Value {
+	typeCatalog := jsruntime.ToValue(cfg.GetTypeCatalog())
+	jsruntime.SetGlobal("typeCatalog", typeCatalog)
+	return typeCatalog
+}
+
+func generateForAllContexts(cfg *model.Config, files []*model.File, renderContexts []*model.RenderContext, cmdLineProps map[string]string, typeCatalog goja.Value) {
+	views := newSharedViews(typeCatalog)
+	var databases []*model.RenderContext
+	seen := make(map[*model.Database]bool)
 	for _, ctx := range renderContexts {
+		scope := model.FileScopeTable
+		if ctx.Routine != nil {
+			scope = model.FileScopeRoutine
+		}
+		context := views.templateContext(ctx)
+		for _, f := range files {
+			if isInScope(f, scope) {
+				generateForTemplateFiles(cfg, f, context)
+			}
+		}
+
+		if ctx.Database != nil && !seen[ctx.Database] {
+			seen[ctx.Database] = true
+			databases = append(databases, &model.RenderContext{
+				Properties:     mergeProperties(mergeProperties(cfg.Properties, ctx.DatabaseConfig.Properties), cmdLineProps),
+				Config:         cfg,
+				DatabaseConfig: ctx.DatabaseConfig,
+				Database:       ctx.Database,
+				AllTables:      ctx.AllTables,
+				Tables:         ctx.Database.Tables,
+			})
+		}
+	}
+
+	for _, ctx := range databases {
+		context := views.templateContext(ctx)
 		for _, f := range files {
-			generateForTemplateFiles(cfg, f, ctx)
+			if isInScope(f, model.FileScopeDatabase) {
+				generateForTemplateFiles(cfg, f, context)
+			}
 		}
 	}
+
+	// AllTables is the same in every context
+	global := &model.RenderContext{
+		Properties: mergeProperties(cfg.Properties, cmdLineProps),
+		Config:     cfg,
+		AllTables:  renderContexts[0].AllTables,
+		Tables:     renderContexts[0].AllTables,
+	}
+	context := views.templateContext(global)
+	for _, f := range files {
+		if isInScope(f, model.FileScopeGlobal) {
+			generateForTemplateFiles(cfg, f, context)
+		}
+	}
+}
+
+// sharedViews holds the whole-database, all-tables and type catalog views converted to JS values,
+// they are shared by many render contexts and converted once instead of with every context.
+type sharedViews struct {
+	databases   map[*model.Database]goja.Value
+	allTables   goja.Value
+	typeCatalog goja.Value
+}
+
+func newSharedViews(typeCatalog goja.Value) *sharedViews {
+	return &sharedViews{databases: make(map[*model.Database]goja.Value), typeCatalog: typeCatalog}
+}
+
+// templateContext converts the render context to the context of its templates, with the shared views attached.
+func (v *sharedViews) templateContext(ctx *model.RenderContext) map[string]interface{} {
+	context := util.ToMap(ctx)
+	if ctx.Database != nil {
+		database, ok := v.databases[ctx.Database]
+		if !ok {
+			database = jsruntime.ToValue(ctx.Database)
+			v.databases[ctx.Database] = database
+		}
+		context["database"] = database
+	}
+	// AllTables is the same in every context
+	if ctx.AllTables != nil {
+		if v.allTables == nil {
+			v.allTables = jsruntime.ToValue(ctx.AllTables)
+		}
+		context["allTables"] = v.allTables
+	}
+	if v.typeCatalog != nil {
+		context["typeCatalog"] = v.typeCatalog
+	}
+	return context
+}
+
+// isInScope reports whether the template is rendered for contexts of the scope, templates without scope are table templates.
+func isInScope(f *model.File, scope string) bool {
+	if f.Scope == "" {
+		return scope == model.FileScopeTable
+	}
+	return f.Scope == scope
 }
 
 func generateForNormalFiles(cfg *model.Config, f *model.File) {
@@ -230,12 +339,11 @@ func mergeProperties(configProps map[string]string, cmdLineProps map[string]stri
 	return merged
 }
 
-func generateForTemplateFiles(cfg *model.Config, tpl *model.File, ctx *model.RenderContext) {
+func generateForTemplateFiles(cfg *model.Config, tpl *model.File, context map[string]interface{}) {
 	if tpl.Type != model.FileTypeTemplate {
 		return
 	}
 
-	context := util.ToMap(ctx)
 	newContent := handlebars.Render(tpl.Template, context)
 	fileName := getFileName(tpl.Output, context)
 	out := filepath.Join(cfg.Output, fileName)
@@ -10,7 +10,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/DanielLiu1123/gencoder/pkg/jsruntime"
 	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/DanielLiu1123/gencoder/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -616,3 +618,204 @@ Hello, {{properties.name}}!`))
 	// Check that deprecation warning was shown
 	assert.True(t, strings.Contains(stderrOutput, "Warning: 'importHelpers' field in config file is deprecated, please use 'helpers' instead"))
 }
+
+func TestNewCmdGenerate_whenSchemaSnapshotIsSet_thenShouldGenerateWithoutDatabase(t *testing.T) {
+	workDir, err := os.MkdirTemp("", "test")
+	require.NoError(t, err)
+	defer func(path string) {
+		err := os.RemoveAll(path)
+		if err != nil {
+			t.Fatalf("failed to remove temp dir: %s", err)
+		}
+	}(workDir)
+
+	_ = os.Chdir(workDir)
+
+	createNewFile(filepath.Join(workDir, "gencoder.yaml"), []byte(`
+templates: templates
+databases:
+  - name: app
+    dsn: postgres://nobody@127.0.0.1:1/none
+    tables:
+      - name: user
+`))
+	createNewFile(filepath.Join(workDir, "schema.yaml"), []byte(`
+- table:
+    name: user
+    schema: public
+    columns:
+      - name: id
+        ordinal: 1
+        type: bigint
+  databaseConfig:
+    name: app
+`))
+	createNewFile(filepath.Join(workDir, "templates/entity.text.hbs"), []byte(`@gencoder.generated: {{table.name}}.txt
+{{#each table.columns}}{{name}} {{type}}{{/each}}`))
+
+	cmd := NewCmdGenerate(&model.GlobalOptions{})
+	cmd.SetArgs([]string{"--config", "gencoder.yaml", "--schema-snapshot", "schema.yaml"})
+
+	err = cmd.Execute()
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(workDir, "user.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, `@gencoder.generated: user.txt
+id bigint`, string(content))
+}
+
+func Test_isInScope(t *testing.T) {
+	assert.True(t, isInScope(&model.File{Scope: model.FileScopeTable}, model.FileScopeTable))
+	assert.True(t, isInScope(&model.File{}, model.FileScopeTable)) // templates without scope are table templates
+	assert.False(t, isInScope(&model.File{}, model.FileScopeGlobal))
+	assert.True(t, isInScope(&model.File{Scope: model.FileScopeRoutine}, model.FileScopeRoutine))
+	assert.False(t, isInScope(&model.File{Scope: model.FileScopeRoutine}, model.FileScopeTable))
+	assert.False(t, isInScope(&model.File{Scope: model.FileScopeDatabase}, model.FileScopeGlobal))
+}
+
+func TestNewCmdGenerate_whenTemplateUsesDatabase_thenShouldSeeOtherTables(t *testing.T) {
+	workDir := t.TempDir()
+	_ = os.Chdir(workDir)
+
+	createNewFile(filepath.Join(workDir, "gencoder.yaml"), []byte(`
+templates: templates
+databases:
+  - name: shop
+    dialect: postgres
+    ddl: [schema.sql]
+    tables:
+      - name: "*"
+`))
+	createNewFile(filepath.Join(workDir, "schema.sql"), []byte(`
+CREATE TABLE customer (id UUID PRIMARY KEY);
+CREATE TABLE "order" (id BIGINT PRIMARY KEY, customer_id UUID);`))
+	createNewFile(filepath.Join(workDir, "templates/dto.text.hbs"), []byte(`@gencoder.generated: {{table.name}}.txt
+{{database.name}} {{#each database.tables}}{{name}} {{/each}}{{database.tableMap.customer.columns.[0].type}} {{allTables.length}}`))
+
+	cmd := NewCmdGenerate(&model.GlobalOptions{})
+	cmd.SetArgs([]string{"--config", "gencoder.yaml"})
+	require.NoError(t, cmd.Execute())
+
+	content, err := os.ReadFile(filepath.Join(workDir, "order.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, `@gencoder.generated: order.txt
+shop customer order uuid 2`, string(content))
+}
+
+func TestNewCmdGenerate_whenTemplatesHaveScopes_thenDatabaseAndGlobalTemplatesRenderOnce(t *testing.T) {
+	workDir := t.TempDir()
+	_ = os.Chdir(workDir)
+
+	createNewFile(filepath.Join(workDir, "gencoder.yaml"), []byte(`
+templates: templates
+properties:
+  package: com.example
+databases:
+  - name: shop
+    dialect: postgres
+    ddl: [shop.sql]
+    properties:
+      package: com.example.shop
+    tables:
+      - name: "*"
+  - name: auth
+    dialect: mysql
+    ddl: [auth.sql]
+    tables:
+      - name: account
+`))
+	createNewFile(filepath.Join(workDir, "shop.sql"), []byte(`
+CREATE TABLE customer (id BIGINT PRIMARY KEY);
+CREATE TABLE "order" (id BIGINT PRIMARY KEY);`))
+	createNewFile(filepath.Join(workDir, "auth.sql"), []byte(`CREATE TABLE account (id INT PRIMARY KEY);`))
+	createNewFile(filepath.Join(workDir, "templates/registry.text.hbs"), []byte(`@gencoder.generated: {{database.name}}/registry.txt
+@gencoder.scope: database
+{{properties.package}}:{{#each tables}} {{name}}{{/each}}
+// @gencoder.block.start: custom
+// @gencoder.block.end: custom`))
+	createNewFile(filepath.Join(workDir, "templates/index.text.hbs"), []byte(`@gencoder.generated: index.txt
+@gencoder.scope: global
+{{properties.package}}:{{#each tables}} {{name}}{{/each}}`))
+	createNewFile(filepath.Join(workDir, "shop/registry.txt"), []byte(`old
+// @gencoder.block.start: custom
+kept
+// @gencoder.block.end: custom`))
+
+	cmd := NewCmdGenerate(&model.GlobalOptions{})
+	cmd.SetArgs([]string{"--config", "gencoder.yaml"})
+	require.NoError(t, cmd.Execute())
+
+	content, err := os.ReadFile(filepath.Join(workDir, "shop/registry.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, `old
+// @gencoder.block.start: custom
+// @gencoder.block.end: custom`, string(content))
+
+	content, err = os.ReadFile(filepath.Join(workDir, "auth/registry.txt"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "com.example: account")
+
+	content, err = os.ReadFile(filepath.Join(workDir, "index.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, `@gencoder.generated: index.txt
+@gencoder.scope: global
+com.example: customer order account`, string(content))
+}
+
+func TestNewCmdGenerate_whenTemplateUsesTypeOf_thenShouldRenderCatalogTypes(t *testing.T) {
+	workDir := t.TempDir()
+	_ = os.Chdir(workDir)
+
+	createNewFile(filepath.Join(workDir, "gencoder.yaml"), []byte(`
+templates: templates
+typeCatalog:
+  go:
+    int32: {type: int32, nullable: sql.NullInt32, import: database/sql}
+databases:
+  - dialect: postgres
+    ddl: [schema.sql]
+    tables:
+      - name: device
+`))
+	createNewFile(filepath.Join(workDir, "schema.sql"), []byte(`
+CREATE TABLE device (id BIGINT PRIMARY KEY, name TEXT, ip INET, tags TEXT[], score INT);`))
+	createNewFile(filepath.Join(workDir, "templates/types.text.hbs"), []byte(`@gencoder.generated: types.txt
+{{#each table.columns}}{{name}}: {{_typeOf 'go' this}}, {{_typeOf 'java' this}}, {{_typeOf 'ts' this}}, {{_typeOf 'ts' this nullable=false}}
+{{/each}}{{typeCatalog.go.int32.import}}`))
+
+	cmd := NewCmdGenerate(&model.GlobalOptions{})
+	cmd.SetArgs([]string{"--config", "gencoder.yaml"})
+	require.NoError(t, cmd.Execute())
+
+	content, err := os.ReadFile(filepath.Join(workDir, "types.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, `@gencoder.generated: types.txt
+id: int64, long, number, number
+name: *string, String, string | null, string
+ip: *netip.Addr, Object, unknown | null, unknown
+tags: []string, List<String>, string[] | null, string[]
+score: sql.NullInt32, Integer, number | null, number
+database/sql`, string(content))
+}
+
+func Test_sharedViews_whenContextsShareDatabase_thenShouldConvertViewsOnce(t *testing.T) {
+	shop := &model.Database{Name: "shop", Dialect: "postgres", Tables: []*model.Table{{Name: "customer"}, {Name: "order"}}}
+	auth := &model.Database{Name: "auth", Dialect: "mysql", Tables: []*model.Table{{Name: "account"}}}
+	allTables := append(append([]*model.Table{}, shop.Tables...), auth.Tables...)
+
+	views := newSharedViews(jsruntime.ToValue(model.BuiltinTypeCatalog()))
+	customer := views.templateContext(&model.RenderContext{Table: shop.Tables[0], Database: shop, AllTables: allTables})
+	order := views.templateContext(&model.RenderContext{Table: shop.Tables[1], Database: shop, AllTables: allTables})
+	account := views.templateContext(&model.RenderContext{Table: auth.Tables[0], Database: auth, AllTables: allTables})
+
+	assert.Len(t, views.databases, 2)
+	assert.Same(t, customer["database"], order["database"])
+	assert.NotSame(t, customer["database"], account["database"])
+	assert.Same(t, customer["allTables"], account["allTables"])
+	assert.Same(t, customer["typeCatalog"], account["typeCatalog"])
+
+	// The views are not serialized with the render context
+	assert.NotContains(t, util.ToMap(&model.RenderContext{Database: shop, AllTables: allTables}), "database")
+	assert.NotContains(t, util.ToMap(&model.RenderContext{Database: shop, AllTables: allTables}), "allTables")
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// ParseAnnotations splits the comment into the annotations matched by the pattern and the description left without them.
+//
+// The pattern must have a name group and can have a value group, annotations without value are set to true,
+// the last one wins if an annotation is repeated.
+func ParseAnnotations(comment string, pattern *regexp.Regexp) (map[string]string, string) {
+	nameIdx, valueIdx := pattern.SubexpIndex("name"), pattern.SubexpIndex("value")
+
+	annotations := make(map[string]string)
+	for _, m := range pattern.FindAllStringSubmatchIndex(comment, -1) {
+		if nameIdx < 0 || m[2*nameIdx] < 0 {
+			continue
+		}
+		value := "true"
+		if valueIdx >= 0 && m[2*valueIdx] >= 0 {
+			value = strings.TrimSpace(comment[m[2*valueIdx]:m[2*valueIdx+1]])
+		}
+		annotations[comment[m[2*nameIdx]:m[2*nameIdx+1]]] = value
+	}
+
+	description := pattern.ReplaceAllString(comment, " ")
+	lines := strings.Split(description, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return annotations, strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// FillAnnotations sets the annotations and description of the table and its columns from their comments.
+func FillAnnotations(table *model.Table, pattern *regexp.Regexp) {
+	table.Annotations, table.Description = parseCommentAnnotations(table.Comment, pattern)
+	for _, col := range table.Columns {
+		col.Annotations, col.Description = parseCommentAnnotations(col.Comment, pattern)
+	}
+}
+
+func parseCommentAnnotations(comment *string, pattern *regexp.Regexp) (map[string]string, *string) {
+	if comment == nil {
+		return map[string]string{}, nil
+	}
+	annotations, description := ParseAnnotations(*comment, pattern)
+	return annotations, &description
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var annotationPattern = regexp.MustCompile(model.Config{}.GetAnnotation())
+
+func TestParseAnnotations(t *testing.T) {
+	tests := []struct {
+		name            string
+		comment         string
+		wantAnnotations map[string]string
+		wantDescription string
+	}{
+		{name: "value and flag", comment: "User name @json(userName) @mask", wantAnnotations: map[string]string{"json": "userName", "mask": "true"}, wantDescription: "User name"},
+		{name: "annotation first", comment: "@deprecated Use email instead", wantAnnotations: map[string]string{"deprecated": "true"}, wantDescription: "Use email instead"},
+		{name: "dotted name", comment: "Price @validate.min( 0 ).", wantAnnotations: map[string]string{"validate.min": "0"}, wantDescription: "Price ."},
+		{name: "email is not an annotation", comment: "Contact admin@example.com", wantAnnotations: map[string]string{}, wantDescription: "Contact admin@example.com"},
+		{name: "multiline", comment: "First line @a\nSecond line", wantAnnotations: map[string]string{"a": "true"}, wantDescription: "First line\nSecond line"},
+		{name: "no annotation", comment: "Plain comment", wantAnnotations: map[string]string{}, wantDescription: "Plain comment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			annotations, description := ParseAnnotations(tt.comment, annotationPattern)
+			assert.Equal(t, tt.wantAnnotations, annotations)
+			assert.Equal(t, tt.wantDescription, description)
+		})
+	}
+}
+
+func TestParseAnnotations_whenPatternIsCustom(t *testing.T) {
+	pattern := regexp.MustCompile(`\[(?P<name>\w+)(?:=(?P<value>[^\]]*))?\]`)
+
+	annotations, description := ParseAnnotations("Status [enum=Status] [readonly]", pattern)
+
+	assert.Equal(t, map[string]string{"enum": "Status", "readonly": "true"}, annotations)
+	assert.Equal(t, "Status", description)
+}
+
+func TestFillAnnotations(t *testing.T) {
+	comment := "Users @entity(User)"
+	name := "Name @mask"
+	table := &model.Table{
+		Comment: &comment,
+		Columns: []*model.Column{{Name: "name", Comment: &name}, {Name: "id"}},
+	}
+
+	FillAnnotations(table, annotationPattern)
+
+	assert.Equal(t, "Users", *table.Description)
+	assert.Equal(t, map[string]string{"entity": "User"}, table.Annotations)
+	assert.Equal(t, "Name", *table.Columns[0].Description)
+	assert.Equal(t, "Name @mask", *table.Columns[0].Comment) // comment is kept as is
+	assert.Nil(t, table.Columns[1].Description)
+	assert.Empty(t, table.Columns[1].Annotations)
+}
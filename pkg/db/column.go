@@ -0,0 +1,173 @@
+package db
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+var (
+	nextvalRegexp      = regexp.MustCompile(`nextval\('([^']+)'(?:::regclass)?\)`)
+	nextValueForRegexp = regexp.MustCompile(`(?i)NEXT\s+VALUE\s+FOR\s+([\w.\[\]"]+)`)
+)
+
+// parseSequenceName extracts the sequence name from a sequence backed default value,
+// e.g. nextval('user_id_seq'::regclass) or NEXT VALUE FOR [dbo].[user_seq].
+func parseSequenceName(defaultValue *string) *string {
+	if defaultValue == nil {
+		return nil
+	}
+	if m := nextvalRegexp.FindStringSubmatch(*defaultValue); m != nil {
+		return &m[1]
+	}
+	if m := nextValueForRegexp.FindStringSubmatch(*defaultValue); m != nil {
+		name := strings.NewReplacer("[", "", "]", "", `"`, "").Replace(m[1])
+		return &name
+	}
+	return nil
+}
+
+// parseEnumValues extracts the labels of a MySQL enum or set column type, quotes in labels are doubled:
+//
+//	enum('a','b''c') -> [a b'c]
+func parseEnumValues(columnType string) []string {
+	start := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if start < 0 || end < start {
+		return nil
+	}
+
+	var values []string
+	var sb strings.Builder
+	inQuote := false
+	body := columnType[start+1 : end]
+	for i := 0; i < len(body); i++ {
+		ch := body[i]
+		switch {
+		case ch == '\\' && inQuote && i+1 < len(body):
+			i++
+			sb.WriteByte(body[i])
+		case ch == '\'' && inQuote && i+1 < len(body) && body[i+1] == '\'':
+			i++
+			sb.WriteByte('\'')
+		case ch == '\'':
+			if inQuote {
+				values = append(values, sb.String())
+				sb.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			sb.WriteByte(ch)
+		}
+	}
+	return values
+}
+
+// fillValueGeneration fills the fields derived from the dialect specific value generation info.
+func fillValueGeneration(col *model.Column) {
+	if col.SequenceName == nil {
+		col.SequenceName = parseSequenceName(col.DefaultValue)
+	}
+	col.IsDatabaseGenerated = col.IsAutoIncrement || col.IsIdentity || col.IsGenerated || col.SequenceName != nil
+}
+
+// commonLogicalTypes maps the base types shared by most databases to logical types,
+// dialect specific spellings are declared next to each introspector.
+var commonLogicalTypes = map[string]string{
+	"char":              model.LogicalTypeString,
+	"character":         model.LogicalTypeString,
+	"varchar":           model.LogicalTypeString,
+	"character varying": model.LogicalTypeString,
+	"nchar":             model.LogicalTypeString,
+	"nvarchar":          model.LogicalTypeString,
+	"text":              model.LogicalTypeText,
+	"clob":              model.LogicalTypeText,
+	"tinyint":           model.LogicalTypeInt8,
+	"smallint":          model.LogicalTypeInt16,
+	"int":               model.LogicalTypeInt32,
+	"integer":           model.LogicalTypeInt32,
+	"bigint":            model.LogicalTypeInt64,
+	"decimal":           model.LogicalTypeDecimal,
+	"numeric":           model.LogicalTypeDecimal,
+	"real":              model.LogicalTypeFloat32,
+	"double":            model.LogicalTypeFloat64,
+	"double precision":  model.LogicalTypeFloat64,
+	"bool":              model.LogicalTypeBool,
+	"boolean":           model.LogicalTypeBool,
+	"date":              model.LogicalTypeDate,
+	"time":              model.LogicalTypeTime,
+	"datetime":          model.LogicalTypeTimestamp,
+	"timestamp":         model.LogicalTypeTimestamp,
+	"uuid":              model.LogicalTypeUUID,
+	"json":              model.LogicalTypeJSON,
+	"binary":            model.LogicalTypeBinary,
+	"varbinary":         model.LogicalTypeBinary,
+	"blob":              model.LogicalTypeBinary,
+	"enum":              model.LogicalTypeEnum,
+}
+
+// unboundedStringTypes are the character types that become text when declared without a length.
+var unboundedStringTypes = []string{"varchar", "character varying", "nvarchar"}
+
+// fillLogicalType sets the logical type of the column from its base type,
+// dialectTypes take precedence over commonLogicalTypes, unknown types are mapped to other.
+func fillLogicalType(col *model.Column, dialectTypes map[string]string) {
+	lt := resolveLogicalType(col, dialectTypes)
+	if col.ArrayDimensions > 0 {
+		col.ElementLogicalType = &lt
+		col.LogicalType = model.LogicalTypeArray
+		return
+	}
+	col.LogicalType = lt
+}
+
+// fillDialectLogicalType sets the logical type of the column like the introspector registered for the given dburl driver name does,
+// see LogicalTypeFiller, it is used by schema sources that do not read a live database.
+func fillDialectLogicalType(col *model.Column, dialect string) {
+	if introspector, ok := GetIntrospector(dialect); ok {
+		if filler, ok := introspector.(LogicalTypeFiller); ok {
+			filler.FillLogicalType(col)
+			return
+		}
+	}
+	fillLogicalType(col, nil)
+}
+
+// RetypeColumn replaces the type of the column with a type declared in the given dialect,
+// the type details and the logical type are derived again like for a column of a DDL script.
+func RetypeColumn(col *model.Column, typ, dialect string) {
+	NewDDLSchema(dialect, "").retypeColumn(col, typ)
+	fillDialectLogicalType(col, dialect)
+}
+
+func resolveLogicalType(col *model.Column, dialectTypes map[string]string) string {
+	lt, ok := dialectTypes[col.BaseType]
+	if !ok {
+		if len(col.EnumValues) > 0 {
+			return model.LogicalTypeEnum
+		}
+		if col.UserType != nil && col.UserType.Kind == model.UserTypeKindComposite {
+			return model.LogicalTypeOther
+		}
+		if lt, ok = commonLogicalTypes[col.BaseType]; !ok {
+			return model.LogicalTypeOther
+		}
+	}
+	if lt == model.LogicalTypeString && col.MaxLength == nil && slices.Contains(unboundedStringTypes, col.BaseType) {
+		return model.LogicalTypeText
+	}
+	if col.IsUnsigned {
+		// widen unsigned integers so that all values fit
+		switch lt {
+		case model.LogicalTypeInt8:
+			return model.LogicalTypeInt16
+		case model.LogicalTypeInt16:
+			return model.LogicalTypeInt32
+		case model.LogicalTypeInt32:
+			return model.LogicalTypeInt64
+		}
+	}
+	return lt
+}
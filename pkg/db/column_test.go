@@ -0,0 +1,100 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseSequenceName(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	tests := []struct {
+		name         string
+		defaultValue *string
+		want         *string
+	}{
+		{name: "nil default", defaultValue: nil, want: nil},
+		{name: "constant default", defaultValue: strPtr("'active'"), want: nil},
+		{name: "postgres nextval", defaultValue: strPtr("nextval('user_id_seq'::regclass)"), want: strPtr("user_id_seq")},
+		{name: "postgres nextval with schema", defaultValue: strPtr("nextval('app.\"order_id_seq\"'::regclass)"), want: strPtr("app.\"order_id_seq\"")},
+		{name: "mssql next value for", defaultValue: strPtr("(NEXT VALUE FOR [dbo].[user_seq])"), want: strPtr("dbo.user_seq")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseSequenceName(tt.defaultValue))
+		})
+	}
+}
+
+func Test_parseEnumValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		columnType string
+		want       []string
+	}{
+		{name: "enum", columnType: "enum('active','inactive')", want: []string{"active", "inactive"}},
+		{name: "set", columnType: "set('read','write','admin')", want: []string{"read", "write", "admin"}},
+		{name: "escaped quote", columnType: "enum('it''s','a,b')", want: []string{"it's", "a,b"}},
+		{name: "empty label", columnType: "enum('','x')", want: []string{"", "x"}},
+		{name: "not enum", columnType: "varchar(255)", want: nil},
+		{name: "no parenthesis", columnType: "text", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseEnumValues(tt.columnType))
+		})
+	}
+}
+
+func Test_fillLogicalType(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	dialectTypes := map[string]string{"mediumint": model.LogicalTypeInt32, "set": model.LogicalTypeString}
+	tests := []struct {
+		name        string
+		col         model.Column
+		want        string
+		wantElement *string
+	}{
+		{name: "bounded string", col: model.Column{BaseType: "varchar", MaxLength: intPtr(64)}, want: model.LogicalTypeString},
+		{name: "unbounded string", col: model.Column{BaseType: "character varying"}, want: model.LogicalTypeText},
+		{name: "integer", col: model.Column{BaseType: "integer"}, want: model.LogicalTypeInt32},
+		{name: "dialect type", col: model.Column{BaseType: "mediumint"}, want: model.LogicalTypeInt32},
+		{name: "unsigned is widened", col: model.Column{BaseType: "mediumint", IsUnsigned: true}, want: model.LogicalTypeInt64},
+		{name: "enum values", col: model.Column{BaseType: "mood", EnumValues: []string{"ok"}}, want: model.LogicalTypeEnum},
+		{name: "dialect type wins over enum values", col: model.Column{BaseType: "set", EnumValues: []string{"a"}}, want: model.LogicalTypeString},
+		{name: "composite", col: model.Column{BaseType: "address", UserType: &model.UserType{Kind: model.UserTypeKindComposite}}, want: model.LogicalTypeOther},
+		{name: "unknown", col: model.Column{BaseType: "geometry"}, want: model.LogicalTypeOther},
+		{name: "array", col: model.Column{BaseType: "bigint", ArrayDimensions: 1}, want: model.LogicalTypeArray, wantElement: func() *string { s := model.LogicalTypeInt64; return &s }()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := tt.col
+			fillLogicalType(&col, dialectTypes)
+			assert.Equal(t, tt.want, col.LogicalType)
+			assert.Equal(t, tt.wantElement, col.ElementLogicalType)
+		})
+	}
+}
+
+func TestRetypeColumn(t *testing.T) {
+	col := &model.Column{Name: "status", Type: "text", BaseType: "text", LogicalType: model.LogicalTypeText}
+
+	RetypeColumn(col, "VARCHAR(64)", "postgres")
+
+	assert.Equal(t, "character varying(64)", col.Type)
+	assert.Equal(t, "character varying", col.BaseType)
+	assert.Equal(t, 64, *col.MaxLength)
+	assert.Equal(t, model.LogicalTypeString, col.LogicalType)
+
+	RetypeColumn(col, "bigint[]", "postgres")
+
+	assert.Nil(t, col.MaxLength)
+	assert.Equal(t, 1, col.ArrayDimensions)
+	assert.Equal(t, model.LogicalTypeArray, col.LogicalType)
+	assert.Equal(t, model.LogicalTypeInt64, *col.ElementLogicalType)
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+var checkTokenRegexp = regexp.MustCompile(`'(?:[^']|'')*'|[\p{L}_][\p{L}\p{N}_$]*`)
+
+// inferCheckColumns returns the columns referenced by a check expression in order of appearance,
+// used for dialects that do not record the columns of a table level check constraint.
+func inferCheckColumns(expr string, columns []*model.Column) []string {
+	var result []string
+	for _, token := range checkTokenRegexp.FindAllString(expr, -1) {
+		if strings.HasPrefix(token, "'") {
+			continue
+		}
+		for _, col := range columns {
+			if strings.EqualFold(col.Name, token) && !slices.Contains(result, col.Name) {
+				result = append(result, col.Name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// fillConstraintDetails fills the constraint info that can be derived from the rest of the table,
+// then sorts the constraints: primary key first, then unique, exclusion and check constraints, by name.
+func fillConstraintDetails(t *model.Table) {
+	for _, c := range t.Constraints {
+		if c.Type == model.ConstraintTypeCheck {
+			if len(c.Columns) == 0 && c.CheckExpression != nil {
+				c.Columns = inferCheckColumns(*c.CheckExpression, t.Columns)
+			}
+			continue
+		}
+		if c.IndexName == nil {
+			for _, idx := range t.Indexes {
+				if idx.Name == c.Name || (idx.IsPrimary && c.Type == model.ConstraintTypePrimaryKey) {
+					name := idx.Name
+					c.IndexName = &name
+					break
+				}
+			}
+		}
+	}
+
+	order := map[string]int{
+		model.ConstraintTypePrimaryKey: 0,
+		model.ConstraintTypeUnique:     1,
+		model.ConstraintTypeExclusion:  2,
+		model.ConstraintTypeCheck:      3,
+	}
+	sort.SliceStable(t.Constraints, func(i, j int) bool {
+		if order[t.Constraints[i].Type] != order[t.Constraints[j].Type] {
+			return order[t.Constraints[i].Type] < order[t.Constraints[j].Type]
+		}
+		return t.Constraints[i].Name < t.Constraints[j].Name
+	})
+}
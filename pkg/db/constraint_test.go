@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_inferCheckColumns(t *testing.T) {
+	columns := []*model.Column{{Name: "price"}, {Name: "discount"}, {Name: "status"}}
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{name: "single column", expr: "(`price` > 0)", want: []string{"price"}},
+		{name: "multiple columns in order of appearance", expr: "([discount]>=(0) AND [discount]<[price])", want: []string{"discount", "price"}},
+		{name: "case insensitive", expr: "PRICE > 0", want: []string{"price"}},
+		{name: "string literal is ignored", expr: "status IN ('price', 'discount')", want: []string{"status"}},
+		{name: "no column", expr: "1 = 1", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, inferCheckColumns(tt.expr, columns))
+		})
+	}
+}
@@ -0,0 +1,904 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+type dbmlTokenKind int
+
+const (
+	dbmlIdent  dbmlTokenKind = iota
+	dbmlQuoted               // "quoted identifier"
+	dbmlString               // 'string' or '''multi-line string'''
+	dbmlExpr                 // `expression`
+	dbmlNumber
+	dbmlSymbol // Including the relationship operators <, >, -, <>
+)
+
+type dbmlToken struct {
+	kind dbmlTokenKind
+	text string
+	line int
+}
+
+func (t dbmlToken) is(text string) bool {
+	return (t.kind == dbmlIdent || t.kind == dbmlSymbol) && strings.EqualFold(t.text, text)
+}
+
+func (t dbmlToken) isName() bool {
+	return t.kind == dbmlIdent || t.kind == dbmlQuoted
+}
+
+func tokenizeDBML(src string) ([]dbmlToken, error) {
+	var tokens []dbmlToken
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case strings.HasPrefix(src[i:], "'''"):
+			end := strings.Index(src[i+3:], "'''")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, dbmlToken{kind: dbmlString, text: trimMultilineString(src[i+3 : i+3+end]), line: line})
+			line += strings.Count(src[i:i+3+end], "\n")
+			i += end + 6
+		case c == '\'' || c == '"' || c == '`':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) && src[j+1] == c {
+					j++
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			kind := map[byte]dbmlTokenKind{'\'': dbmlString, '"': dbmlQuoted, '`': dbmlExpr}[c]
+			tokens = append(tokens, dbmlToken{kind: kind, text: sb.String(), line: line})
+			line += strings.Count(sb.String(), "\n")
+			i = j + 1
+		case isIdentPart(rune(c)): // Including colors, e.g. headercolor: #3498DB
+			j := i + 1
+			for j < len(src) && isIdentPart(rune(src[j])) {
+				j++
+			}
+			kind := dbmlIdent
+			if c >= '0' && c <= '9' {
+				kind = dbmlNumber
+				for j < len(src) && (src[j] == '.' || isIdentPart(rune(src[j]))) {
+					j++
+				}
+			}
+			tokens = append(tokens, dbmlToken{kind: kind, text: src[i:j], line: line})
+			i = j
+		case c == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9' && len(tokens) > 0 && tokens[len(tokens)-1].is(":"):
+			j := i + 1
+			for j < len(src) && (src[j] == '.' || isIdentPart(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, dbmlToken{kind: dbmlNumber, text: src[i:j], line: line})
+			i = j
+		case strings.HasPrefix(src[i:], "<>"):
+			tokens = append(tokens, dbmlToken{kind: dbmlSymbol, text: "<>", line: line})
+			i += 2
+		default:
+			tokens = append(tokens, dbmlToken{kind: dbmlSymbol, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// trimMultilineString removes the indentation shared by the lines of a triple-quoted string, like dbdiagram.io does.
+func trimMultilineString(s string) string {
+	lines := strings.Split(strings.Trim(s, "\r\n"), "\n")
+	indent := -1
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		n := len(l) - len(strings.TrimLeft(l, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i, l := range lines {
+		if len(l) >= indent && indent > 0 {
+			lines[i] = l[indent:]
+		}
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " \t\r\n")
+}
+
+// dbmlSetting is an entry of a [setting, key: value] list.
+type dbmlSetting struct {
+	key   string // Lowercase, e.g. pk, not null, default, ref
+	value *dbmlToken
+	ref   *dbmlRef // Inline relationship of a column, e.g. ref: > users.id
+}
+
+type dbmlEndpoint struct {
+	schema, table string
+	columns       []string
+}
+
+type dbmlRef struct {
+	name     string
+	from, to dbmlEndpoint // The relationship is written as from <op> to
+	op       string
+	settings []dbmlSetting
+}
+
+type dbmlColumn struct {
+	name     string
+	typ      string
+	settings []dbmlSetting
+}
+
+type dbmlIndex struct {
+	columns     []string // Expressions without their backticks, like CREATE INDEX
+	expressions []bool   // Whether the column at the same position is an expression
+	settings    []dbmlSetting
+}
+
+type dbmlTable struct {
+	schema, name, alias string
+	note                *string
+	columns             []*dbmlColumn
+	indexes             []*dbmlIndex
+}
+
+type dbmlDocument struct {
+	tables []*dbmlTable
+	enums  []*model.UserType
+	refs   []*dbmlRef
+}
+
+type dbmlParser struct {
+	tokens []dbmlToken
+	pos    int
+}
+
+func (p *dbmlParser) peek() dbmlToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return dbmlToken{kind: dbmlSymbol}
+}
+
+func (p *dbmlParser) peekAt(offset int) dbmlToken {
+	if p.pos+offset < len(p.tokens) {
+		return p.tokens[p.pos+offset]
+	}
+	return dbmlToken{kind: dbmlSymbol}
+}
+
+func (p *dbmlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *dbmlParser) next() dbmlToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *dbmlParser) accept(text string) bool {
+	if p.peek().is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *dbmlParser) expect(text string) error {
+	if t := p.next(); !t.is(text) {
+		return fmt.Errorf("line %d: expected %q, got %q", t.line, text, t.text)
+	}
+	return nil
+}
+
+func (p *dbmlParser) name() (string, error) {
+	t := p.next()
+	if !t.isName() {
+		return "", fmt.Errorf("line %d: expected a name, got %q", t.line, t.text)
+	}
+	return t.text, nil
+}
+
+// qualifiedName parses [schema.]name.
+func (p *dbmlParser) qualifiedName() (string, string, error) {
+	name, err := p.name()
+	if err != nil {
+		return "", "", err
+	}
+	if p.peek().is(".") && p.peekAt(1).isName() {
+		p.pos++
+		table, err := p.name()
+		return name, table, err
+	}
+	return "", name, nil
+}
+
+// skipBlock skips the tokens up to the closing brace of the block, the opening brace must be consumed.
+func (p *dbmlParser) skipBlock() error {
+	for depth := 1; depth > 0; {
+		if p.done() {
+			return fmt.Errorf("unexpected end of document")
+		}
+		switch t := p.next(); {
+		case t.is("{"):
+			depth++
+		case t.is("}"):
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *dbmlParser) parseDocument() (*dbmlDocument, error) {
+	doc := &dbmlDocument{}
+	for !p.done() {
+		t := p.next()
+		var err error
+		switch {
+		case t.is("Table"):
+			var table *dbmlTable
+			if table, err = p.parseTable(); err == nil {
+				doc.tables = append(doc.tables, table)
+			}
+		case t.is("Enum"):
+			var enum *model.UserType
+			if enum, err = p.parseEnum(); err == nil {
+				doc.enums = append(doc.enums, enum)
+			}
+		case t.is("Ref"):
+			var refs []*dbmlRef
+			if refs, err = p.parseRefs(); err == nil {
+				doc.refs = append(doc.refs, refs...)
+			}
+		case t.kind == dbmlIdent: // Project, TableGroup, TablePartial, Note, Records, ...
+			for !p.done() && !p.peek().is("{") {
+				p.pos++
+			}
+			if err = p.expect("{"); err == nil {
+				err = p.skipBlock()
+			}
+		default:
+			err = fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func (p *dbmlParser) parseTable() (*dbmlTable, error) {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	table := &dbmlTable{schema: schema, name: name}
+	if p.accept("as") {
+		if table.alias, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek().is("[") {
+		settings, err := p.parseSettings()
+		if err != nil {
+			return nil, err
+		}
+		table.note = settingText(settings, "note")
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for !p.accept("}") {
+		if p.done() {
+			return nil, fmt.Errorf("table %s is not closed", name)
+		}
+		switch {
+		case p.peek().is("Note") && (p.peekAt(1).is(":") || p.peekAt(1).is("{")):
+			p.pos++
+			note, err := p.parseNote()
+			if err != nil {
+				return nil, err
+			}
+			table.note = &note
+		case p.peek().is("indexes") && p.peekAt(1).is("{"):
+			p.pos += 2
+			if table.indexes, err = p.parseIndexes(); err != nil {
+				return nil, err
+			}
+		case p.peek().kind == dbmlIdent && p.peekAt(1).is("{"): // checks, records, ...
+			p.pos += 2
+			if err := p.skipBlock(); err != nil {
+				return nil, err
+			}
+		case p.peek().is("~"): // Injected table partial
+			p.pos++
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+		default:
+			col, err := p.parseColumn()
+			if err != nil {
+				return nil, err
+			}
+			table.columns = append(table.columns, col)
+		}
+	}
+	return table, nil
+}
+
+// parseNote parses the value of Note: 'text' or Note { 'text' } after the Note keyword.
+func (p *dbmlParser) parseNote() (string, error) {
+	if p.accept(":") {
+		return p.next().text, nil
+	}
+	if err := p.expect("{"); err != nil {
+		return "", err
+	}
+	note := p.next().text
+	return note, p.expect("}")
+}
+
+func (p *dbmlParser) parseColumn() (*dbmlColumn, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	col := &dbmlColumn{name: name}
+
+	typeToken := p.next()
+	if !typeToken.isName() {
+		return nil, fmt.Errorf("line %d: expected the type of column %s, got %q", typeToken.line, name, typeToken.text)
+	}
+	typ := typeToken.text
+	if p.peek().is(".") && p.peekAt(1).isName() { // Enum of another schema
+		p.pos++
+		typ += "." + p.next().text
+	}
+	if p.peek().is("(") && p.peek().line == typeToken.line {
+		var args []string
+		p.pos++
+		for !p.accept(")") {
+			if p.done() {
+				return nil, fmt.Errorf("line %d: type of column %s is not closed", typeToken.line, name)
+			}
+			if t := p.next(); !t.is(",") {
+				args = append(args, t.text)
+			}
+		}
+		typ += "(" + strings.Join(args, ",") + ")"
+	}
+	for p.peek().is("[") && p.peekAt(1).is("]") {
+		p.pos += 2
+		typ += "[]"
+	}
+	col.typ = typ
+
+	if p.peek().is("[") {
+		if col.settings, err = p.parseSettings(); err != nil {
+			return nil, err
+		}
+	}
+	return col, nil
+}
+
+// parseSettings parses a [setting, key: value, ...] list.
+func (p *dbmlParser) parseSettings() ([]dbmlSetting, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	var settings []dbmlSetting
+	for !p.accept("]") {
+		if p.done() {
+			return nil, fmt.Errorf("settings are not closed")
+		}
+		if p.accept(",") {
+			continue
+		}
+
+		var words []string
+		for !p.done() && p.peek().kind == dbmlIdent {
+			words = append(words, strings.ToLower(p.next().text))
+		}
+		if len(words) == 0 {
+			t := p.peek()
+			return nil, fmt.Errorf("line %d: unexpected %q in settings", t.line, t.text)
+		}
+		setting := dbmlSetting{key: strings.Join(words, " ")}
+		if p.accept(":") {
+			if setting.key == "ref" {
+				ref := &dbmlRef{}
+				op := p.next()
+				ref.op = op.text
+				to, err := p.parseEndpoint()
+				if err != nil {
+					return nil, err
+				}
+				ref.to = to
+				setting.ref = ref
+			} else {
+				value := p.next()
+				// Unquoted multi-word values, e.g. delete: set null
+				for value.kind == dbmlIdent && p.peek().kind == dbmlIdent {
+					value.text += " " + p.next().text
+				}
+				setting.value = &value
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+func (p *dbmlParser) parseIndexes() ([]*dbmlIndex, error) {
+	var indexes []*dbmlIndex
+	for !p.accept("}") {
+		if p.done() {
+			return nil, fmt.Errorf("indexes are not closed")
+		}
+		idx := &dbmlIndex{}
+		t := p.next()
+		switch {
+		case t.is("("):
+			for !p.accept(")") {
+				if p.done() {
+					return nil, fmt.Errorf("line %d: index columns are not closed", t.line)
+				}
+				c := p.next()
+				switch {
+				case c.is(","):
+				default:
+					idx.columns = append(idx.columns, c.text)
+					idx.expressions = append(idx.expressions, c.kind == dbmlExpr)
+				}
+			}
+		case t.kind == dbmlExpr:
+			idx.columns = []string{t.text}
+			idx.expressions = []bool{true}
+		case t.isName():
+			idx.columns = []string{t.text}
+			idx.expressions = []bool{false}
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q in indexes", t.line, t.text)
+		}
+		if p.peek().is("[") {
+			settings, err := p.parseSettings()
+			if err != nil {
+				return nil, err
+			}
+			idx.settings = settings
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
+
+func (p *dbmlParser) parseEnum() (*model.UserType, error) {
+	schema, name, err := p.qualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	enum := &model.UserType{Schema: schema, Name: name, Kind: model.UserTypeKindEnum}
+	for !p.accept("}") {
+		if p.done() {
+			return nil, fmt.Errorf("enum %s is not closed", name)
+		}
+		value := p.next()
+		if !value.isName() {
+			return nil, fmt.Errorf("line %d: unexpected %q in enum %s", value.line, value.text, name)
+		}
+		enum.EnumValues = append(enum.EnumValues, value.text)
+		if p.peek().is("[") {
+			if _, err := p.parseSettings(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return enum, nil
+}
+
+// parseRefs parses Ref [name]: a.x < b.y [settings] and the block form Ref [name] { ... } after the Ref keyword.
+func (p *dbmlParser) parseRefs() ([]*dbmlRef, error) {
+	var name string
+	if p.peek().isName() {
+		name = p.next().text
+	}
+	if p.accept(":") {
+		ref, err := p.parseRef(name)
+		if err != nil {
+			return nil, err
+		}
+		return []*dbmlRef{ref}, nil
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var refs []*dbmlRef
+	for !p.accept("}") {
+		if p.done() {
+			return nil, fmt.Errorf("ref %s is not closed", name)
+		}
+		ref, err := p.parseRef(name)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (p *dbmlParser) parseRef(name string) (*dbmlRef, error) {
+	from, err := p.parseEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	to, err := p.parseEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	ref := &dbmlRef{name: name, from: from, op: op.text, to: to}
+	if p.peek().is("[") {
+		if ref.settings, err = p.parseSettings(); err != nil {
+			return nil, err
+		}
+	}
+	return ref, nil
+}
+
+// parseEndpoint parses [schema.]table.column and [schema.]table.(column, ...).
+func (p *dbmlParser) parseEndpoint() (dbmlEndpoint, error) {
+	var parts []string
+	var e dbmlEndpoint
+	for {
+		if p.accept("(") {
+			for !p.accept(")") {
+				if p.done() {
+					return e, fmt.Errorf("ref columns are not closed")
+				}
+				if t := p.next(); t.isName() {
+					e.columns = append(e.columns, t.text)
+				}
+			}
+			break
+		}
+		name, err := p.name()
+		if err != nil {
+			return e, err
+		}
+		parts = append(parts, name)
+		if !p.accept(".") {
+			break
+		}
+	}
+	if len(e.columns) == 0 {
+		if len(parts) < 2 {
+			return e, fmt.Errorf("invalid ref endpoint %s", strings.Join(parts, "."))
+		}
+		e.columns = []string{parts[len(parts)-1]}
+		parts = parts[:len(parts)-1]
+	}
+	switch len(parts) {
+	case 1:
+		e.table = parts[0]
+	case 2:
+		e.schema, e.table = parts[0], parts[1]
+	default:
+		return e, fmt.Errorf("invalid ref endpoint %s", strings.Join(parts, "."))
+	}
+	return e, nil
+}
+
+func settingText(settings []dbmlSetting, key string) *string {
+	for _, s := range settings {
+		if s.key == key && s.value != nil {
+			text := s.value.text
+			return &text
+		}
+	}
+	return nil
+}
+
+func hasSetting(settings []dbmlSetting, keys ...string) bool {
+	for _, s := range settings {
+		for _, k := range keys {
+			if s.key == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dbmlDatabaseTypes maps Project database_type to dburl driver names.
+var dbmlDatabaseTypes = map[string]string{
+	"postgresql": "postgres",
+	"postgres":   "postgres",
+	"mysql":      "mysql",
+	"sql server": "sqlserver",
+	"mssql":      "sqlserver",
+	"sqlite":     "sqlite3",
+}
+
+var dbmlDatabaseTypeRegexp = regexp.MustCompile(`(?i)database_type\s*:\s*['"]([^'"]+)['"]`)
+
+// DBMLDialect returns the dialect declared by the database_type of the Project of a DBML document,
+// postgres if there is none, which is the default of dbdiagram.io.
+func DBMLDialect(content string) string {
+	if m := dbmlDatabaseTypeRegexp.FindStringSubmatch(content); m != nil {
+		if dialect, ok := dbmlDatabaseTypes[strings.ToLower(m[1])]; ok {
+			return dialect
+		}
+	}
+	return "postgres"
+}
+
+// ParseDBML parses the tables of a DBML document, see DDLSchema.ExecDBML,
+// an empty dialect is read from the database_type of the Project, an empty default schema is the one of the dialect.
+func ParseDBML(content, dialect, defaultSchema string) ([]*model.Table, error) {
+	if dialect == "" {
+		dialect = DBMLDialect(content)
+	}
+	if introspector, ok := GetIntrospector(dialect); ok && defaultSchema == "" {
+		defaultSchema = introspector.DefaultSchema(nil)
+	}
+	s := NewDDLSchema(dialect, defaultSchema)
+	if err := s.ExecDBML(content); err != nil {
+		return nil, err
+	}
+	return s.Tables(), nil
+}
+
+// ExecDBML adds the tables of a DBML (https://dbml.dbdiagram.io) document to the schema, so tables designed in dbdiagram.io
+// get the type details and constraint names of the dialect: columns with their settings (pk, unique, not null, increment,
+// default, note), table notes as comments, indexes, enums, and relationships declared by Ref or inline ref settings.
+// Many-to-many relationships (<>) need a junction table and are ignored.
+func (s *DDLSchema) ExecDBML(content string) error {
+	tokens, err := tokenizeDBML(content)
+	if err != nil {
+		return err
+	}
+	doc, err := (&dbmlParser{tokens: tokens}).parseDocument()
+	if err != nil {
+		return err
+	}
+
+	for _, enum := range doc.enums {
+		if enum.Schema == "" {
+			enum.Schema = s.defaultSchema
+		}
+		s.enums[strings.ToLower(enum.Schema+"."+enum.Name)] = enum
+	}
+
+	aliases := make(map[string]*model.Table)
+	refs := doc.refs
+	for _, dt := range doc.tables {
+		schema := dt.schema
+		if schema == "" {
+			schema = s.defaultSchema
+		}
+		if s.findTable(schema, dt.name) != nil {
+			return fmt.Errorf("table %s already exists", dt.name)
+		}
+		t := &model.Table{Name: dt.name, Schema: schema, Kind: model.TableKindTable, Comment: dt.note, Indexes: []*model.Index{}}
+		s.tables = append(s.tables, t)
+		if dt.alias != "" {
+			aliases[dt.alias] = t
+		}
+
+		var pk []string
+		for _, dc := range dt.columns {
+			col := s.addDBMLColumn(t, dc)
+			if hasSetting(dc.settings, "pk", "primary key") {
+				pk = append(pk, col.Name)
+			}
+			for _, setting := range dc.settings {
+				if setting.ref != nil {
+					ref := *setting.ref
+					ref.from = dbmlEndpoint{schema: dt.schema, table: dt.name, columns: []string{dc.name}}
+					refs = append(refs, &ref)
+				}
+			}
+		}
+		if len(pk) > 0 {
+			s.addPrimaryKey(t, "", pk)
+		}
+		for _, dc := range dt.columns {
+			if hasSetting(dc.settings, "unique") {
+				s.addUnique(t, "", "", []string{dc.name})
+			}
+		}
+		for _, idx := range dt.indexes {
+			s.addDBMLIndex(t, idx)
+		}
+		for _, col := range t.Columns {
+			s.finishColumn(t, col)
+		}
+	}
+
+	for _, ref := range refs {
+		if err := s.addDBMLRef(ref, aliases); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DDLSchema) addDBMLColumn(t *model.Table, dc *dbmlColumn) *model.Column {
+	col := &model.Column{Name: dc.name, Ordinal: len(t.Columns) + 1, IsNullable: true}
+	t.Columns = append(t.Columns, col)
+
+	schema, name := s.splitName(strings.Split(strings.TrimSuffix(dc.typ, "[]"), "."))
+	if enum, ok := s.enums[strings.ToLower(schema+"."+name)]; ok {
+		dims := strings.Count(dc.typ, "[]")
+		col.BaseType = enum.Name
+		col.EnumValues = enum.EnumValues
+		col.UserType = enum
+		col.ArrayDimensions = dims
+		col.Type = enum.Name + strings.Repeat("[]", dims)
+	} else {
+		s.fillColumnType(col, dc.typ)
+	}
+
+	for _, setting := range dc.settings {
+		switch setting.key {
+		case "not null":
+			col.IsNullable = false
+		case "null":
+			col.IsNullable = true
+		case "note":
+			col.Comment = settingText([]dbmlSetting{setting}, "note")
+		case "default":
+			if setting.value == nil || setting.value.is("null") {
+				continue
+			}
+			value := setting.value.text
+			if setting.value.kind == dbmlString && s.dialect != "mysql" {
+				value = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+			}
+			col.DefaultValue = &value
+		case "increment":
+			s.setIncrement(t, col)
+		}
+	}
+	return col
+}
+
+// setIncrement makes the column generated the way the DBML exporters of dbdiagram.io declare increment columns:
+// serial in PostgreSQL, IDENTITY in SQL Server and auto increment in MySQL and SQLite.
+func (s *DDLSchema) setIncrement(t *model.Table, col *model.Column) {
+	switch s.dialect {
+	case "postgres":
+		seq := t.Name + "_" + col.Name + "_seq"
+		defaultValue := "nextval('" + seq + "'::regclass)"
+		qualified := t.Schema + "." + seq
+		col.DefaultValue = &defaultValue
+		col.SequenceName = &qualified
+		col.IsNullable = false
+	case "sqlserver":
+		setIdentity(col, "always")
+	default:
+		col.IsAutoIncrement = true
+	}
+}
+
+func (s *DDLSchema) addDBMLIndex(t *model.Table, idx *dbmlIndex) {
+	name := ""
+	if n := settingText(idx.settings, "name"); n != nil {
+		name = *n
+	}
+	switch {
+	case hasSetting(idx.settings, "pk"):
+		s.addPrimaryKey(t, name, idx.columns)
+	case hasSetting(idx.settings, "unique"):
+		s.addUnique(t, name, "", idx.columns)
+	default:
+		if name == "" {
+			name = s.defaultIndexName(t, idx)
+		}
+		s.addIndex(t, name, false, false, idx.columns)
+	}
+}
+
+// defaultIndexName returns the name PostgreSQL gives to an unnamed index, e.g. post_user_id_idx,
+// which the DBML exporters of dbdiagram.io leave to the database.
+func (s *DDLSchema) defaultIndexName(t *model.Table, idx *dbmlIndex) string {
+	var parts []string
+	for i, c := range idx.columns {
+		if idx.expressions[i] {
+			parts = append(parts, "expr")
+			continue
+		}
+		parts = append(parts, c)
+	}
+	return t.Name + "_" + strings.Join(parts, "_") + "_idx"
+}
+
+// addDBMLRef adds the foreign key of a relationship, the many side (or the second endpoint of a one-to-one) references the other.
+func (s *DDLSchema) addDBMLRef(ref *dbmlRef, aliases map[string]*model.Table) error {
+	from, to := ref.from, ref.to
+	switch ref.op {
+	case ">":
+	case "<", "-":
+		from, to = to, from
+	case "<>":
+		return nil
+	default:
+		return fmt.Errorf("unknown relationship %q", ref.op)
+	}
+
+	find := func(e dbmlEndpoint) *model.Table {
+		if e.schema == "" {
+			if t, ok := aliases[e.table]; ok {
+				return t
+			}
+		}
+		schema := e.schema
+		if schema == "" {
+			schema = s.defaultSchema
+		}
+		return s.findTable(schema, e.table)
+	}
+	t, target := find(from), find(to)
+	if t == nil {
+		return fmt.Errorf("ref: table %s does not exist", from.table)
+	}
+	if target == nil {
+		return fmt.Errorf("ref: table %s does not exist", to.table)
+	}
+
+	fk := &model.ForeignKey{
+		Name:              ref.name,
+		Schema:            t.Schema,
+		Table:             t.Name,
+		Columns:           from.columns,
+		ReferencedSchema:  target.Schema,
+		ReferencedTable:   target.Name,
+		ReferencedColumns: to.columns,
+		OnDelete:          "NO ACTION",
+		OnUpdate:          "NO ACTION",
+	}
+	if action := settingText(ref.settings, "delete"); action != nil {
+		fk.OnDelete = strings.ToUpper(*action)
+	}
+	if action := settingText(ref.settings, "update"); action != nil {
+		fk.OnUpdate = strings.ToUpper(*action)
+	}
+	if fk.Name == "" {
+		fk.Name = s.defaultConstraintName(t, "fk", fk.Columns, fk.ReferencedTable)
+	}
+	t.ForeignKeys = append(t.ForeignKeys, fk)
+	return nil
+}
@@ -0,0 +1,186 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const blogDBML = `
+Project blog {
+  database_type: 'PostgreSQL'
+  Note: '''
+    # Blog
+    The blog database
+  '''
+}
+
+Enum post_status {
+  draft
+  published [note: 'Visible to everyone']
+}
+
+// Registered users
+Table users as U [headercolor: #3498DB] {
+  id integer [pk, increment]
+  email varchar(128) [not null, unique, note: 'Login email']
+  "display name" varchar
+  created_at timestamp [default: ` + "`now()`" + `]
+  Note: 'User accounts'
+}
+
+Table posts {
+  id bigint [pk]
+  user_id integer [not null, ref: > U.id]
+  status post_status [default: 'draft']
+  price decimal(10, 2)
+  tags text[]
+
+  indexes {
+    user_id
+    (user_id, status) [unique, name: 'uk_posts_user_status']
+  }
+}
+
+Table comments {
+  id integer [pk]
+  post_id bigint
+}
+
+Ref fk_comments_post: posts.id < comments.post_id [delete: cascade]
+Ref: posts.id <> users.id
+
+TableGroup content {
+  posts
+  comments
+}
+`
+
+func TestParseDBML(t *testing.T) {
+	tables, err := ParseDBML(blogDBML, "", "")
+	require.NoError(t, err)
+	require.Len(t, tables, 3)
+
+	users, posts, comments := tables[0], tables[1], tables[2]
+	assert.Equal(t, "users", users.Name)
+	assert.Equal(t, "public", users.Schema)
+	assert.Equal(t, "User accounts", *users.Comment)
+
+	id := users.Columns[0]
+	assert.True(t, id.IsPrimaryKey)
+	assert.False(t, id.IsNullable)
+	assert.Equal(t, "integer", id.Type)
+	assert.Equal(t, "nextval('users_id_seq'::regclass)", *id.DefaultValue)
+	assert.True(t, id.IsDatabaseGenerated)
+
+	email := users.Columns[1]
+	assert.Equal(t, "character varying(128)", email.Type)
+	assert.Equal(t, 128, *email.MaxLength)
+	assert.False(t, email.IsNullable)
+	assert.Equal(t, "Login email", *email.Comment)
+
+	displayName := users.Columns[2]
+	assert.Equal(t, "display name", displayName.Name)
+	assert.True(t, displayName.IsNullable)
+	assert.Equal(t, model.LogicalTypeText, displayName.LogicalType)
+	assert.Equal(t, "now()", *users.Columns[3].DefaultValue)
+	assert.Equal(t, "timestamp without time zone", users.Columns[3].Type)
+
+	require.Len(t, users.Constraints, 2)
+	assert.Equal(t, "users_pkey", users.Constraints[0].Name)
+	assert.Equal(t, "users_email_key", users.Constraints[1].Name)
+	assert.Equal(t, "users_email_key", *users.Constraints[1].IndexName)
+
+	status := posts.Columns[2]
+	assert.Equal(t, "post_status", status.Type)
+	assert.Equal(t, []string{"draft", "published"}, status.EnumValues)
+	assert.Equal(t, model.LogicalTypeEnum, status.LogicalType)
+	assert.Equal(t, "'draft'", *status.DefaultValue)
+	assert.Equal(t, "numeric(10,2)", posts.Columns[3].Type)
+	assert.Equal(t, 2, *posts.Columns[3].NumericScale)
+	assert.Equal(t, model.LogicalTypeArray, posts.Columns[4].LogicalType)
+
+	var indexes []string
+	for _, idx := range posts.Indexes {
+		indexes = append(indexes, idx.Name)
+	}
+	assert.Equal(t, []string{"posts_pkey", "uk_posts_user_status", "posts_user_id_idx"}, indexes)
+
+	require.Len(t, posts.ForeignKeys, 1)
+	assert.Equal(t, &model.ForeignKey{
+		Name:              "posts_user_id_fkey",
+		Schema:            "public",
+		Table:             "posts",
+		Columns:           []string{"user_id"},
+		ReferencedSchema:  "public",
+		ReferencedTable:   "users",
+		ReferencedColumns: []string{"id"},
+		OnDelete:          "NO ACTION",
+		OnUpdate:          "NO ACTION",
+	}, posts.ForeignKeys[0])
+
+	require.Len(t, comments.ForeignKeys, 1)
+	assert.Equal(t, "fk_comments_post", comments.ForeignKeys[0].Name)
+	assert.Equal(t, []string{"post_id"}, comments.ForeignKeys[0].Columns)
+	assert.Equal(t, "posts", comments.ForeignKeys[0].ReferencedTable)
+	assert.Equal(t, "CASCADE", comments.ForeignKeys[0].OnDelete)
+	assert.Empty(t, users.ForeignKeys)
+}
+
+func TestParseDBML_whenDialectIsMySQL(t *testing.T) {
+	tables, err := ParseDBML(`
+		Table shop.orders {
+			id int [pk, increment]
+			note varchar(255) [default: 'none']
+			paid boolean
+		}`, "mysql", "")
+	require.NoError(t, err)
+
+	orders := tables[0]
+	assert.Equal(t, "shop", orders.Schema)
+	assert.True(t, orders.Columns[0].IsAutoIncrement)
+	assert.Equal(t, "PRIMARY", orders.Constraints[0].Name)
+	assert.Equal(t, "none", *orders.Columns[1].DefaultValue)
+	assert.Equal(t, "tinyint(1)", orders.Columns[2].Type)
+}
+
+func TestParseDBML_whenIndexHasExpression_thenSameColumnsAsDDL(t *testing.T) {
+	tables, err := ParseDBML(`
+		Table users {
+			uid int
+			a varchar(64)
+			Indexes {
+				(uid, `+"`lower(a)`"+`) [unique, name: 'users_uid_lower_a_key']
+				`+"`upper(a)`"+`
+			}
+		}`, "postgres", "public")
+	require.NoError(t, err)
+	ddlTables, err := ParseDDL(`
+		CREATE TABLE users (uid int, a varchar(64));
+		CREATE UNIQUE INDEX users_uid_lower_a_key ON users (uid, lower(a));`, "postgres", "public")
+	require.NoError(t, err)
+
+	indexes := make(map[string]*model.Index)
+	for _, idx := range tables[0].Indexes {
+		indexes[idx.Name] = idx
+	}
+	require.Len(t, indexes, 2)
+	assert.Equal(t, ddlTables[0].Indexes[0].Columns, indexes["users_uid_lower_a_key"].Columns)
+	assert.Equal(t, "upper(a)", indexes["users_expr_idx"].Columns[0].Name)
+}
+
+func TestParseDBML_whenRefTableDoesNotExist_thenReturnError(t *testing.T) {
+	_, err := ParseDBML(`
+		Table posts {
+			user_id int [ref: > users.id]
+		}`, "postgres", "public")
+	assert.ErrorContains(t, err, "users")
+}
+
+func TestDBMLDialect(t *testing.T) {
+	assert.Equal(t, "mysql", DBMLDialect(`Project p { database_type: 'MySQL' }`))
+	assert.Equal(t, "sqlserver", DBMLDialect(`Project p { database_type: "SQL Server" }`))
+	assert.Equal(t, "postgres", DBMLDialect(`Table t { id int }`))
+}
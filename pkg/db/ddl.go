@@ -0,0 +1,1366 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// DDLSchema is an in-memory schema built by applying DDL statements instead of reading a live database.
+//
+// It understands CREATE/ALTER/DROP TABLE, CREATE/DROP INDEX, RENAME, COMMENT ON and CREATE TYPE ... AS ENUM
+// in the MySQL, PostgreSQL and T-SQL flavours, T-SQL comments and renames are read from sp_addextendedproperty and sp_rename calls,
+// so both schema dumps and migration scripts can be applied.
+type DDLSchema struct {
+	dialect       string // dburl driver name, e.g. mysql, postgres, sqlserver, sqlite3
+	defaultSchema string // Schema of unqualified names
+	tables        []*model.Table
+	withoutRowid  bool                       // Whether the SQLite table being created is a WITHOUT ROWID table
+	skippedParts  []string                   // Unsupported parts of the statement being applied
+	enums         map[string]*model.UserType // Enum types declared by CREATE TYPE, keyed by schema.name
+}
+
+// NewDDLSchema creates an empty schema, dialect is a dburl driver name (mysql, postgres, sqlserver, sqlite3) that decides
+// identifier folding, type normalization and the default names of constraints, it can be empty for a generic schema.
+func NewDDLSchema(dialect, defaultSchema string) *DDLSchema {
+	return &DDLSchema{
+		dialect:       dialect,
+		defaultSchema: defaultSchema,
+		enums:         make(map[string]*model.UserType),
+	}
+}
+
+// ParseDDL parses the tables declared by the DDL script, see DDLSchema.
+func ParseDDL(script, dialect, defaultSchema string) ([]*model.Table, error) {
+	s := NewDDLSchema(dialect, defaultSchema)
+	if _, err := s.Exec(script); err != nil {
+		return nil, err
+	}
+	return s.Tables(), nil
+}
+
+// errSkipStatement reports a statement that does not change the tables of the schema.
+var errSkipStatement = errors.New("statement skipped")
+
+// Exec applies the statements of the SQL script in order,
+// statements that do not change the tables (e.g. INSERT, CREATE VIEW) are returned as skipped,
+// so are the unsupported actions of an ALTER TABLE statement (e.g. OWNER TO).
+func (s *DDLSchema) Exec(script string) (skipped []string, err error) {
+	for _, tokens := range splitSQLStatements(script, s.dialect) {
+		p := &ddlParser{src: script, tokens: tokens, dialect: s.dialect}
+		s.skippedParts = nil
+		err := s.execStatement(p)
+		if errors.Is(err, errSkipStatement) {
+			skipped = append(skipped, p.text(tokens))
+			continue
+		}
+		if err != nil {
+			return skipped, fmt.Errorf("failed to parse statement %q: %w", abbreviate(p.text(tokens), 80), err)
+		}
+		skipped = append(skipped, s.skippedParts...)
+	}
+	return skipped, nil
+}
+
+// Tables returns the tables in declaration order.
+func (s *DDLSchema) Tables() []*model.Table {
+	for _, t := range s.tables {
+		sortIndexes(t.Indexes)
+	}
+	return s.tables
+}
+
+func (s *DDLSchema) execStatement(p *ddlParser) error {
+	switch {
+	case p.acceptKeyword("CREATE"):
+		p.acceptKeyword("OR", "REPLACE")
+		for _, modifier := range []string{"GLOBAL", "LOCAL", "TEMP", "TEMPORARY", "UNLOGGED"} {
+			p.acceptKeyword(modifier)
+		}
+		switch {
+		case p.acceptKeyword("TABLE"):
+			return s.execCreateTable(p)
+		case p.peekKeyword("UNIQUE"), p.peekKeyword("CLUSTERED"), p.peekKeyword("NONCLUSTERED"), p.peekKeyword("FULLTEXT"), p.peekKeyword("SPATIAL"), p.peekKeyword("INDEX"):
+			return s.execCreateIndex(p)
+		case p.acceptKeyword("TYPE"):
+			return s.execCreateType(p)
+		}
+	case p.acceptKeyword("ALTER", "TABLE"):
+		return s.execAlterTable(p)
+	case p.acceptKeyword("COMMENT", "ON"):
+		return s.execCommentOn(p)
+	case p.acceptKeyword("EXEC"), p.acceptKeyword("EXECUTE"):
+		return s.execProcedure(p)
+	case p.acceptKeyword("DROP", "TABLE"):
+		return s.execDropTable(p)
+	case p.acceptKeyword("DROP", "INDEX"):
+		return s.execDropIndex(p)
+	case p.acceptKeyword("RENAME", "TABLE"):
+		return s.execRenameTable(p)
+	case p.acceptKeyword("ALTER", "INDEX"):
+		return s.execAlterIndex(p)
+	}
+	return errSkipStatement
+}
+
+func (s *DDLSchema) execCreateTable(p *ddlParser) error {
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+	schema, name, err := s.parseTableName(p)
+	if err != nil {
+		return err
+	}
+	if !p.peekSymbol("(") {
+		return errSkipStatement // CREATE TABLE ... AS SELECT, PARTITION OF
+	}
+	body, err := p.parenGroup()
+	if err != nil {
+		return err
+	}
+
+	t := &model.Table{Schema: schema, Name: name, Kind: model.TableKindTable}
+	s.withoutRowid = false
+	for !p.done() {
+		switch {
+		case p.acceptKeyword("COMMENT"):
+			p.acceptSymbol("=")
+			if tok := p.next(); tok.kind == sqlString {
+				comment := tok.text
+				t.Comment = &comment
+			}
+		case p.acceptKeyword("WITHOUT", "ROWID"):
+			s.withoutRowid = true
+		default:
+			p.next()
+		}
+	}
+
+	if existing := s.findTable(schema, name); existing != nil {
+		s.tables = slices.DeleteFunc(s.tables, func(e *model.Table) bool { return e == existing })
+	}
+	s.tables = append(s.tables, t)
+
+	var tableElements []*ddlParser
+	for _, element := range splitTopLevel(body) {
+		ep := p.sub(element)
+		if isTableConstraint(ep) {
+			tableElements = append(tableElements, ep) // table constraints may reference columns declared after them
+			continue
+		}
+		if _, err := s.addColumn(t, ep); err != nil {
+			return err
+		}
+	}
+	for _, ep := range tableElements {
+		if err := s.addTableConstraint(t, ep); err != nil {
+			return err
+		}
+	}
+
+	if s.isSQLite() {
+		ddl := p.text(p.tokens) // SQLite keeps comments in the DDL only
+		fillSQLiteTableComment(t, ddl)
+		fillSQLiteColumnComments(t.Columns, ddl)
+	}
+	return nil
+}
+
+func (s *DDLSchema) execCreateIndex(p *ddlParser) error {
+	unique := false
+	for !p.done() && !p.peekKeyword("INDEX") {
+		if p.next().isKeyword("UNIQUE") {
+			unique = true
+		}
+	}
+	if !p.acceptKeyword("INDEX") {
+		return errSkipStatement
+	}
+	p.acceptKeyword("CONCURRENTLY")
+	p.acceptKeyword("IF", "NOT", "EXISTS")
+	var indexName string
+	if !p.peekKeyword("ON") {
+		parts, err := p.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		indexName = parts[len(parts)-1]
+	}
+	if !p.acceptKeyword("ON") {
+		return errSkipStatement
+	}
+	p.acceptKeyword("ONLY")
+	schema, name, err := s.parseTableName(p)
+	if err != nil {
+		return err
+	}
+	t := s.findTable(schema, name)
+	if t == nil {
+		return errSkipStatement
+	}
+	if p.acceptKeyword("USING") {
+		p.next()
+	}
+	columns, err := p.parseIndexColumns()
+	if err != nil {
+		return err
+	}
+	if indexName == "" {
+		indexName = t.Name + "_" + strings.Join(columns, "_") + "_idx"
+	}
+	s.addIndex(t, indexName, unique, false, columns)
+	return nil
+}
+
+func (s *DDLSchema) execCreateType(p *ddlParser) error {
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.acceptKeyword("AS", "ENUM") {
+		return errSkipStatement
+	}
+	group, err := p.parenGroup()
+	if err != nil {
+		return err
+	}
+	schema, name := s.splitName(parts)
+	enum := &model.UserType{Schema: schema, Name: name, Kind: model.UserTypeKindEnum}
+	for _, tok := range group {
+		if tok.kind == sqlString {
+			enum.EnumValues = append(enum.EnumValues, tok.text)
+		}
+	}
+	s.enums[strings.ToLower(schema+"."+name)] = enum
+	return nil
+}
+
+func (s *DDLSchema) execCommentOn(p *ddlParser) error {
+	isColumn := false
+	switch {
+	case p.acceptKeyword("TABLE"):
+	case p.acceptKeyword("COLUMN"):
+		isColumn = true
+	default:
+		return errSkipStatement
+	}
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.acceptKeyword("IS") {
+		return errors.New("expected IS")
+	}
+	var comment *string
+	if tok := p.next(); tok.kind == sqlString {
+		comment = &tok.text
+	}
+
+	if !isColumn {
+		schema, name := s.splitName(parts)
+		t := s.findTable(schema, name)
+		if t == nil {
+			return errSkipStatement
+		}
+		t.Comment = comment
+		return nil
+	}
+	if len(parts) < 2 {
+		return errors.New("expected table.column")
+	}
+	schema, name := s.splitName(parts[:len(parts)-1])
+	col := s.findColumn(s.findTable(schema, name), parts[len(parts)-1])
+	if col == nil {
+		return errSkipStatement
+	}
+	col.Comment = comment
+	return nil
+}
+
+// execProcedure applies the T-SQL system procedures that change tables, sp_addextendedproperty and sp_rename.
+func (s *DDLSchema) execProcedure(p *ddlParser) error {
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return errSkipStatement
+	}
+	var args []string
+	for !p.done() {
+		if tok := p.next(); tok.kind == sqlString {
+			args = append(args, tok.text)
+		}
+	}
+	switch strings.ToLower(parts[len(parts)-1]) {
+	case "sp_addextendedproperty":
+		return s.execExtendedProperty(args)
+	case "sp_rename":
+		return s.execSpRename(args)
+	}
+	return errSkipStatement
+}
+
+// execExtendedProperty applies the T-SQL way to comment tables and columns:
+// EXEC sp_addextendedproperty 'MS_Description', 'comment', 'SCHEMA', 'dbo', 'TABLE', 'user', 'COLUMN', 'name'.
+func (s *DDLSchema) execExtendedProperty(args []string) error {
+	if len(args) < 6 || !strings.EqualFold(args[0], "MS_Description") {
+		return errSkipStatement
+	}
+	comment := args[1]
+	levels := make(map[string]string)
+	for i := 2; i+1 < len(args); i += 2 {
+		levels[strings.ToUpper(args[i])] = args[i+1]
+	}
+	t := s.findTable(levels["SCHEMA"], levels["TABLE"])
+	if t == nil {
+		return errSkipStatement
+	}
+	if column, ok := levels["COLUMN"]; ok {
+		col := s.findColumn(t, column)
+		if col == nil {
+			return errSkipStatement
+		}
+		col.Comment = &comment
+		return nil
+	}
+	t.Comment = &comment
+	return nil
+}
+
+var columnStopKeywords = []string{
+	"CONSTRAINT", "NOT", "NULL", "DEFAULT", "PRIMARY", "UNIQUE", "CHECK", "REFERENCES", "COLLATE", "COMMENT",
+	"AUTO_INCREMENT", "AUTOINCREMENT", "IDENTITY", "GENERATED", "AS", "ON", "CHARACTER", "CHARSET",
+	"STORED", "VIRTUAL", "PERSISTED", "VISIBLE", "INVISIBLE",
+}
+
+// typeWords are the words that may follow the first word of a multi-word type, e.g. double precision, int unsigned.
+var typeWords = []string{"precision", "varying", "unsigned", "signed", "zerofill", "with", "without", "time", "zone"}
+
+// columnPosition is the MySQL FIRST or AFTER clause of a column definition, after is empty for FIRST.
+type columnPosition struct {
+	after string
+}
+
+// addColumn appends the column defined by p to the table, the FIRST or AFTER clause of ALTER TABLE is returned
+// for the caller to move the column, nil if the definition has none.
+func (s *DDLSchema) addColumn(t *model.Table, p *ddlParser) (*columnPosition, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	col := &model.Column{Name: name, Ordinal: len(t.Columns) + 1, IsNullable: true}
+	if existing := s.findColumn(t, name); existing != nil {
+		return nil, fmt.Errorf("duplicate column %s", name)
+	}
+	t.Columns = append(t.Columns, col)
+
+	typ, typeWordsText := p.parseType()
+	s.fillColumnType(col, typ)
+
+	var constraintName string
+	var position *columnPosition
+	for !p.done() {
+		switch {
+		case p.acceptKeyword("CONSTRAINT"):
+			constraintName, err = p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		case p.acceptKeyword("NOT", "NULL"):
+			col.IsNullable = false
+		case p.acceptKeyword("NULL"):
+			col.IsNullable = true
+		case p.acceptKeyword("DEFAULT"):
+			expr := p.parseExpression()
+			if !strings.EqualFold(expr, "NULL") {
+				col.DefaultValue = &expr
+				if s.dialect == "mysql" {
+					col.DefaultValue = unquoteMySQLDefault(expr)
+				}
+			}
+		case p.acceptKeyword("PRIMARY", "KEY"):
+			s.addPrimaryKey(t, constraintName, []string{col.Name})
+		case p.acceptKeyword("UNIQUE"):
+			p.acceptKeyword("KEY")
+			s.addUnique(t, constraintName, "", []string{col.Name})
+		case p.acceptKeyword("AUTO_INCREMENT"), p.acceptKeyword("AUTOINCREMENT"):
+			col.IsAutoIncrement = true
+		case p.acceptKeyword("IDENTITY"):
+			p.skipParenGroup()
+			setIdentity(col, "always")
+		case p.acceptKeyword("GENERATED"):
+			generation := "always"
+			if p.acceptKeyword("BY", "DEFAULT") {
+				generation = "by_default"
+			} else {
+				p.acceptKeyword("ALWAYS")
+			}
+			p.acceptKeyword("AS")
+			if p.acceptKeyword("IDENTITY") {
+				p.skipParenGroup()
+				setIdentity(col, generation)
+				continue
+			}
+			if err := s.setGenerated(col, p); err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("AS"):
+			if err := s.setGenerated(col, p); err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("STORED"), p.acceptKeyword("PERSISTED"):
+			kind := "stored"
+			col.GenerationKind = &kind
+		case p.acceptKeyword("VIRTUAL"):
+			kind := "virtual"
+			col.GenerationKind = &kind
+		case p.acceptKeyword("COMMENT"):
+			if tok := p.next(); tok.kind == sqlString {
+				comment := tok.text
+				col.Comment = &comment
+			}
+		case p.acceptKeyword("CHECK"):
+			group, err := p.parenGroup()
+			if err != nil {
+				return nil, err
+			}
+			s.addCheck(t, constraintName, p.text(group), []string{col.Name})
+		case p.acceptKeyword("REFERENCES"):
+			if err := s.parseReferences(t, p, constraintName, []string{col.Name}); err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("COLLATE"):
+			collation, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			col.Collation = &collation
+		case p.acceptKeyword("CHARACTER", "SET"), p.acceptKeyword("CHARSET"):
+			charset, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			col.Charset = &charset
+		case p.acceptKeyword("ON", "UPDATE"):
+			p.parseExpression()
+		case p.acceptKeyword("FIRST"):
+			position = &columnPosition{}
+		case p.acceptKeyword("AFTER"):
+			after, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			position = &columnPosition{after: after}
+		default:
+			if p.next().isSymbol("(") {
+				p.pos--
+				p.skipParenGroup()
+			}
+		}
+		constraintName = ""
+	}
+
+	if s.dialect == "postgres" && slices.Contains([]string{"serial", "serial4", "bigserial", "serial8", "smallserial", "serial2"}, typeWordsText) {
+		seq := t.Name + "_" + col.Name + "_seq"
+		defaultValue := "nextval('" + seq + "'::regclass)"
+		qualified := t.Schema + "." + seq
+		col.DefaultValue = &defaultValue
+		col.SequenceName = &qualified
+		col.IsNullable = false
+	}
+	s.finishColumn(t, col)
+	return position, nil
+}
+
+// finishColumn fills the column fields derived from the others, it is called again whenever the column is altered.
+func (s *DDLSchema) finishColumn(t *model.Table, col *model.Column) {
+	if col.IsIdentity || col.IsPrimaryKey && !s.isSQLite() {
+		col.IsNullable = false
+	}
+	if s.dialect == "postgres" && col.IsIdentity {
+		qualified := t.Schema + "." + t.Name + "_" + col.Name + "_seq"
+		col.SequenceName = &qualified
+	}
+	fillValueGeneration(col)
+	fillDialectLogicalType(col, s.dialect)
+}
+
+func setIdentity(col *model.Column, generation string) {
+	col.IsIdentity = true
+	col.IdentityGeneration = &generation
+}
+
+func (s *DDLSchema) setGenerated(col *model.Column, p *ddlParser) error {
+	group, err := p.parenGroup()
+	if err != nil {
+		return err
+	}
+	expr := strings.TrimSpace(p.text(group))
+	kind := "virtual"
+	col.IsGenerated = true
+	col.GenerationExpression = &expr
+	col.GenerationKind = &kind
+	col.DefaultValue = nil
+	return nil
+}
+
+func isTableConstraint(p *ddlParser) bool {
+	for _, kw := range []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "KEY", "INDEX", "FULLTEXT", "SPATIAL", "EXCLUDE", "LIKE", "PERIOD"} {
+		if p.peekKeyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *DDLSchema) addTableConstraint(t *model.Table, p *ddlParser) error {
+	var name string
+	if p.acceptKeyword("CONSTRAINT") {
+		var err error
+		if name, err = p.parseName(); err != nil {
+			return err
+		}
+	}
+	switch {
+	case p.acceptKeyword("PRIMARY", "KEY"):
+		p.skipIndexOptions()
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		s.addPrimaryKey(t, name, columns)
+	case p.acceptKeyword("UNIQUE"):
+		_ = p.acceptKeyword("KEY") || p.acceptKeyword("INDEX")
+		p.skipIndexOptions()
+		var indexName string
+		if !p.peekSymbol("(") {
+			indexName, _ = p.parseName()
+			p.skipIndexOptions()
+		}
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		s.addUnique(t, name, indexName, columns)
+	case p.acceptKeyword("CHECK"):
+		group, err := p.parenGroup()
+		if err != nil {
+			return err
+		}
+		s.addCheck(t, name, p.text(group), nil)
+	case p.acceptKeyword("FOREIGN", "KEY"):
+		if !p.peekSymbol("(") {
+			indexName, _ := p.parseName() // MySQL allows an index name here
+			if name == "" {
+				name = indexName
+			}
+		}
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		if !p.acceptKeyword("REFERENCES") {
+			return errors.New("expected REFERENCES")
+		}
+		return s.parseReferences(t, p, name, columns)
+	case p.acceptKeyword("EXCLUDE"):
+		if p.acceptKeyword("USING") {
+			p.next()
+		}
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = t.Name + "_" + strings.Join(columns, "_") + "_excl"
+		}
+		indexName := name
+		s.addIndex(t, indexName, false, false, columns)
+		t.Constraints = append(t.Constraints, &model.Constraint{Name: name, Type: model.ConstraintTypeExclusion, Columns: columns, IndexName: &indexName})
+	case p.acceptKeyword("DEFAULT"):
+		// T-SQL default constraint: DEFAULT expr FOR column
+		start := p.pos
+		for !p.done() && !p.peekKeyword("FOR") {
+			p.next()
+		}
+		expr := p.text(p.tokens[start:p.pos])
+		if !p.acceptKeyword("FOR") {
+			return errors.New("expected FOR")
+		}
+		column, err := p.parseName()
+		if err != nil {
+			return err
+		}
+		if col := s.findColumn(t, column); col != nil {
+			col.DefaultValue = &expr
+		}
+	case p.acceptKeyword("KEY"), p.acceptKeyword("INDEX"), p.acceptKeyword("FULLTEXT"), p.acceptKeyword("SPATIAL"):
+		_ = p.acceptKeyword("KEY") || p.acceptKeyword("INDEX")
+		var indexName string
+		if !p.peekSymbol("(") {
+			indexName, _ = p.parseName()
+		}
+		p.skipIndexOptions()
+		columns, err := p.parseIndexColumns()
+		if err != nil {
+			return err
+		}
+		if indexName == "" {
+			indexName = columns[0]
+		}
+		s.addIndex(t, indexName, false, false, columns)
+	}
+	return nil
+}
+
+func (s *DDLSchema) parseReferences(t *model.Table, p *ddlParser, name string, columns []string) error {
+	refSchema, refTable, err := s.parseTableName(p)
+	if err != nil {
+		return err
+	}
+	var refColumns []string
+	if p.peekSymbol("(") {
+		if refColumns, err = p.parseIndexColumns(); err != nil {
+			return err
+		}
+	} else if ref := s.findTable(refSchema, refTable); ref != nil {
+		for _, col := range ref.Columns {
+			if col.IsPrimaryKey {
+				refColumns = append(refColumns, col.Name)
+			}
+		}
+	}
+
+	fk := &model.ForeignKey{
+		Name:              name,
+		Schema:            t.Schema,
+		Table:             t.Name,
+		Columns:           columns,
+		ReferencedSchema:  refSchema,
+		ReferencedTable:   refTable,
+		ReferencedColumns: refColumns,
+		OnDelete:          "NO ACTION",
+		OnUpdate:          "NO ACTION",
+	}
+	for !p.done() {
+		switch {
+		case p.acceptKeyword("ON", "DELETE"):
+			fk.OnDelete = p.parseReferentialAction()
+		case p.acceptKeyword("ON", "UPDATE"):
+			fk.OnUpdate = p.parseReferentialAction()
+		case p.acceptKeyword("NOT", "DEFERRABLE"):
+			fk.IsDeferrable = false
+		case p.acceptKeyword("DEFERRABLE"):
+			fk.IsDeferrable = true
+		case p.peekKeyword("MATCH"), p.peekKeyword("INITIALLY"):
+			p.pos += 2
+		default:
+			goto done
+		}
+	}
+done:
+	if fk.Name == "" {
+		fk.Name = s.defaultConstraintName(t, "fk", columns, fk.ReferencedTable)
+	}
+	if s.isSQLite() {
+		t.ForeignKeys = slices.Insert(t.ForeignKeys, 0, fk) // Reported last declared first
+		return nil
+	}
+	t.ForeignKeys = append(t.ForeignKeys, fk)
+	return nil
+}
+
+func (s *DDLSchema) addPrimaryKey(t *model.Table, name string, columns []string) {
+	for _, c := range columns {
+		if col := s.findColumn(t, c); col != nil {
+			col.IsPrimaryKey = true
+			if !s.isSQLite() {
+				col.IsNullable = false
+			}
+		}
+	}
+	if name == "" || s.isSQLite() {
+		name = s.defaultConstraintName(t, "pk", columns, "")
+	}
+	pk := &model.Constraint{Name: name, Type: model.ConstraintTypePrimaryKey, Columns: columns}
+	t.Constraints = append(t.Constraints, pk)
+	if s.isSQLite() && s.isRowidAlias(t, columns) {
+		col := s.findColumn(t, columns[0])
+		col.IsAutoIncrement = true
+		fillValueGeneration(col)
+		return // The column is an alias of the rowid, there is no index
+	}
+
+	indexName := name
+	switch s.dialect {
+	case "mysql":
+		indexName = "PRIMARY"
+	case "sqlite3", "moderncsqlite":
+		indexName = s.sqliteAutoindexName(t)
+	}
+	s.addIndex(t, indexName, true, true, columns)
+	pk.IndexName = &indexName
+}
+
+func (s *DDLSchema) addUnique(t *model.Table, name, indexName string, columns []string) {
+	if name == "" {
+		name = indexName
+	}
+	if name == "" {
+		name = s.defaultConstraintName(t, "unique", columns, "")
+	}
+	indexName = name
+	if s.isSQLite() {
+		name = "" // SQLite does not keep the names of key constraints
+		indexName = s.sqliteAutoindexName(t)
+	}
+	s.addIndex(t, indexName, true, false, columns)
+	t.Constraints = append(t.Constraints, &model.Constraint{Name: name, Type: model.ConstraintTypeUnique, Columns: columns, IndexName: &indexName})
+}
+
+func (s *DDLSchema) addCheck(t *model.Table, name, expr string, columns []string) {
+	expr = strings.TrimSpace(expr)
+	if len(columns) == 0 {
+		columns = inferCheckColumns(expr, t.Columns)
+	}
+	if name == "" {
+		name = s.defaultConstraintName(t, "check", columns, "")
+	}
+	t.Constraints = append(t.Constraints, &model.Constraint{Name: name, Type: model.ConstraintTypeCheck, Columns: columns, CheckExpression: &expr})
+}
+
+func (s *DDLSchema) addIndex(t *model.Table, name string, unique, primary bool, columns []string) {
+	idx := &model.Index{Name: name, IsUnique: unique, IsPrimary: primary, Columns: []*model.IndexColumn{}}
+	for i, c := range columns {
+		idx.Columns = append(idx.Columns, &model.IndexColumn{Ordinal: i + 1, Name: c})
+	}
+	t.Indexes = append(t.Indexes, idx)
+}
+
+// defaultConstraintName returns the name the database would give to an unnamed constraint,
+// SQLite does not keep constraint names, so they stay empty like with live introspection.
+func (s *DDLSchema) defaultConstraintName(t *model.Table, kind string, columns []string, refTable string) string {
+	count := func(constraintType string) int {
+		n := 0
+		for _, c := range t.Constraints {
+			if c.Type == constraintType {
+				n++
+			}
+		}
+		return n
+	}
+	switch s.dialect {
+	case "mysql":
+		switch kind {
+		case "pk":
+			return "PRIMARY"
+		case "unique":
+			name := columns[0]
+			for i := 2; slices.ContainsFunc(t.Indexes, func(idx *model.Index) bool { return idx.Name == name }); i++ {
+				name = columns[0] + "_" + strconv.Itoa(i)
+			}
+			return name
+		case "check":
+			return t.Name + "_chk_" + strconv.Itoa(count(model.ConstraintTypeCheck)+1)
+		case "fk":
+			return t.Name + "_ibfk_" + strconv.Itoa(len(t.ForeignKeys)+1)
+		}
+	case "postgres":
+		switch kind {
+		case "pk":
+			return t.Name + "_pkey"
+		case "unique":
+			return t.Name + "_" + strings.Join(columns, "_") + "_key"
+		case "check":
+			if len(columns) == 0 {
+				return t.Name + "_check"
+			}
+			return t.Name + "_" + columns[0] + "_check"
+		case "fk":
+			return t.Name + "_" + strings.Join(columns, "_") + "_fkey"
+		}
+	case "sqlserver":
+		switch kind {
+		case "pk":
+			return "PK_" + t.Name
+		case "unique":
+			return "UQ_" + t.Name + "_" + strings.Join(columns, "_")
+		case "check":
+			return "CK_" + t.Name + "_" + strings.Join(columns, "_")
+		case "fk":
+			return "FK_" + t.Name + "_" + refTable
+		}
+	}
+	return ""
+}
+
+func (s *DDLSchema) sqliteAutoindexName(t *model.Table) string {
+	n := 1
+	for _, idx := range t.Indexes {
+		if strings.HasPrefix(idx.Name, "sqlite_autoindex_") {
+			n++
+		}
+	}
+	return "sqlite_autoindex_" + t.Name + "_" + strconv.Itoa(n)
+}
+
+// isRowidAlias reports whether the primary key columns are an alias of the SQLite rowid, i.e. a single INTEGER PRIMARY KEY.
+func (s *DDLSchema) isRowidAlias(t *model.Table, columns []string) bool {
+	if s.withoutRowid || len(columns) != 1 {
+		return false
+	}
+	col := s.findColumn(t, columns[0])
+	return col != nil && strings.EqualFold(col.Type, "INTEGER")
+}
+
+// ddlTypeAliases maps alternative type spellings to the base type reported by the live introspector of each dialect.
+var ddlTypeAliases = map[string]map[string]string{
+	"mysql": {
+		"integer":           "int",
+		"bool":              "tinyint",
+		"boolean":           "tinyint",
+		"dec":               "decimal",
+		"numeric":           "decimal",
+		"fixed":             "decimal",
+		"double precision":  "double",
+		"real":              "double",
+		"character":         "char",
+		"character varying": "varchar",
+	},
+	"postgres": {
+		"int":         "integer",
+		"int4":        "integer",
+		"serial":      "integer",
+		"serial4":     "integer",
+		"int8":        "bigint",
+		"bigserial":   "bigint",
+		"serial8":     "bigint",
+		"int2":        "smallint",
+		"smallserial": "smallint",
+		"serial2":     "smallint",
+		"varchar":     "character varying",
+		"char":        "character",
+		"bpchar":      "character",
+		"bool":        "boolean",
+		"float":       "double precision",
+		"float8":      "double precision",
+		"float4":      "real",
+		"decimal":     "numeric",
+		"timestamp":   "timestamp without time zone",
+		"timestamptz": "timestamp with time zone",
+		"time":        "time without time zone",
+		"timetz":      "time with time zone",
+		"varbit":      "bit varying",
+	},
+	"sqlserver": {
+		"integer":           "int",
+		"dec":               "decimal",
+		"rowversion":        "timestamp",
+		"double precision":  "float",
+		"character":         "char",
+		"character varying": "varchar",
+	},
+}
+
+var (
+	lengthTypes      = []string{"char", "character", "varchar", "character varying", "nchar", "nvarchar", "binary", "varbinary", "bit", "bit varying"}
+	fixedLengthTypes = []string{"char", "character", "nchar", "binary", "bit"} // Length defaults to 1
+)
+
+// fillColumnType fills the structured type details from the declared type, normalized like the live introspector does.
+func (s *DDLSchema) fillColumnType(col *model.Column, typ string) {
+	col.Type = typ
+	if s.isSQLite() {
+		fillSQLiteTypeDetails(col)
+		return
+	}
+
+	words, args, dims := parseDeclaredType(typ)
+	var baseWords []string
+	for _, w := range words {
+		switch w {
+		case "unsigned":
+			col.IsUnsigned = true
+		case "signed", "zerofill":
+		default:
+			baseWords = append(baseWords, w)
+		}
+	}
+	base := strings.Join(baseWords, " ")
+	if alias, ok := ddlTypeAliases[s.dialect][base]; ok {
+		base = alias
+	}
+	col.BaseType = base
+	col.ArrayDimensions = dims
+
+	switch {
+	case slices.Contains(lengthTypes, base):
+		if len(args) > 0 {
+			if n, err := strconv.Atoi(args[0]); err == nil {
+				col.MaxLength = &n
+			}
+		} else if slices.Contains(fixedLengthTypes, base) {
+			n := 1
+			col.MaxLength = &n
+		}
+	case base == "decimal" || base == "numeric":
+		precision, scale := 0, 0
+		switch {
+		case len(args) > 0:
+			precision, _ = strconv.Atoi(args[0])
+			if len(args) > 1 {
+				scale, _ = strconv.Atoi(args[1])
+			}
+		case s.dialect == "mysql":
+			precision = 10
+		case s.dialect == "sqlserver":
+			precision = 18
+		}
+		if precision > 0 {
+			col.NumericPrecision = &precision
+			col.NumericScale = &scale
+		}
+	}
+
+	switch s.dialect {
+	case "mysql":
+		if base == "enum" || base == "set" {
+			col.EnumValues = parseEnumValues(typ)
+			col.Type = base + typ[strings.Index(typ, "("):]
+			return
+		}
+		col.Type = formatMySQLType(words, base, args)
+	case "postgres":
+		schema, name := s.splitName(strings.Split(base, "."))
+		if enum, ok := s.enums[strings.ToLower(schema+"."+name)]; ok {
+			col.BaseType = enum.Name
+			col.EnumValues = enum.EnumValues
+			col.UserType = enum
+			col.Type = enum.Name + strings.Repeat("[]", dims)
+			return
+		}
+		col.Type = formatPostgresType(base, args, dims)
+	case "sqlserver":
+		col.Type = base
+	}
+}
+
+// formatMySQLType formats the type like information_schema.columns.column_type,
+// the display width of integer types is dropped except for tinyint(1) which is commonly used as boolean.
+func formatMySQLType(words []string, base string, args []string) string {
+	if len(words) > 0 && (words[0] == "bool" || words[0] == "boolean") {
+		return "tinyint(1)"
+	}
+	zerofill := slices.Contains(words, "zerofill")
+	typ := base
+	isInteger := slices.Contains([]string{"tinyint", "smallint", "mediumint", "int", "bigint"}, base)
+	if len(args) > 0 && (!isInteger || zerofill || base == "tinyint" && args[0] == "1") {
+		typ += "(" + strings.Join(args, ",") + ")"
+	}
+	if slices.Contains(words, "unsigned") || zerofill {
+		typ += " unsigned"
+	}
+	if zerofill {
+		typ += " zerofill"
+	}
+	return typ
+}
+
+// formatPostgresType formats the type like format_type, e.g. timestamp(3) without time zone, numeric(10,2)[].
+func formatPostgresType(base string, args []string, dims int) string {
+	typ := base
+	if len(args) > 0 {
+		modifier := "(" + strings.Join(args, ",") + ")"
+		if first, rest, ok := strings.Cut(base, " with"); ok && (first == "timestamp" || first == "time") {
+			typ = first + modifier + " with" + rest
+		} else {
+			typ += modifier
+		}
+	}
+	return typ + strings.Repeat("[]", dims)
+}
+
+// parseDeclaredType splits a declared type into its lowercase words, the arguments of the first parenthesized group,
+// and the number of array dimensions, e.g. numeric(10, 2)[] -> [numeric], [10 2], 1.
+func parseDeclaredType(typ string) (words []string, args []string, dims int) {
+	typ = strings.TrimSpace(typ)
+	for {
+		switch {
+		case strings.HasSuffix(typ, "]"):
+			if i := strings.LastIndex(typ, "["); i >= 0 {
+				typ = strings.TrimSpace(typ[:i])
+				dims++
+				continue
+			}
+		case strings.HasSuffix(strings.ToLower(typ), " array"):
+			typ = strings.TrimSpace(typ[:len(typ)-len(" array")])
+			dims++
+			continue
+		}
+		break
+	}
+
+	if start := strings.Index(typ, "("); start >= 0 {
+		if end := strings.Index(typ[start:], ")"); end >= 0 {
+			for _, a := range strings.Split(typ[start+1:start+end], ",") {
+				args = append(args, strings.ToLower(strings.TrimSpace(a)))
+			}
+			typ = typ[:start] + " " + typ[start+end+1:]
+		}
+	}
+	return strings.Fields(strings.ToLower(typ)), args, dims
+}
+
+// unquoteMySQLDefault returns the default like information_schema.columns does, string literals are unquoted.
+func unquoteMySQLDefault(expr string) *string {
+	if len(expr) >= 2 && expr[0] == '\'' && expr[len(expr)-1] == '\'' {
+		v := strings.ReplaceAll(expr[1:len(expr)-1], "''", "'")
+		return &v
+	}
+	return &expr
+}
+
+func (s *DDLSchema) parseTableName(p *ddlParser) (string, string, error) {
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return "", "", err
+	}
+	schema, name := s.splitName(parts)
+	return schema, name, nil
+}
+
+// splitName returns the schema and name of a possibly qualified name (name, schema.name or database.schema.name).
+func (s *DDLSchema) splitName(parts []string) (string, string) {
+	if len(parts) == 1 {
+		return s.defaultSchema, parts[0]
+	}
+	return parts[len(parts)-2], parts[len(parts)-1]
+}
+
+func (s *DDLSchema) findTable(schema, name string) *model.Table {
+	for _, t := range s.tables {
+		if strings.EqualFold(t.Schema, schema) && strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *DDLSchema) findColumn(t *model.Table, name string) *model.Column {
+	if t == nil {
+		return nil
+	}
+	for _, col := range t.Columns {
+		if strings.EqualFold(col.Name, name) {
+			return col
+		}
+	}
+	return nil
+}
+
+func (s *DDLSchema) isSQLite() bool {
+	return s.dialect == "sqlite3" || s.dialect == "moderncsqlite"
+}
+
+func sortIndexes(indexes []*model.Index) {
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if indexes[i].IsPrimary != indexes[j].IsPrimary {
+			return indexes[i].IsPrimary
+		}
+		if indexes[i].IsUnique != indexes[j].IsUnique {
+			return indexes[i].IsUnique
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+}
+
+func abbreviate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// ddlParser is a cursor over the tokens of one statement or one element of a statement.
+type ddlParser struct {
+	src     string
+	tokens  []sqlToken
+	pos     int
+	dialect string
+}
+
+func (p *ddlParser) sub(tokens []sqlToken) *ddlParser {
+	return &ddlParser{src: p.src, tokens: tokens, dialect: p.dialect}
+}
+
+func (p *ddlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *ddlParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *ddlParser) next() sqlToken {
+	tok := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *ddlParser) rest() []sqlToken {
+	rest := p.tokens[p.pos:]
+	p.pos = len(p.tokens)
+	return rest
+}
+
+// peekKeyword reports whether the next tokens are the given keywords.
+func (p *ddlParser) peekKeyword(kws ...string) bool {
+	if p.pos+len(kws) > len(p.tokens) {
+		return false
+	}
+	for i, kw := range kws {
+		if !p.tokens[p.pos+i].isKeyword(kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptKeyword consumes the given keywords if the next tokens are exactly them.
+func (p *ddlParser) acceptKeyword(kws ...string) bool {
+	if !p.peekKeyword(kws...) {
+		return false
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *ddlParser) peekSymbol(s string) bool {
+	return p.peek().isSymbol(s)
+}
+
+func (p *ddlParser) acceptSymbol(s string) bool {
+	if !p.peekSymbol(s) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+// parseName parses an identifier, unquoted identifiers are folded to lowercase in PostgreSQL.
+func (p *ddlParser) parseName() (string, error) {
+	tok := p.next()
+	switch {
+	case tok.kind == sqlIdent && p.dialect == "postgres":
+		return strings.ToLower(tok.text), nil
+	case tok.isName(), tok.kind == sqlString:
+		return tok.text, nil
+	}
+	return "", fmt.Errorf("expected name, got %q", tok.text)
+}
+
+func (p *ddlParser) parseQualifiedName() ([]string, error) {
+	var parts []string
+	for {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, name)
+		if !p.acceptSymbol(".") {
+			return parts, nil
+		}
+	}
+}
+
+// parenGroup consumes a balanced parenthesized group and returns the tokens inside.
+func (p *ddlParser) parenGroup() ([]sqlToken, error) {
+	if !p.acceptSymbol("(") {
+		return nil, fmt.Errorf("expected (, got %q", p.peek().text)
+	}
+	start := p.pos
+	for depth := 1; !p.done(); {
+		tok := p.next()
+		switch {
+		case tok.isSymbol("("):
+			depth++
+		case tok.isSymbol(")"):
+			depth--
+			if depth == 0 {
+				return p.tokens[start : p.pos-1], nil
+			}
+		}
+	}
+	return nil, errors.New("unbalanced parentheses")
+}
+
+func (p *ddlParser) skipParenGroup() {
+	if p.peekSymbol("(") {
+		_, _ = p.parenGroup()
+	}
+}
+
+// skipIndexOptions skips the options between the key word and the column list of an index, e.g. CLUSTERED, USING BTREE.
+func (p *ddlParser) skipIndexOptions() {
+	for {
+		switch {
+		case p.acceptKeyword("CLUSTERED"), p.acceptKeyword("NONCLUSTERED"):
+		case p.acceptKeyword("USING"):
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// parseIndexColumns parses a parenthesized column list, expressions are kept as written.
+func (p *ddlParser) parseIndexColumns() ([]string, error) {
+	group, err := p.parenGroup()
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	for _, element := range splitTopLevel(group) {
+		ep := p.sub(element)
+		if isIndexColumnName(element) {
+			name, _ := ep.parseName()
+			columns = append(columns, name)
+			continue
+		}
+		columns = append(columns, p.text(element))
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("empty column list")
+	}
+	return columns, nil
+}
+
+// isIndexColumnName reports whether the index element is a column, optionally followed by a MySQL prefix length
+// or by modifiers like DESC, COLLATE and operator classes, rather than an expression like lower(name).
+func isIndexColumnName(element []sqlToken) bool {
+	if !element[0].isName() {
+		return false
+	}
+	if len(element) == 1 || element[1].isName() {
+		return true
+	}
+	return len(element) >= 4 && element[1].isSymbol("(") && element[2].kind == sqlNumber && element[3].isSymbol(")")
+}
+
+// parseType parses a column type, it returns the type as written and its first word in lowercase.
+func (p *ddlParser) parseType() (string, string) {
+	start := p.pos
+	if p.done() || slices.ContainsFunc(columnStopKeywords, func(kw string) bool { return p.peekKeyword(kw) }) {
+		return "", ""
+	}
+	first := p.next()
+	for p.acceptSymbol(".") {
+		p.next()
+	}
+	for !p.done() {
+		tok := p.peek()
+		switch {
+		case tok.isSymbol("("):
+			p.skipParenGroup()
+		case tok.kind == sqlIdent && slices.Contains(typeWords, strings.ToLower(tok.text)):
+			p.next()
+		case tok.isKeyword("ARRAY"):
+			p.next()
+		case isArrayDimension(p.src, tok):
+			p.next()
+		default:
+			goto done
+		}
+	}
+done:
+	var sb strings.Builder
+	for i, tok := range p.tokens[start:p.pos] {
+		if i > 0 && tok.start > p.tokens[start+i-1].end {
+			sb.WriteByte(' ')
+		}
+		switch {
+		case isArrayDimension(p.src, tok):
+			sb.WriteString("[" + tok.text + "]")
+		case tok.kind == sqlQuotedIdent:
+			sb.WriteString(tok.text)
+		default:
+			sb.WriteString(p.src[tok.start:tok.end])
+		}
+	}
+	return sb.String(), strings.ToLower(first.text)
+}
+
+func isArrayDimension(src string, tok sqlToken) bool {
+	if tok.kind != sqlQuotedIdent || src[tok.start] != '[' {
+		return false
+	}
+	_, err := strconv.Atoi(tok.text)
+	return tok.text == "" || err == nil
+}
+
+// parseExpression consumes an expression up to the next column constraint keyword and returns it as written.
+func (p *ddlParser) parseExpression() string {
+	start := p.pos
+	for !p.done() {
+		if p.pos > start && slices.ContainsFunc(columnStopKeywords, func(kw string) bool { return p.peekKeyword(kw) }) {
+			break
+		}
+		if p.peekSymbol("(") {
+			p.skipParenGroup()
+			continue
+		}
+		p.next()
+	}
+	if p.pos == start {
+		return ""
+	}
+	return p.text(p.tokens[start:p.pos])
+}
+
+func (p *ddlParser) parseReferentialAction() string {
+	for _, action := range [][]string{{"NO", "ACTION"}, {"SET", "NULL"}, {"SET", "DEFAULT"}, {"CASCADE"}, {"RESTRICT"}} {
+		if p.acceptKeyword(action...) {
+			return strings.Join(action, " ")
+		}
+	}
+	return strings.ToUpper(p.next().text)
+}
+
+// text returns the source text of the tokens as written.
+func (p *ddlParser) text(tokens []sqlToken) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return p.src[tokens[0].start:tokens[len(tokens)-1].end]
+}
+
+// splitTopLevel splits the tokens at the commas outside parentheses.
+func splitTopLevel(tokens []sqlToken) [][]sqlToken {
+	var result [][]sqlToken
+	depth, start := 0, 0
+	for i, tok := range tokens {
+		switch {
+		case tok.isSymbol("("):
+			depth++
+		case tok.isSymbol(")"):
+			depth--
+		case tok.isSymbol(",") && depth == 0:
+			if i > start {
+				result = append(result, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		result = append(result, tokens[start:])
+	}
+	return result
+}
@@ -0,0 +1,587 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// alterVerbs are the keywords that start an action of an ALTER TABLE statement.
+var alterVerbs = []string{"ADD", "DROP", "ALTER", "MODIFY", "CHANGE", "RENAME", "WITH", "SET", "OWNER", "ENABLE", "DISABLE", "CHECK", "NOCHECK"}
+
+func (s *DDLSchema) execAlterTable(p *ddlParser) error {
+	p.acceptKeyword("IF", "EXISTS")
+	p.acceptKeyword("ONLY")
+	schema, name, err := s.parseTableName(p)
+	if err != nil {
+		return err
+	}
+	t := s.findTable(schema, name)
+	if t == nil {
+		return errSkipStatement
+	}
+
+	prefix := p.text(p.tokens[:p.pos])
+	applied := false
+	var unsupported []string
+	verb := ""
+	for _, action := range splitTopLevel(p.rest()) {
+		ok, err := s.execAlterAction(t, p.sub(action), &verb)
+		if err != nil {
+			return err
+		}
+		if ok {
+			applied = true
+		} else {
+			unsupported = append(unsupported, prefix+" "+p.text(action))
+		}
+	}
+	if !applied {
+		return errSkipStatement
+	}
+	s.skippedParts = append(s.skippedParts, unsupported...)
+	return nil
+}
+
+// execAlterAction applies one action of an ALTER TABLE statement, reports false for unsupported actions.
+// T-SQL lists several columns after a single verb (ADD a int, b int), so the verb of the previous action is kept.
+func (s *DDLSchema) execAlterAction(t *model.Table, p *ddlParser, verb *string) (bool, error) {
+	p.acceptKeyword("WITH", "CHECK")
+	p.acceptKeyword("WITH", "NOCHECK")
+	if !slices.ContainsFunc(alterVerbs, func(v string) bool { return p.peekKeyword(v) }) {
+		switch *verb {
+		case "ADD":
+			return true, s.alterAdd(t, p)
+		case "DROP":
+			return s.alterDrop(t, p)
+		}
+		return false, nil
+	}
+
+	switch {
+	case p.acceptKeyword("ADD"):
+		*verb = "ADD"
+		return true, s.alterAdd(t, p)
+	case p.acceptKeyword("DROP"):
+		*verb = "DROP"
+		return s.alterDrop(t, p)
+	case p.acceptKeyword("ALTER"):
+		p.acceptKeyword("COLUMN")
+		return s.alterColumn(t, p)
+	case p.acceptKeyword("MODIFY"):
+		p.acceptKeyword("COLUMN")
+		return true, s.replaceColumn(t, p, "")
+	case p.acceptKeyword("CHANGE"):
+		p.acceptKeyword("COLUMN")
+		oldName, err := p.parseName()
+		if err != nil {
+			return false, err
+		}
+		return true, s.replaceColumn(t, p, oldName)
+	case p.acceptKeyword("RENAME"):
+		return s.alterRename(t, p)
+	}
+	*verb = ""
+	return false, nil
+}
+
+func (s *DDLSchema) alterAdd(t *model.Table, p *ddlParser) error {
+	if isTableConstraint(p) {
+		return s.addTableConstraint(t, p)
+	}
+	p.acceptKeyword("COLUMN")
+	if p.acceptKeyword("IF", "NOT", "EXISTS") && s.findColumn(t, p.peek().text) != nil {
+		return nil
+	}
+	position, err := s.addColumn(t, p)
+	if err != nil || position == nil {
+		return err
+	}
+	return moveColumn(t, t.Columns[len(t.Columns)-1], position)
+}
+
+func (s *DDLSchema) alterDrop(t *model.Table, p *ddlParser) (bool, error) {
+	switch {
+	case p.acceptKeyword("CONSTRAINT"), p.acceptKeyword("CHECK"), p.acceptKeyword("FOREIGN", "KEY"):
+		p.acceptKeyword("IF", "EXISTS")
+		name, err := p.parseName()
+		if err != nil {
+			return false, err
+		}
+		s.dropConstraint(t, name)
+	case p.acceptKeyword("PRIMARY", "KEY"):
+		for _, c := range t.Constraints {
+			if c.Type == model.ConstraintTypePrimaryKey {
+				s.dropConstraint(t, c.Name)
+				break
+			}
+		}
+	case p.acceptKeyword("INDEX"), p.acceptKeyword("KEY"):
+		p.acceptKeyword("IF", "EXISTS")
+		name, err := p.parseName()
+		if err != nil {
+			return false, err
+		}
+		s.dropIndex(t, name)
+	case p.peekKeyword("DEFAULT"), p.peekKeyword("PERIOD"), p.peekKeyword("SYSTEM"), p.peekKeyword("PARTITION"):
+		return false, nil
+	default:
+		p.acceptKeyword("COLUMN")
+		p.acceptKeyword("IF", "EXISTS")
+		name, err := p.parseName()
+		if err != nil {
+			return false, err
+		}
+		s.dropColumn(t, name)
+	}
+	return true, nil
+}
+
+// alterColumn applies ALTER [COLUMN] name ..., in the PostgreSQL, MySQL and T-SQL forms.
+func (s *DDLSchema) alterColumn(t *model.Table, p *ddlParser) (bool, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return false, err
+	}
+	col := s.findColumn(t, name)
+	if col == nil {
+		return false, fmt.Errorf("column %s not found in table %s", name, t.Name)
+	}
+
+	switch {
+	case p.acceptKeyword("TYPE"), p.acceptKeyword("SET", "DATA", "TYPE"):
+		typ, _ := p.parseType()
+		s.retypeColumn(col, typ)
+	case p.acceptKeyword("SET", "NOT", "NULL"):
+		col.IsNullable = false
+	case p.acceptKeyword("DROP", "NOT", "NULL"):
+		col.IsNullable = true
+	case p.acceptKeyword("SET", "DEFAULT"):
+		expr := p.parseExpression()
+		col.DefaultValue = &expr
+		if s.dialect == "mysql" {
+			col.DefaultValue = unquoteMySQLDefault(expr)
+		}
+	case p.acceptKeyword("DROP", "DEFAULT"):
+		col.DefaultValue = nil
+	case p.acceptKeyword("ADD", "GENERATED"):
+		generation := "always"
+		if p.acceptKeyword("BY", "DEFAULT") {
+			generation = "by_default"
+		}
+		setIdentity(col, generation)
+	case p.acceptKeyword("DROP", "IDENTITY"):
+		col.IsIdentity = false
+		col.IdentityGeneration = nil
+	case s.dialect == "sqlserver":
+		// ALTER COLUMN name type [NULL | NOT NULL]
+		typ, _ := p.parseType()
+		s.retypeColumn(col, typ)
+		col.IsNullable = !p.acceptKeyword("NOT", "NULL")
+	default:
+		return false, nil
+	}
+
+	col.SequenceName = nil
+	s.finishColumn(t, col)
+	return true, nil
+}
+
+// retypeColumn replaces the type of the column, the type details are derived again.
+func (s *DDLSchema) retypeColumn(col *model.Column, typ string) {
+	col.BaseType = ""
+	col.MaxLength = nil
+	col.NumericPrecision = nil
+	col.NumericScale = nil
+	col.IsUnsigned = false
+	col.ArrayDimensions = 0
+	col.EnumValues = nil
+	col.UserType = nil
+	col.ElementLogicalType = nil
+	s.fillColumnType(col, typ)
+}
+
+// replaceColumn applies the MySQL MODIFY and CHANGE actions, which redefine a column in place.
+func (s *DDLSchema) replaceColumn(t *model.Table, p *ddlParser, oldName string) error {
+	if oldName == "" {
+		oldName = p.peek().text
+	}
+	i := slices.IndexFunc(t.Columns, func(c *model.Column) bool { return strings.EqualFold(c.Name, oldName) })
+	if i < 0 {
+		return fmt.Errorf("column %s not found in table %s", oldName, t.Name)
+	}
+	old := t.Columns[i]
+	t.Columns = slices.Delete(t.Columns, i, i+1)
+
+	position, err := s.addColumn(t, p)
+	if err != nil {
+		return err
+	}
+	col := t.Columns[len(t.Columns)-1]
+	if position != nil {
+		if err := moveColumn(t, col, position); err != nil {
+			return err
+		}
+	} else {
+		t.Columns = slices.Insert(t.Columns[:len(t.Columns)-1], i, col)
+		renumberColumns(t)
+	}
+
+	if old.IsPrimaryKey && !col.IsPrimaryKey {
+		col.IsPrimaryKey = true
+		s.finishColumn(t, col)
+	}
+	if col.Name != old.Name {
+		s.renameColumnReferences(t, old.Name, col.Name)
+	}
+	return nil
+}
+
+func (s *DDLSchema) alterRename(t *model.Table, p *ddlParser) (bool, error) {
+	switch {
+	case p.acceptKeyword("TO"), p.acceptKeyword("AS"):
+		parts, err := p.parseQualifiedName()
+		if err != nil {
+			return false, err
+		}
+		s.renameTable(t, parts)
+	case p.acceptKeyword("INDEX"), p.acceptKeyword("KEY"):
+		oldName, newName, err := parseRenamePair(p)
+		if err != nil {
+			return false, err
+		}
+		s.renameIndex(t, oldName, newName)
+	case p.acceptKeyword("CONSTRAINT"):
+		oldName, newName, err := parseRenamePair(p)
+		if err != nil {
+			return false, err
+		}
+		s.renameConstraint(t, oldName, newName)
+	default:
+		p.acceptKeyword("COLUMN")
+		oldName, newName, err := parseRenamePair(p)
+		if err != nil {
+			return false, err
+		}
+		col := s.findColumn(t, oldName)
+		if col == nil {
+			return false, fmt.Errorf("column %s not found in table %s", oldName, t.Name)
+		}
+		col.Name = newName
+		s.renameColumnReferences(t, oldName, newName)
+	}
+	return true, nil
+}
+
+// parseRenamePair parses "old TO new".
+func parseRenamePair(p *ddlParser) (string, string, error) {
+	oldName, err := p.parseName()
+	if err != nil {
+		return "", "", err
+	}
+	if !p.acceptKeyword("TO") {
+		return "", "", fmt.Errorf("expected TO, got %q", p.peek().text)
+	}
+	newName, err := p.parseName()
+	if err != nil {
+		return "", "", err
+	}
+	return oldName, newName, nil
+}
+
+func (s *DDLSchema) execDropTable(p *ddlParser) error {
+	p.acceptKeyword("IF", "EXISTS")
+	for {
+		schema, name, err := s.parseTableName(p)
+		if err != nil {
+			return err
+		}
+		if t := s.findTable(schema, name); t != nil {
+			s.tables = slices.DeleteFunc(s.tables, func(e *model.Table) bool { return e == t })
+		}
+		if !p.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+// execDropIndex applies DROP INDEX in the PostgreSQL (schema.name), MySQL (name ON table) and T-SQL (table.name) forms.
+func (s *DDLSchema) execDropIndex(p *ddlParser) error {
+	p.acceptKeyword("CONCURRENTLY")
+	p.acceptKeyword("IF", "EXISTS")
+	for {
+		parts, err := p.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		name := parts[len(parts)-1]
+		var tables []*model.Table
+		switch {
+		case p.acceptKeyword("ON"):
+			schema, table, err := s.parseTableName(p)
+			if err != nil {
+				return err
+			}
+			tables = []*model.Table{s.findTable(schema, table)}
+		case len(parts) > 1 && s.dialect == "sqlserver":
+			schema, table := s.splitName(parts[:len(parts)-1])
+			tables = []*model.Table{s.findTable(schema, table)}
+		default:
+			schema, _ := s.splitName(parts)
+			for _, t := range s.tables {
+				if strings.EqualFold(t.Schema, schema) {
+					tables = append(tables, t)
+				}
+			}
+		}
+		for _, t := range tables {
+			if t != nil {
+				s.dropIndex(t, name)
+			}
+		}
+		if !p.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+// execRenameTable applies the MySQL RENAME TABLE a TO b [, c TO d].
+func (s *DDLSchema) execRenameTable(p *ddlParser) error {
+	for {
+		schema, name, err := s.parseTableName(p)
+		if err != nil {
+			return err
+		}
+		if !p.acceptKeyword("TO") {
+			return fmt.Errorf("expected TO, got %q", p.peek().text)
+		}
+		parts, err := p.parseQualifiedName()
+		if err != nil {
+			return err
+		}
+		if t := s.findTable(schema, name); t != nil {
+			s.renameTable(t, parts)
+		}
+		if !p.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+// execAlterIndex applies the PostgreSQL ALTER INDEX name RENAME TO new.
+func (s *DDLSchema) execAlterIndex(p *ddlParser) error {
+	p.acceptKeyword("IF", "EXISTS")
+	parts, err := p.parseQualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.acceptKeyword("RENAME", "TO") {
+		return errSkipStatement
+	}
+	newName, err := p.parseName()
+	if err != nil {
+		return err
+	}
+	schema, name := s.splitName(parts)
+	for _, t := range s.tables {
+		if strings.EqualFold(t.Schema, schema) {
+			s.renameIndex(t, name, newName)
+		}
+	}
+	return nil
+}
+
+// execSpRename applies EXEC sp_rename 'schema.table[.column]', 'new' [, 'COLUMN' | 'INDEX' | 'OBJECT'].
+func (s *DDLSchema) execSpRename(args []string) error {
+	if len(args) < 2 {
+		return errSkipStatement
+	}
+	parts := strings.Split(strings.NewReplacer("[", "", "]", "").Replace(args[0]), ".")
+	newName := args[1]
+	objectType := "OBJECT"
+	if len(args) > 2 {
+		objectType = strings.ToUpper(args[2])
+	}
+
+	switch objectType {
+	case "COLUMN", "INDEX":
+		if len(parts) < 2 {
+			return errSkipStatement
+		}
+		schema, name := s.splitName(parts[:len(parts)-1])
+		t := s.findTable(schema, name)
+		if t == nil {
+			return errSkipStatement
+		}
+		if objectType == "INDEX" {
+			s.renameIndex(t, parts[len(parts)-1], newName)
+			return nil
+		}
+		col := s.findColumn(t, parts[len(parts)-1])
+		if col == nil {
+			return errSkipStatement
+		}
+		oldName := col.Name
+		col.Name = newName
+		s.renameColumnReferences(t, oldName, newName)
+		return nil
+	}
+
+	schema, name := s.splitName(parts)
+	if t := s.findTable(schema, name); t != nil {
+		s.renameTable(t, []string{t.Schema, newName})
+		return nil
+	}
+	for _, t := range s.tables {
+		if strings.EqualFold(t.Schema, schema) {
+			s.renameConstraint(t, name, newName)
+		}
+	}
+	return nil
+}
+
+func (s *DDLSchema) renameTable(t *model.Table, parts []string) {
+	oldSchema, oldName := t.Schema, t.Name
+	t.Schema, t.Name = s.splitName(parts)
+	if len(parts) == 1 {
+		t.Schema = oldSchema // RENAME TO keeps the schema
+	}
+	for _, fk := range t.ForeignKeys {
+		fk.Schema, fk.Table = t.Schema, t.Name
+	}
+	for _, other := range s.tables {
+		for _, fk := range other.ForeignKeys {
+			if strings.EqualFold(fk.ReferencedSchema, oldSchema) && strings.EqualFold(fk.ReferencedTable, oldName) {
+				fk.ReferencedSchema, fk.ReferencedTable = t.Schema, t.Name
+			}
+		}
+	}
+}
+
+// renameColumnReferences replaces the column name in the indexes, constraints and foreign keys that use the column.
+func (s *DDLSchema) renameColumnReferences(t *model.Table, oldName, newName string) {
+	rename := func(columns []string) {
+		for i, c := range columns {
+			if strings.EqualFold(c, oldName) {
+				columns[i] = newName
+			}
+		}
+	}
+	for _, idx := range t.Indexes {
+		for _, c := range idx.Columns {
+			if strings.EqualFold(c.Name, oldName) {
+				c.Name = newName
+			}
+		}
+	}
+	for _, c := range t.Constraints {
+		rename(c.Columns)
+	}
+	for _, fk := range t.ForeignKeys {
+		rename(fk.Columns)
+	}
+	for _, other := range s.tables {
+		for _, fk := range other.ForeignKeys {
+			if strings.EqualFold(fk.ReferencedSchema, t.Schema) && strings.EqualFold(fk.ReferencedTable, t.Name) {
+				rename(fk.ReferencedColumns)
+			}
+		}
+	}
+}
+
+// dropColumn removes the column together with the indexes, constraints and foreign keys that use it.
+func (s *DDLSchema) dropColumn(t *model.Table, name string) {
+	uses := func(columns []string) bool {
+		return slices.ContainsFunc(columns, func(c string) bool { return strings.EqualFold(c, name) })
+	}
+	t.Columns = slices.DeleteFunc(t.Columns, func(c *model.Column) bool { return strings.EqualFold(c.Name, name) })
+	renumberColumns(t)
+	t.Indexes = slices.DeleteFunc(t.Indexes, func(idx *model.Index) bool {
+		return slices.ContainsFunc(idx.Columns, func(c *model.IndexColumn) bool { return strings.EqualFold(c.Name, name) })
+	})
+	t.Constraints = slices.DeleteFunc(t.Constraints, func(c *model.Constraint) bool { return uses(c.Columns) })
+	t.ForeignKeys = slices.DeleteFunc(t.ForeignKeys, func(fk *model.ForeignKey) bool { return uses(fk.Columns) })
+}
+
+// dropConstraint removes the constraint or foreign key, the index backing a key constraint is removed too.
+func (s *DDLSchema) dropConstraint(t *model.Table, name string) {
+	t.ForeignKeys = slices.DeleteFunc(t.ForeignKeys, func(fk *model.ForeignKey) bool { return strings.EqualFold(fk.Name, name) })
+	t.Constraints = slices.DeleteFunc(t.Constraints, func(c *model.Constraint) bool {
+		if !strings.EqualFold(c.Name, name) {
+			return false
+		}
+		if c.Type == model.ConstraintTypePrimaryKey {
+			for _, col := range t.Columns {
+				col.IsPrimaryKey = false
+			}
+		}
+		if c.IndexName != nil {
+			t.Indexes = slices.DeleteFunc(t.Indexes, func(idx *model.Index) bool { return idx.Name == *c.IndexName })
+		}
+		return true
+	})
+}
+
+// dropIndex removes the index, a MySQL unique key is both an index and a constraint.
+func (s *DDLSchema) dropIndex(t *model.Table, name string) {
+	t.Indexes = slices.DeleteFunc(t.Indexes, func(idx *model.Index) bool { return strings.EqualFold(idx.Name, name) })
+	t.Constraints = slices.DeleteFunc(t.Constraints, func(c *model.Constraint) bool {
+		return c.IndexName != nil && strings.EqualFold(*c.IndexName, name)
+	})
+}
+
+func (s *DDLSchema) renameIndex(t *model.Table, oldName, newName string) {
+	for _, idx := range t.Indexes {
+		if strings.EqualFold(idx.Name, oldName) {
+			idx.Name = newName
+		}
+	}
+	for _, c := range t.Constraints {
+		if c.IndexName != nil && strings.EqualFold(*c.IndexName, oldName) {
+			name := newName
+			c.IndexName = &name
+		}
+	}
+}
+
+// renameConstraint renames the constraint or foreign key, the index backing a key constraint shares its name.
+func (s *DDLSchema) renameConstraint(t *model.Table, oldName, newName string) {
+	for _, fk := range t.ForeignKeys {
+		if strings.EqualFold(fk.Name, oldName) {
+			fk.Name = newName
+		}
+	}
+	for _, c := range t.Constraints {
+		if !strings.EqualFold(c.Name, oldName) {
+			continue
+		}
+		c.Name = newName
+		if c.IndexName != nil && strings.EqualFold(*c.IndexName, oldName) {
+			s.renameIndex(t, oldName, newName)
+		}
+	}
+}
+
+// moveColumn moves the column to the position of the MySQL FIRST or AFTER clause.
+func moveColumn(t *model.Table, col *model.Column, position *columnPosition) error {
+	t.Columns = slices.DeleteFunc(t.Columns, func(c *model.Column) bool { return c == col })
+	i := 0
+	if position.after != "" {
+		i = slices.IndexFunc(t.Columns, func(c *model.Column) bool { return strings.EqualFold(c.Name, position.after) })
+		if i < 0 {
+			return fmt.Errorf("column %s not found in table %s", position.after, t.Name)
+		}
+		i++
+	}
+	t.Columns = slices.Insert(t.Columns, i, col)
+	renumberColumns(t)
+	return nil
+}
+
+func renumberColumns(t *model.Table) {
+	for i, col := range t.Columns {
+		col.Ordinal = i + 1
+	}
+}
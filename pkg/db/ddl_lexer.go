@@ -0,0 +1,175 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+)
+
+type sqlTokenKind int
+
+const (
+	sqlIdent       sqlTokenKind = iota // Bare word, including keywords
+	sqlQuotedIdent                     // "name", `name` or [name]
+	sqlString                          // 'text', N'text', E'text' or $tag$text$tag$
+	sqlNumber
+	sqlSymbol
+)
+
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string // Unquoted identifier, unescaped string or the symbol itself
+	start int    // Offset of the first byte in the source
+	end   int    // Offset after the last byte in the source
+}
+
+// isKeyword reports whether the token is the bare word kw, case-insensitively.
+func (t sqlToken) isKeyword(kw string) bool {
+	return t.kind == sqlIdent && strings.EqualFold(t.text, kw)
+}
+
+func (t sqlToken) isSymbol(s string) bool {
+	return t.kind == sqlSymbol && t.text == s
+}
+
+// isName reports whether the token can be used as an object name.
+func (t sqlToken) isName() bool {
+	return t.kind == sqlIdent || t.kind == sqlQuotedIdent
+}
+
+// splitSQLStatements tokenizes the SQL script and splits it into statements,
+// statements are separated by semicolons or by a T-SQL GO line, comments are dropped.
+func splitSQLStatements(src string, dialect string) [][]sqlToken {
+	var statements [][]sqlToken
+	var cur []sqlToken
+	flush := func() {
+		if len(cur) > 0 {
+			statements = append(statements, cur)
+		}
+		cur = nil
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case c == '-' && strings.HasPrefix(src[i:], "--"), c == '#' && dialect == "mysql":
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				i = len(src)
+				break
+			}
+			i += end + 4
+		case c == '\'':
+			text, end := scanSQLString(src, i)
+			cur = append(cur, sqlToken{kind: sqlString, text: text, start: i, end: end})
+			i = end
+		case (c == 'N' || c == 'n' || c == 'E' || c == 'e') && i+1 < len(src) && src[i+1] == '\'':
+			text, end := scanSQLString(src, i+1)
+			cur = append(cur, sqlToken{kind: sqlString, text: text, start: i, end: end})
+			i = end
+		case c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(src[i+1:], closing)
+			if end < 0 {
+				end = len(src) - i - 1
+			}
+			cur = append(cur, sqlToken{kind: sqlQuotedIdent, text: src[i+1 : i+1+end], start: i, end: min(i+end+2, len(src))})
+			i = min(i+end+2, len(src))
+		case c == '$' && dollarQuoteTag(src[i:]) != "":
+			tag := dollarQuoteTag(src[i:])
+			body := src[i+len(tag):]
+			end := strings.Index(body, tag)
+			if end < 0 {
+				end = len(body)
+			}
+			stop := min(i+len(tag)+end+len(tag), len(src))
+			cur = append(cur, sqlToken{kind: sqlString, text: body[:end], start: i, end: stop})
+			i = stop
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			cur = append(cur, sqlToken{kind: sqlNumber, text: src[i:j], start: i, end: j})
+			i = j
+		case isIdentStart(rune(c)) || c >= 0x80:
+			j := i
+			for j < len(src) && (isIdentPart(rune(src[j])) || src[j] >= 0x80) {
+				j++
+			}
+			word := src[i:j]
+			if strings.EqualFold(word, "GO") && isAloneOnLine(src, i, j) {
+				flush()
+			} else {
+				cur = append(cur, sqlToken{kind: sqlIdent, text: word, start: i, end: j})
+			}
+			i = j
+		case c == ';':
+			flush()
+			i++
+		case c == ':' && strings.HasPrefix(src[i:], "::"):
+			cur = append(cur, sqlToken{kind: sqlSymbol, text: "::", start: i, end: i + 2})
+			i += 2
+		default:
+			cur = append(cur, sqlToken{kind: sqlSymbol, text: string(c), start: i, end: i + 1})
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// scanSQLString scans the single-quoted string starting at src[start], doubled quotes are unescaped.
+func scanSQLString(src string, start int) (string, int) {
+	var sb strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		if src[i] == '\'' {
+			if i+1 < len(src) && src[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), i + 1
+		}
+		sb.WriteByte(src[i])
+	}
+	return sb.String(), len(src)
+}
+
+// dollarQuoteTag returns the PostgreSQL dollar quote tag ($$ or $tag$) at the beginning of s.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1]
+		}
+		if !isIdentPart(rune(s[i])) {
+			return ""
+		}
+	}
+	return ""
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '@' || r == '#'
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' || r == '@' || r == '#'
+}
+
+func isAloneOnLine(src string, start, end int) bool {
+	lineStart := strings.LastIndexByte(src[:start], '\n') + 1
+	lineEnd := strings.IndexByte(src[end:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(src) - end
+	}
+	return strings.TrimSpace(src[lineStart:start]) == "" && strings.TrimSpace(src[end:end+lineEnd]) == ""
+}
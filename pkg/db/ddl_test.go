@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDDL_whenSQLite_thenSameAsLiveIntrospection(t *testing.T) {
+	ddl := `CREATE TABLE user ( -- User account information
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username VARCHAR(64) NOT NULL, -- Username, required
+		email VARCHAR(128) NOT NULL DEFAULT '', -- User email, required
+		price DECIMAL(10, 2) NOT NULL CHECK (price > 0),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		total INTEGER GENERATED ALWAYS AS (price * 2) STORED,
+		CONSTRAINT unique_email UNIQUE (email),
+		CONSTRAINT ck_price CHECK (price < 100)
+	);
+	CREATE TABLE post (
+		id INTEGER PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES user (id) ON DELETE CASCADE,
+		title TEXT UNIQUE,
+		slug TEXT,
+		FOREIGN KEY (slug) REFERENCES user (id)
+	);
+	CREATE INDEX idx_name ON user (username);
+	CREATE UNIQUE INDEX idx_title ON post (title, slug);
+	CREATE VIEW v_user AS SELECT id FROM user;`
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+	_, err = db.Exec(ddl)
+	require.NoError(t, err)
+
+	tables, err := ParseDDL(ddl, "sqlite3", "main")
+	require.NoError(t, err)
+	assert.Len(t, tables, 2)
+
+	for _, name := range []string{"user", "post"} {
+		live, err := GenSQLiteTable(context.Background(), db, "main", name, nil)
+		require.NoError(t, err)
+		offline, err := GenTable(context.Background(), StaticIntrospector{Schema: "main", Tables: tables}, nil, "main", name, nil)
+		require.NoError(t, err)
+		assert.Equal(t, live, offline)
+	}
+}
+
+func TestParseDDL_whenMySQL(t *testing.T) {
+	tables, err := ParseDDL("CREATE TABLE `user` (\n"+
+		"  `id` BIGINT(20) UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Primary key',\n"+
+		"  `username` VARCHAR(64) NOT NULL,\n"+
+		"  `status` ENUM('active','inactive') DEFAULT 'active',\n"+
+		"  `is_admin` BOOLEAN NOT NULL DEFAULT 0,\n"+
+		"  `balance` DECIMAL NOT NULL DEFAULT 0.00,\n"+
+		"  `created_at` TIMESTAMP NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n"+
+		"  PRIMARY KEY (`id`),\n"+
+		"  UNIQUE KEY `uk_username` (`username`),\n"+
+		"  KEY `idx_status` (`status`, `created_at`),\n"+
+		"  CHECK (`balance` >= 0)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='User account';\n"+
+		"CREATE TABLE `order` (\n"+
+		"  `id` INT PRIMARY KEY,\n"+
+		"  `user_id` BIGINT UNSIGNED NOT NULL,\n"+
+		"  FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE\n"+
+		");\n"+
+		"INSERT INTO `user` (`username`) VALUES ('admin');", "mysql", "test")
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+
+	tb := tables[0]
+	assert.Equal(t, "test", tb.Schema)
+	assert.Equal(t, "user", tb.Name)
+	assert.Equal(t, "User account", *tb.Comment)
+
+	id := tb.Columns[0]
+	assert.Equal(t, "bigint unsigned", id.Type)
+	assert.Equal(t, "bigint", id.BaseType)
+	assert.True(t, id.IsUnsigned)
+	assert.True(t, id.IsPrimaryKey)
+	assert.False(t, id.IsNullable)
+	assert.True(t, id.IsAutoIncrement)
+	assert.Equal(t, "Primary key", *id.Comment)
+	assert.Equal(t, model.LogicalTypeInt64, id.LogicalType)
+
+	assert.Equal(t, "varchar(64)", tb.Columns[1].Type)
+	assert.Equal(t, 64, *tb.Columns[1].MaxLength)
+	assert.Equal(t, []string{"active", "inactive"}, tb.Columns[2].EnumValues)
+	assert.Equal(t, "active", *tb.Columns[2].DefaultValue)
+	assert.Equal(t, "tinyint(1)", tb.Columns[3].Type)
+	assert.Equal(t, model.LogicalTypeBool, tb.Columns[3].LogicalType)
+	assert.Equal(t, "decimal", tb.Columns[4].Type)
+	assert.Equal(t, 10, *tb.Columns[4].NumericPrecision)
+	assert.Equal(t, 0, *tb.Columns[4].NumericScale)
+	assert.True(t, tb.Columns[5].IsNullable)
+	assert.Equal(t, "CURRENT_TIMESTAMP", *tb.Columns[5].DefaultValue)
+
+	require.Len(t, tb.Indexes, 3)
+	assert.Equal(t, "PRIMARY", tb.Indexes[0].Name)
+	assert.True(t, tb.Indexes[0].IsPrimary)
+	assert.Equal(t, "uk_username", tb.Indexes[1].Name)
+	assert.True(t, tb.Indexes[1].IsUnique)
+	assert.Equal(t, "idx_status", tb.Indexes[2].Name)
+	assert.Equal(t, "created_at", tb.Indexes[2].Columns[1].Name)
+
+	require.Len(t, tb.Constraints, 3)
+	assert.Equal(t, "user_chk_1", tb.Constraints[2].Name)
+	assert.Equal(t, []string{"balance"}, tb.Constraints[2].Columns)
+
+	fk := tables[1].ForeignKeys[0]
+	assert.Equal(t, "order_ibfk_1", fk.Name)
+	assert.Equal(t, "user", fk.ReferencedTable)
+	assert.Equal(t, []string{"id"}, fk.ReferencedColumns)
+	assert.Equal(t, "CASCADE", fk.OnDelete)
+	assert.Equal(t, "NO ACTION", fk.OnUpdate)
+}
+
+func TestParseDDL_whenPostgres(t *testing.T) {
+	tables, err := ParseDDL(`
+		CREATE TYPE mood AS ENUM ('happy', 'sad');
+
+		CREATE TABLE IF NOT EXISTS public."User" (
+			id         BIGSERIAL PRIMARY KEY,
+			Name       VARCHAR(64) NOT NULL UNIQUE,
+			tags       TEXT[],
+			mood       mood DEFAULT 'happy',
+			amount     NUMERIC(10, 2),
+			created_at TIMESTAMPTZ(3) NOT NULL DEFAULT now(),
+			code       INT GENERATED ALWAYS AS IDENTITY
+		);
+
+		COMMENT ON TABLE "User" IS 'User account';
+		COMMENT ON COLUMN public."User".name IS 'Display name';
+
+		CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_user_created_at ON "User" USING btree (created_at DESC);
+
+		CREATE FUNCTION noop() RETURNS void AS $$ BEGIN END; $$ LANGUAGE plpgsql;
+	`, "postgres", "public")
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+
+	tb := tables[0]
+	assert.Equal(t, "User", tb.Name)
+	assert.Equal(t, "User account", *tb.Comment)
+
+	id := tb.Columns[0]
+	assert.Equal(t, "bigint", id.Type)
+	assert.Equal(t, "nextval('User_id_seq'::regclass)", *id.DefaultValue)
+	assert.Equal(t, "public.User_id_seq", *id.SequenceName)
+	assert.True(t, id.IsDatabaseGenerated)
+	assert.False(t, id.IsNullable)
+
+	name := tb.Columns[1]
+	assert.Equal(t, "name", name.Name)
+	assert.Equal(t, "character varying(64)", name.Type)
+	assert.Equal(t, "Display name", *name.Comment)
+
+	assert.Equal(t, "text[]", tb.Columns[2].Type)
+	assert.Equal(t, 1, tb.Columns[2].ArrayDimensions)
+	assert.Equal(t, model.LogicalTypeArray, tb.Columns[2].LogicalType)
+	assert.Equal(t, "mood", tb.Columns[3].Type)
+	assert.Equal(t, []string{"happy", "sad"}, tb.Columns[3].EnumValues)
+	assert.Equal(t, model.UserTypeKindEnum, tb.Columns[3].UserType.Kind)
+	assert.Equal(t, "numeric(10,2)", tb.Columns[4].Type)
+	assert.Equal(t, 2, *tb.Columns[4].NumericScale)
+	assert.Equal(t, "timestamp(3) with time zone", tb.Columns[5].Type)
+	assert.Equal(t, model.LogicalTypeTimestamptz, tb.Columns[5].LogicalType)
+	assert.True(t, tb.Columns[6].IsIdentity)
+	assert.Equal(t, "always", *tb.Columns[6].IdentityGeneration)
+
+	require.Len(t, tb.Indexes, 3)
+	assert.Equal(t, "User_pkey", tb.Indexes[0].Name)
+	assert.Equal(t, "User_name_key", tb.Indexes[1].Name)
+	assert.Equal(t, "idx_user_created_at", tb.Indexes[2].Name)
+	assert.Equal(t, "created_at", tb.Indexes[2].Columns[0].Name)
+}
+
+func TestParseDDL_whenSQLServer(t *testing.T) {
+	tables, err := ParseDDL(`
+		CREATE TABLE [dbo].[user] (
+			[id] [int] IDENTITY(1,1) NOT NULL,
+			[username] [nvarchar](64) NOT NULL,
+			[bio] NVARCHAR(MAX) NULL,
+			[price] DECIMAL NOT NULL CONSTRAINT DF_user_price DEFAULT ((0)),
+			[total] AS ([price] * 2) PERSISTED,
+			CONSTRAINT [PK_user] PRIMARY KEY CLUSTERED ([id] ASC)
+		)
+		GO
+		CREATE UNIQUE NONCLUSTERED INDEX [IX_user_username] ON [dbo].[user] ([username])
+		GO
+		EXEC sp_addextendedproperty 'MS_Description', N'User account', 'SCHEMA', 'dbo', 'TABLE', 'user'
+		GO
+		EXEC sys.sp_addextendedproperty @name = N'MS_Description', @value = N'Login name',
+			@level0type = N'SCHEMA', @level0name = N'dbo', @level1type = N'TABLE', @level1name = N'user',
+			@level2type = N'COLUMN', @level2name = N'username'
+		GO
+	`, "sqlserver", "dbo")
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+
+	tb := tables[0]
+	assert.Equal(t, "dbo", tb.Schema)
+	assert.Equal(t, "user", tb.Name)
+	assert.Equal(t, "User account", *tb.Comment)
+
+	assert.Equal(t, "int", tb.Columns[0].Type)
+	assert.True(t, tb.Columns[0].IsIdentity)
+	assert.True(t, tb.Columns[0].IsPrimaryKey)
+	assert.Equal(t, "nvarchar", tb.Columns[1].Type)
+	assert.Equal(t, 64, *tb.Columns[1].MaxLength)
+	assert.Equal(t, "Login name", *tb.Columns[1].Comment)
+	assert.Nil(t, tb.Columns[2].MaxLength)
+	assert.Equal(t, "((0))", *tb.Columns[3].DefaultValue)
+	assert.Equal(t, 18, *tb.Columns[3].NumericPrecision)
+	assert.True(t, tb.Columns[4].IsGenerated)
+	assert.Equal(t, "[price] * 2", *tb.Columns[4].GenerationExpression)
+	assert.Equal(t, "stored", *tb.Columns[4].GenerationKind)
+
+	require.Len(t, tb.Indexes, 2)
+	assert.Equal(t, "PK_user", tb.Indexes[0].Name)
+	assert.Equal(t, "IX_user_username", tb.Indexes[1].Name)
+	assert.Equal(t, "PK_user", tb.Constraints[0].Name)
+}
+
+func TestDDLSchema_Exec_whenStatementIsNotDDL_thenSkip(t *testing.T) {
+	s := NewDDLSchema("postgres", "public")
+	skipped, err := s.Exec(`CREATE TABLE t (id int); INSERT INTO t VALUES (1); CREATE VIEW v AS SELECT * FROM t;`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"INSERT INTO t VALUES (1)", "CREATE VIEW v AS SELECT * FROM t"}, skipped)
+	assert.Len(t, s.Tables(), 1)
+}
+
+func TestParseDDL_whenStatementIsMalformed_thenReturnError(t *testing.T) {
+	_, err := ParseDDL(`CREATE TABLE t (id int, CONSTRAINT pk PRIMARY KEY id)`, "postgres", "public")
+	assert.Error(t, err)
+}
+
+func Test_splitSQLStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		dialect string
+		want    [][]string
+	}{
+		{
+			name: "semicolons and comments",
+			src:  "SELECT 1; -- a;b\n/* c; */ SELECT 'x;y'",
+			want: [][]string{{"SELECT", "1"}, {"SELECT", "x;y"}},
+		},
+		{
+			name:    "mysql hash comment and backticks",
+			src:     "# note\nDROP TABLE `a;b`",
+			dialect: "mysql",
+			want:    [][]string{{"DROP", "TABLE", "a;b"}},
+		},
+		{
+			name: "go separator",
+			src:  "SELECT N'it''s'\nGO\nSELECT [go]",
+			want: [][]string{{"SELECT", "it's"}, {"SELECT", "go"}},
+		},
+		{
+			name: "dollar quotes",
+			src:  "DO $body$ BEGIN; END $body$",
+			want: [][]string{{"DO", " BEGIN; END "}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, statement := range splitSQLStatements(tt.src, tt.dialect) {
+				var texts []string
+				for _, tok := range statement {
+					texts = append(texts, tok.text)
+				}
+				got = append(got, texts)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseDDL_whenIndexHasExpression_thenKeepExpression(t *testing.T) {
+	tables, err := ParseDDL(`
+		CREATE TABLE user (id INT, name VARCHAR(64), email VARCHAR(64));
+		CREATE INDEX idx_name ON user (name(10), email DESC);
+		CREATE INDEX idx_lower ON user ((lower(name)), upper(email), id);`, "mysql", "")
+	require.NoError(t, err)
+
+	columnsOf := func(name string) []string {
+		var columns []string
+		for _, idx := range tables[0].Indexes {
+			if idx.Name == name {
+				for _, c := range idx.Columns {
+					columns = append(columns, c.Name)
+				}
+			}
+		}
+		return columns
+	}
+	assert.Equal(t, []string{"name", "email"}, columnsOf("idx_name"))
+	assert.Equal(t, []string{"(lower(name))", "upper(email)", "id"}, columnsOf("idx_lower"))
+}
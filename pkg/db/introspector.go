@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
+)
+
+// Introspector reads the metadata of tables from a database, there is one implementation per dialect.
+//
+// Implementations can embed one of the built-in introspectors to reuse most of it,
+// e.g. a dialect for a PostgreSQL fork can embed PostgresIntrospector and only override GetColumns.
+type Introspector interface {
+	// DefaultSchema returns the schema used when neither the table nor the database config specifies one,
+	// u is nil for offline schema sources such as DDL files.
+	DefaultSchema(u *dburl.URL) string
+	// ListTables lists the names of all tables in the given schema, used to resolve table name patterns.
+	ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error)
+	// GetTable returns the table without columns, indexes, foreign keys and constraints, nil if the table does not exist.
+	GetTable(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, error)
+	GetColumns(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error)
+	GetIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Index, error)
+	GetForeignKeys(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error)
+	GetConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Constraint, error)
+}
+
+// DriverNamer is implemented by introspectors whose database/sql driver is registered under another name than the dburl driver name,
+// e.g. SQLite is served by the pure Go driver modernc.org/sqlite, which registers itself as sqlite.
+type DriverNamer interface {
+	// DriverName returns the name the database/sql driver is registered with, see sql.Register.
+	DriverName() string
+}
+
+// LogicalTypeFiller is implemented by introspectors that map the types of their dialect to logical types,
+// schema sources that do not read a live database, such as DDL files, use it to fill the logical types of the dialect.
+// Columns of dialects whose introspector does not implement it only get the common logical types.
+type LogicalTypeFiller interface {
+	// FillLogicalType sets the logical type of the column from its base type and type details.
+	FillLogicalType(col *model.Column)
+}
+
+var (
+	introspectorsMu sync.RWMutex
+	introspectors   = make(map[string]Introspector)
+)
+
+func init() {
+	RegisterIntrospector("mysql", MySQLIntrospector{})
+	RegisterIntrospector("postgres", PostgresIntrospector{})
+	RegisterIntrospector("sqlserver", MssqlIntrospector{})
+	RegisterIntrospector("sqlite3", SQLiteIntrospector{})
+	RegisterIntrospector("moderncsqlite", SQLiteIntrospector{})
+}
+
+// RegisterIntrospector makes the introspector available for the given dburl driver name (dburl.URL.Driver), e.g. postgres.
+// Registering a driver name again replaces the previous introspector, so built-in dialects can be overridden.
+func RegisterIntrospector(driver string, introspector Introspector) {
+	if introspector == nil {
+		panic("db: RegisterIntrospector introspector is nil")
+	}
+	introspectorsMu.Lock()
+	defer introspectorsMu.Unlock()
+	introspectors[driver] = introspector
+}
+
+// GetIntrospector returns the introspector registered for the given dburl driver name.
+func GetIntrospector(driver string) (Introspector, bool) {
+	introspectorsMu.RLock()
+	defer introspectorsMu.RUnlock()
+	introspector, ok := introspectors[driver]
+	return introspector, ok
+}
+
+// GenTable generates the table using the given introspector and fills the Table structure, nil if the table does not exist.
+func GenTable(ctx context.Context, introspector Introspector, db *sql.DB, schema, table string, ignoreColumns []string) (*model.Table, error) {
+	t, err := introspector.GetTable(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, nil
+	}
+
+	columns, err := introspector.GetColumns(ctx, db, schema, table, ignoreColumns)
+	if err != nil {
+		return nil, err
+	}
+	t.Columns = columns
+
+	indexes, err := introspector.GetIndexes(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	t.Indexes = indexes
+
+	foreignKeys, err := introspector.GetForeignKeys(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	t.ForeignKeys = foreignKeys
+
+	constraints, err := introspector.GetConstraints(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	t.Constraints = constraints
+	fillConstraintDetails(t)
+	normalizeCollections(t)
+
+	return t, nil
+}
+
+// normalizeCollections makes the empty collections of the table the same whatever the introspector or schema source:
+// indexes are empty but not nil, foreign keys, constraints and enum values are nil.
+func normalizeCollections(t *model.Table) {
+	if t.Indexes == nil {
+		t.Indexes = []*model.Index{}
+	}
+	if len(t.ForeignKeys) == 0 {
+		t.ForeignKeys = nil
+	}
+	if len(t.Constraints) == 0 {
+		t.Constraints = nil
+	}
+	for _, col := range t.Columns {
+		if len(col.EnumValues) == 0 {
+			col.EnumValues = nil
+		}
+	}
+}
@@ -8,46 +8,104 @@ import (
 	"sort"
 
 	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
 )
 
+// MssqlIntrospector is the Introspector for MSSQL.
+type MssqlIntrospector struct{}
+
+// DefaultSchema returns dbo, the default schema in SQL Server.
+func (MssqlIntrospector) DefaultSchema(u *dburl.URL) string {
+	return "dbo"
+}
+
+func (MssqlIntrospector) ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	return ListMssqlTables(ctx, db, schema)
+}
+
+func (MssqlIntrospector) GetTable(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, error) {
+	return getMssqlTableInfo(ctx, db, schema, table)
+}
+
+func (MssqlIntrospector) GetColumns(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	return getMssqlColumnsInfo(ctx, db, schema, table, ignoreColumns)
+}
+
+func (MssqlIntrospector) GetIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Index, error) {
+	return getMssqlIndexesInfo(ctx, db, schema, table)
+}
+
+func (MssqlIntrospector) GetForeignKeys(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	return getMssqlForeignKeysInfo(ctx, db, schema, table)
+}
+
+func (MssqlIntrospector) GetConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Constraint, error) {
+	return getMssqlConstraintsInfo(ctx, db, schema, table)
+}
+
 // GenMssqlTable generates an MSSQL table and fills the Table structure.
-func GenMssqlTable(ctx context.Context, db *sql.DB, schema string, name string, ignoreColumns []string) (*model.Table, error) {
-	t, err := getMssqlTableInfo(ctx, db, schema, name)
-	if err != nil {
-		return nil, err
-	}
-	if t == nil {
-		return nil, nil
-	}
+func GenMssqlTable(ctx context.Context, db *sql.DB, schema, name string, ignoreColumns []string) (*model.Table, error) {
+	return GenTable(ctx, MssqlIntrospector{}, db, schema, name, ignoreColumns)
+}
 
-	columns, err := getMssqlColumnsInfo(ctx, db, schema, name, ignoreColumns)
+// ListMssqlTables lists the names of all tables and views in the given MSSQL schema.
+func ListMssqlTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const tablesSql = `
+		SELECT o.name AS table_name
+		FROM sys.objects o
+		JOIN sys.schemas s ON o.schema_id = s.schema_id
+		WHERE s.name = @p1
+		  AND o.type IN ('U', 'V')
+		ORDER BY o.name;
+	`
+	rows, err := db.QueryContext(ctx, tablesSql, schema)
 	if err != nil {
 		return nil, err
 	}
-	t.Columns = columns
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
 
-	indexes, err := getMssqlIndexesInfo(ctx, db, schema, name)
-	if err != nil {
-		return nil, err
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
 	}
-	t.Indexes = indexes
-
-	return t, nil
+	return tables, nil
 }
 
 func getMssqlTableInfo(ctx context.Context, db *sql.DB, schema, name string) (*model.Table, error) {
 	const tableSql = `
 		SELECT s.name AS table_schema,
-		       t.name AS table_name,
-		       p.value AS table_comment
-		FROM sys.tables t
-		JOIN sys.schemas s ON t.schema_id = s.schema_id
-		LEFT JOIN sys.extended_properties p ON p.major_id = t.object_id AND p.minor_id = 0 AND p.name = 'MS_Description'
+		       o.name AS table_name,
+		       p.value AS table_comment,
+		       CASE
+		           WHEN o.type = 'V' THEN 'view'
+		           WHEN t.is_external = 1 THEN 'foreign_table'
+		           WHEN EXISTS (
+		               SELECT 1
+		               FROM sys.indexes i
+		               JOIN sys.partition_schemes ps ON ps.data_space_id = i.data_space_id
+		               WHERE i.object_id = o.object_id
+		           ) THEN 'partitioned_table'
+		           ELSE 'table'
+		       END AS table_kind
+		FROM sys.objects o
+		JOIN sys.schemas s ON o.schema_id = s.schema_id
+		LEFT JOIN sys.tables t ON t.object_id = o.object_id
+		LEFT JOIN sys.extended_properties p ON p.major_id = o.object_id AND p.minor_id = 0 AND p.name = 'MS_Description'
 		WHERE s.name = @p1
-		  AND t.name = @p2;
+		  AND o.name = @p2
+		  AND o.type IN ('U', 'V');
 	`
 	var t model.Table
-	err := db.QueryRowContext(ctx, tableSql, schema, name).Scan(&t.Schema, &t.Name, &t.Comment)
+	err := db.QueryRowContext(ctx, tableSql, schema, name).Scan(&t.Schema, &t.Name, &t.Comment, &t.Kind)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -57,6 +115,27 @@ func getMssqlTableInfo(ctx context.Context, db *sql.DB, schema, name string) (*m
 	return &t, nil
 }
 
+// mssqlLogicalTypes maps MSSQL specific base types to logical types, see fillLogicalType.
+var mssqlLogicalTypes = map[string]string{
+	"bit":              model.LogicalTypeBool,
+	"float":            model.LogicalTypeFloat64,
+	"money":            model.LogicalTypeDecimal,
+	"smallmoney":       model.LogicalTypeDecimal,
+	"datetime2":        model.LogicalTypeTimestamp,
+	"smalldatetime":    model.LogicalTypeTimestamp,
+	"datetimeoffset":   model.LogicalTypeTimestamptz,
+	"timestamp":        model.LogicalTypeBinary, // rowversion
+	"uniqueidentifier": model.LogicalTypeUUID,
+	"ntext":            model.LogicalTypeText,
+	"xml":              model.LogicalTypeText,
+	"image":            model.LogicalTypeBinary,
+}
+
+// FillLogicalType implements LogicalTypeFiller.
+func (MssqlIntrospector) FillLogicalType(col *model.Column) {
+	fillLogicalType(col, mssqlLogicalTypes)
+}
+
 func getMssqlColumnsInfo(ctx context.Context, db *sql.DB, schema, name string, ignoreColumns []string) ([]*model.Column, error) {
 	const columnsSql = `
 		SELECT 
@@ -74,12 +153,43 @@ func getMssqlColumnsInfo(ctx context.Context, db *sql.DB, schema, name string, i
 				) 
 				THEN 1 ELSE 0 
 			END AS is_primary,
-			ep.value AS comment
+			ep.value AS comment,
+			TYPE_NAME(c.system_type_id) AS base_type,
+			CASE
+				WHEN c.max_length = -1 THEN NULL
+				WHEN TYPE_NAME(c.system_type_id) IN ('nchar', 'nvarchar') THEN c.max_length / 2
+				WHEN TYPE_NAME(c.system_type_id) IN ('char', 'varchar', 'binary', 'varbinary') THEN c.max_length
+			END AS max_length,
+			CASE WHEN TYPE_NAME(c.system_type_id) IN ('decimal', 'numeric') THEN c.precision END AS numeric_precision,
+			CASE WHEN TYPE_NAME(c.system_type_id) IN ('decimal', 'numeric') THEN c.scale END AS numeric_scale,
+			CASE WHEN TYPE_NAME(c.system_type_id) = 'tinyint' THEN 1 ELSE 0 END AS is_unsigned,
+			CASE
+				WHEN c.collation_name IS NULL THEN NULL
+				WHEN TYPE_NAME(c.system_type_id) IN ('nchar', 'nvarchar', 'ntext') THEN 'UTF-16'
+				ELSE 'CP' + CAST(COLLATIONPROPERTY(c.collation_name, 'CodePage') AS VARCHAR(10))
+			END AS charset,
+			c.collation_name AS collation,
+			c.is_identity AS is_identity,
+			CASE WHEN c.is_identity = 1 THEN 'always' END AS identity_generation,
+			c.is_computed AS is_generated,
+			cc.definition AS generation_expression,
+			CASE WHEN c.is_computed = 1 THEN CASE WHEN cc.is_persisted = 1 THEN 'stored' ELSE 'virtual' END END AS generation_kind,
+			CASE WHEN tp.is_user_defined = 1 AND tp.is_assembly_type = 0 AND tp.is_table_type = 0 THEN SCHEMA_NAME(tp.schema_id) END AS user_type_schema,
+			CASE WHEN tp.is_user_defined = 1 AND tp.is_assembly_type = 0 AND tp.is_table_type = 0 THEN tp.name END AS user_type_name,
+			CASE
+				WHEN tp.is_user_defined = 0 OR tp.is_assembly_type = 1 OR tp.is_table_type = 1 THEN NULL
+				WHEN c.max_length = -1 THEN TYPE_NAME(c.system_type_id) + '(max)'
+				WHEN TYPE_NAME(c.system_type_id) IN ('nchar', 'nvarchar') THEN TYPE_NAME(c.system_type_id) + '(' + CAST(c.max_length / 2 AS VARCHAR(10)) + ')'
+				WHEN TYPE_NAME(c.system_type_id) IN ('char', 'varchar', 'binary', 'varbinary') THEN TYPE_NAME(c.system_type_id) + '(' + CAST(c.max_length AS VARCHAR(10)) + ')'
+				WHEN TYPE_NAME(c.system_type_id) IN ('decimal', 'numeric') THEN TYPE_NAME(c.system_type_id) + '(' + CAST(c.precision AS VARCHAR(10)) + ',' + CAST(c.scale AS VARCHAR(10)) + ')'
+				ELSE TYPE_NAME(c.system_type_id)
+			END AS domain_base_type
 		FROM sys.columns c
 		JOIN sys.types tp ON c.user_type_id = tp.user_type_id
-		JOIN sys.tables t ON c.object_id = t.object_id
+		JOIN sys.objects t ON c.object_id = t.object_id AND t.type IN ('U', 'V')
 		JOIN sys.schemas s ON t.schema_id = s.schema_id
 		LEFT JOIN sys.default_constraints dc ON c.default_object_id = dc.object_id
+		LEFT JOIN sys.computed_columns cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
 		LEFT JOIN sys.extended_properties ep ON ep.major_id = c.object_id AND ep.minor_id = c.column_id AND ep.name = 'MS_Description'
 		WHERE s.name = @p1
 		  AND t.name = @p2
@@ -99,9 +209,24 @@ func getMssqlColumnsInfo(ctx context.Context, db *sql.DB, schema, name string, i
 	var columns []*model.Column
 	for rows.Next() {
 		var col model.Column
-		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &col.Comment); err != nil {
+		var userTypeSchema, userTypeName, domainBaseType *string
+		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &col.Comment,
+			&col.BaseType, &col.MaxLength, &col.NumericPrecision, &col.NumericScale, &col.IsUnsigned, &col.Charset, &col.Collation,
+			&col.IsIdentity, &col.IdentityGeneration, &col.IsGenerated, &col.GenerationExpression, &col.GenerationKind,
+			&userTypeSchema, &userTypeName, &domainBaseType); err != nil {
 			return nil, err
 		}
+		fillValueGeneration(&col)
+		if userTypeName != nil {
+			// alias types are the SQL Server counterpart of domains
+			col.UserType = &model.UserType{
+				Schema:   *userTypeSchema,
+				Name:     *userTypeName,
+				Kind:     model.UserTypeKindDomain,
+				BaseType: domainBaseType,
+			}
+		}
+		fillLogicalType(&col, mssqlLogicalTypes)
 		if !slices.Contains(ignoreColumns, col.Name) {
 			columns = append(columns, &col)
 		}
@@ -117,7 +242,7 @@ func getMssqlIndexesInfo(ctx context.Context, db *sql.DB, schema, name string) (
 		       ic.key_ordinal                        AS ordinal,
 		       c.name                                AS column_name
 		FROM sys.indexes i
-		JOIN sys.tables t ON i.object_id = t.object_id
+		JOIN sys.objects t ON i.object_id = t.object_id AND t.type IN ('U', 'V')
 		JOIN sys.schemas s ON t.schema_id = s.schema_id
 		JOIN sys.index_columns ic ON i.object_id = ic.object_id AND i.index_id = ic.index_id
 		JOIN sys.columns c ON ic.object_id = c.object_id AND ic.column_id = c.column_id
@@ -178,3 +303,291 @@ func getMssqlIndexesInfo(ctx context.Context, db *sql.DB, schema, name string) (
 
 	return indexes, nil
 }
+
+func getMssqlForeignKeysInfo(ctx context.Context, db *sql.DB, schema, name string) ([]*model.ForeignKey, error) {
+	const foreignKeysSql = `
+		SELECT fk.name                                              AS constraint_name,
+		       pc.name                                              AS column_name,
+		       rs.name                                              AS referenced_schema,
+		       rt.name                                              AS referenced_table,
+		       rc.name                                              AS referenced_column,
+		       REPLACE(fk.delete_referential_action_desc, '_', ' ') AS on_delete,
+		       REPLACE(fk.update_referential_action_desc, '_', ' ') AS on_update
+		FROM sys.foreign_keys fk
+		JOIN sys.tables t ON fk.parent_object_id = t.object_id
+		JOIN sys.schemas s ON t.schema_id = s.schema_id
+		JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+		JOIN sys.columns pc ON pc.object_id = fkc.parent_object_id AND pc.column_id = fkc.parent_column_id
+		JOIN sys.tables rt ON rt.object_id = fkc.referenced_object_id
+		JOIN sys.schemas rs ON rs.schema_id = rt.schema_id
+		JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id AND rc.column_id = fkc.referenced_column_id
+		WHERE s.name = @p1
+		  AND t.name = @p2
+		ORDER BY fk.name, fkc.constraint_column_id;
+`
+	rows, err := db.QueryContext(ctx, foreignKeysSql, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var foreignKeys []*model.ForeignKey
+	for rows.Next() {
+		var constraintName, column, refSchema, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&constraintName, &column, &refSchema, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != constraintName {
+			foreignKeys = append(foreignKeys, &model.ForeignKey{
+				Name:             constraintName,
+				Schema:           schema,
+				Table:            name,
+				ReferencedSchema: refSchema,
+				ReferencedTable:  refTable,
+				OnDelete:         onDelete,
+				OnUpdate:         onUpdate,
+			})
+		}
+
+		fk := foreignKeys[len(foreignKeys)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+	}
+	return foreignKeys, nil
+}
+
+func getMssqlConstraintsInfo(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Constraint, error) {
+	const constraintsSql = `
+		SELECT kc.name                                                     AS constraint_name,
+		       CASE kc.type WHEN 'PK' THEN 'primary_key' ELSE 'unique' END AS constraint_type,
+		       c.name                                                      AS column_name,
+		       CAST(NULL AS NVARCHAR(MAX))                                 AS check_expression,
+		       i.name                                                      AS index_name,
+		       ic.key_ordinal                                              AS ordinal
+		FROM sys.key_constraints kc
+		JOIN sys.tables t ON kc.parent_object_id = t.object_id
+		JOIN sys.schemas s ON t.schema_id = s.schema_id
+		JOIN sys.indexes i ON i.object_id = kc.parent_object_id AND i.index_id = kc.unique_index_id
+		JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id AND ic.is_included_column = 0
+		JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+		WHERE s.name = @p1
+		  AND t.name = @p2
+		UNION ALL
+		SELECT cc.name, 'check', c.name, cc.definition, CAST(NULL AS SYSNAME), 0
+		FROM sys.check_constraints cc
+		JOIN sys.tables t ON cc.parent_object_id = t.object_id
+		JOIN sys.schemas s ON t.schema_id = s.schema_id
+		LEFT JOIN sys.columns c ON c.object_id = cc.parent_object_id AND c.column_id = cc.parent_column_id
+		WHERE s.name = @p1
+		  AND t.name = @p2
+		ORDER BY constraint_name, ordinal;
+`
+	rows, err := db.QueryContext(ctx, constraintsSql, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	// table level check constraints have no parent column, their columns are inferred from the expression later
+	var constraints []*model.Constraint
+	for rows.Next() {
+		var constraintName, constraintType string
+		var column, checkExpression, indexName *string
+		var ordinal int
+		if err := rows.Scan(&constraintName, &constraintType, &column, &checkExpression, &indexName, &ordinal); err != nil {
+			return nil, err
+		}
+
+		if len(constraints) == 0 || constraints[len(constraints)-1].Name != constraintName {
+			constraints = append(constraints, &model.Constraint{
+				Name:            constraintName,
+				Type:            constraintType,
+				CheckExpression: checkExpression,
+				IndexName:       indexName,
+			})
+		}
+		if column != nil {
+			c := constraints[len(constraints)-1]
+			c.Columns = append(c.Columns, *column)
+		}
+	}
+	return constraints, nil
+}
+
+func (MssqlIntrospector) ListRoutines(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const routinesSql = `
+		SELECT o.name
+		FROM sys.objects o
+		JOIN sys.schemas s ON o.schema_id = s.schema_id
+		WHERE s.name = @p1
+		  AND o.type IN ('P', 'FN', 'IF', 'TF')
+		ORDER BY o.name;
+	`
+	rows, err := db.QueryContext(ctx, routinesSql, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var routines []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		routines = append(routines, name)
+	}
+	return routines, nil
+}
+
+func (MssqlIntrospector) GetRoutines(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Routine, error) {
+	routine, err := getMssqlRoutineInfo(ctx, db, schema, name)
+	if err != nil || routine == nil {
+		return nil, err
+	}
+	return []*model.Routine{routine}, nil
+}
+
+// mssqlTypeNameSql formats the type of a sys.parameters or sys.columns row aliased as c.
+const mssqlTypeNameSql = `
+	CASE
+		WHEN TYPE_NAME(c.user_type_id) <> TYPE_NAME(c.system_type_id) THEN TYPE_NAME(c.user_type_id)
+		WHEN c.max_length = -1 THEN TYPE_NAME(c.system_type_id) + '(max)'
+		WHEN TYPE_NAME(c.system_type_id) IN ('nchar', 'nvarchar') THEN TYPE_NAME(c.system_type_id) + '(' + CAST(c.max_length / 2 AS VARCHAR(10)) + ')'
+		WHEN TYPE_NAME(c.system_type_id) IN ('char', 'varchar', 'binary', 'varbinary') THEN TYPE_NAME(c.system_type_id) + '(' + CAST(c.max_length AS VARCHAR(10)) + ')'
+		WHEN TYPE_NAME(c.system_type_id) IN ('decimal', 'numeric') THEN TYPE_NAME(c.system_type_id) + '(' + CAST(c.precision AS VARCHAR(10)) + ',' + CAST(c.scale AS VARCHAR(10)) + ')'
+		ELSE TYPE_NAME(c.system_type_id)
+	END`
+
+func getMssqlRoutineInfo(ctx context.Context, db *sql.DB, schema, name string) (*model.Routine, error) {
+	routineSql := `
+		SELECT o.object_id,
+		       s.name AS routine_schema,
+		       o.name AS routine_name,
+		       CASE WHEN o.type = 'P' THEN 'procedure' ELSE 'function' END AS kind,
+		       CASE
+		           WHEN o.type IN ('IF', 'TF') THEN 'TABLE'
+		           WHEN o.type = 'FN' THEN (SELECT ` + mssqlTypeNameSql + ` FROM sys.parameters c WHERE c.object_id = o.object_id AND c.parameter_id = 0)
+		       END AS return_type,
+		       p.value AS routine_comment
+		FROM sys.objects o
+		JOIN sys.schemas s ON o.schema_id = s.schema_id
+		LEFT JOIN sys.extended_properties p ON p.major_id = o.object_id AND p.minor_id = 0 AND p.name = 'MS_Description'
+		WHERE s.name = @p1
+		  AND o.name = @p2
+		  AND o.type IN ('P', 'FN', 'IF', 'TF');
+	`
+	var objectID int64
+	var r model.Routine
+	err := db.QueryRowContext(ctx, routineSql, schema, name).Scan(&objectID, &r.Schema, &r.Name, &r.Kind, &r.ReturnType, &r.Comment)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	r.SpecificName = r.Name
+
+	if r.Parameters, err = getMssqlParametersInfo(ctx, db, objectID); err != nil {
+		return nil, err
+	}
+	if r.ResultColumns, err = getMssqlResultColumnsInfo(ctx, db, objectID, r.Kind); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func getMssqlParametersInfo(ctx context.Context, db *sql.DB, objectID int64) ([]*model.RoutineParameter, error) {
+	// parameter_id 0 is the return value of a scalar function, OUTPUT parameters are also inputs
+	parametersSql := `
+		SELECT c.parameter_id,
+		       SUBSTRING(c.name, 2, LEN(c.name)) AS parameter_name,
+		       CASE WHEN c.is_output = 1 THEN 'inout' ELSE 'in' END AS parameter_mode,
+		       ` + mssqlTypeNameSql + ` AS data_type,
+		       TYPE_NAME(c.system_type_id) AS base_type
+		FROM sys.parameters c
+		WHERE c.object_id = @p1
+		  AND c.parameter_id > 0
+		ORDER BY c.parameter_id;
+	`
+	rows, err := db.QueryContext(ctx, parametersSql, objectID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var params []*model.RoutineParameter
+	for rows.Next() {
+		var p model.RoutineParameter
+		var baseType string
+		if err := rows.Scan(&p.Ordinal, &p.Name, &p.Mode, &p.Type, &baseType); err != nil {
+			return nil, err
+		}
+		fillParameterLogicalType(&p, baseType, "sqlserver")
+		params = append(params, &p)
+	}
+	return params, nil
+}
+
+// getMssqlResultColumnsInfo returns the columns of table-valued functions and of the first result set of procedures.
+func getMssqlResultColumnsInfo(ctx context.Context, db *sql.DB, objectID int64, kind string) ([]*model.Column, error) {
+	resultColumnsSql := `
+		SELECT c.column_id, c.name, ` + mssqlTypeNameSql + ` AS data_type, TYPE_NAME(c.system_type_id) AS base_type, c.is_nullable
+		FROM sys.columns c
+		WHERE c.object_id = @p1
+		ORDER BY c.column_id;
+	`
+	if kind == model.RoutineKindProcedure {
+		resultColumnsSql = `
+			SELECT r.column_ordinal, r.name, r.system_type_name,
+			       CASE WHEN CHARINDEX('(', r.system_type_name) > 0 THEN LEFT(r.system_type_name, CHARINDEX('(', r.system_type_name) - 1) ELSE r.system_type_name END AS base_type,
+			       r.is_nullable
+			FROM sys.dm_exec_describe_first_result_set_for_object(@p1, 0) r
+			WHERE r.error_number IS NULL
+			  AND r.name IS NOT NULL
+			ORDER BY r.column_ordinal;
+		`
+	}
+	rows, err := db.QueryContext(ctx, resultColumnsSql, objectID)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var columns []*model.Column
+	for rows.Next() {
+		var col model.Column
+		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.BaseType, &col.IsNullable); err != nil {
+			return nil, err
+		}
+		fillLogicalType(&col, mssqlLogicalTypes)
+		columns = append(columns, &col)
+	}
+	return columns, nil
+}
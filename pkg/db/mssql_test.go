@@ -90,12 +90,22 @@ func TestGenMssqlTable(t *testing.T) {
 	assert.NotNil(t, tb)
 	assert.Equal(t, "dbo", tb.Schema)
 	assert.Equal(t, "user", tb.Name)
+	assert.Equal(t, "table", tb.Kind)
 	assert.Equal(t, "User account information", *tb.Comment)
 
 	// Assertions on the columns
 	assert.Equal(t, 9, len(tb.Columns)) // Columns without deleted_at
 	assert.Equal(t, true, tb.Columns[0].IsPrimaryKey)
 	assert.Equal(t, false, tb.Columns[1].IsPrimaryKey)
+	assert.Equal(t, "nvarchar", tb.Columns[1].Type)
+	assert.Equal(t, "nvarchar", tb.Columns[1].BaseType)
+	assert.Equal(t, 64, *tb.Columns[1].MaxLength)
+	assert.Equal(t, "UTF-16", *tb.Columns[1].Charset)
+	assert.NotNil(t, tb.Columns[1].Collation)
+	assert.Equal(t, true, tb.Columns[0].IsIdentity)
+	assert.Equal(t, "always", *tb.Columns[0].IdentityGeneration)
+	assert.Equal(t, true, tb.Columns[0].IsDatabaseGenerated)
+	assert.Equal(t, false, tb.Columns[1].IsDatabaseGenerated)
 
 	// Assertions on the indexes
 	assert.Equal(t, 5, len(tb.Indexes))
@@ -108,4 +118,97 @@ func TestGenMssqlTable(t *testing.T) {
 	assert.Equal(t, "idx_status_created", tb.Indexes[4].Name)
 	assert.Equal(t, "status", tb.Indexes[4].Columns[0].Name)
 	assert.Equal(t, "created_at", tb.Indexes[4].Columns[1].Name)
+
+	tables, err := ListMssqlTables(ctx, db, schema)
+	require.NoError(t, err)
+	assert.Contains(t, tables, "user")
+
+	_, err = db.Exec(`CREATE TABLE master.dbo.user_role (
+		user_id INT          NULL,
+		role    NVARCHAR(32) NOT NULL,
+		CONSTRAINT fk_user_role_user FOREIGN KEY (user_id) REFERENCES master.dbo.[user] (id) ON DELETE SET NULL
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenMssqlTable(ctx, db, schema, "user_role", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(tb.ForeignKeys))
+	assert.Equal(t, "fk_user_role_user", tb.ForeignKeys[0].Name)
+	assert.Equal(t, []string{"user_id"}, tb.ForeignKeys[0].Columns)
+	assert.Equal(t, "dbo", tb.ForeignKeys[0].ReferencedSchema)
+	assert.Equal(t, "user", tb.ForeignKeys[0].ReferencedTable)
+	assert.Equal(t, []string{"id"}, tb.ForeignKeys[0].ReferencedColumns)
+	assert.Equal(t, "SET NULL", tb.ForeignKeys[0].OnDelete)
+	assert.Equal(t, "NO ACTION", tb.ForeignKeys[0].OnUpdate)
+
+	_, err = db.Exec(`CREATE VIEW dbo.active_user AS SELECT id, username FROM dbo.[user] WHERE deleted_at IS NULL;`)
+	require.NoError(t, err)
+
+	tb, err = GenMssqlTable(ctx, db, schema, "active_user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "view", tb.Kind)
+	assert.Equal(t, 2, len(tb.Columns))
+	assert.Equal(t, "username", tb.Columns[1].Name)
+
+	_, err = db.Exec(`CREATE TABLE master.dbo.product (
+		price DECIMAL(10, 2)  NOT NULL,
+		qty   TINYINT         NOT NULL,
+		note  VARCHAR(MAX)    NULL,
+		total AS (price * qty) PERSISTED
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenMssqlTable(ctx, db, schema, "product", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "decimal", tb.Columns[0].BaseType)
+	assert.Equal(t, 10, *tb.Columns[0].NumericPrecision)
+	assert.Equal(t, 2, *tb.Columns[0].NumericScale)
+	assert.Equal(t, true, tb.Columns[1].IsUnsigned)
+	assert.Equal(t, "varchar", tb.Columns[2].BaseType)
+	assert.Nil(t, tb.Columns[2].MaxLength)
+	assert.Equal(t, "int16", tb.Columns[1].LogicalType) // tinyint is unsigned
+	assert.Equal(t, "text", tb.Columns[2].LogicalType)  // varchar(max)
+	assert.Equal(t, true, tb.Columns[3].IsGenerated)
+	assert.Equal(t, "stored", *tb.Columns[3].GenerationKind)
+	assert.Equal(t, "([price]*[qty])", *tb.Columns[3].GenerationExpression)
+	assert.Equal(t, true, tb.Columns[3].IsDatabaseGenerated)
+
+	_, err = db.Exec(`CREATE TYPE dbo.phone FROM NVARCHAR(20) NOT NULL;`)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE master.dbo.contact (phone dbo.phone, name NVARCHAR(50));`)
+	require.NoError(t, err)
+
+	tb, err = GenMssqlTable(ctx, db, schema, "contact", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "nvarchar", tb.Columns[0].BaseType)
+	assert.Equal(t, 20, *tb.Columns[0].MaxLength)
+	assert.Equal(t, "domain", tb.Columns[0].UserType.Kind)
+	assert.Equal(t, "dbo", tb.Columns[0].UserType.Schema)
+	assert.Equal(t, "phone", tb.Columns[0].UserType.Name)
+	assert.Equal(t, "nvarchar(20)", *tb.Columns[0].UserType.BaseType)
+	assert.Nil(t, tb.Columns[1].UserType)
+
+	_, err = db.Exec(`CREATE TABLE master.dbo.coupon (
+		id        INT CONSTRAINT pk_coupon PRIMARY KEY,
+		code      VARCHAR(32) NOT NULL CONSTRAINT uk_coupon_code UNIQUE,
+		amount    DECIMAL(10, 2) NOT NULL CONSTRAINT ck_coupon_amount CHECK (amount > 0),
+		min_spend DECIMAL(10, 2) NOT NULL,
+		CONSTRAINT ck_coupon_min_spend CHECK (min_spend >= amount)
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenMssqlTable(ctx, db, schema, "coupon", nil)
+	require.NoError(t, err)
+	assert.Len(t, tb.Constraints, 4)
+	assert.Equal(t, "pk_coupon", tb.Constraints[0].Name)
+	assert.Equal(t, "pk_coupon", *tb.Constraints[0].IndexName)
+	assert.Equal(t, "uk_coupon_code", tb.Constraints[1].Name)
+	assert.Equal(t, "unique", tb.Constraints[1].Type)
+	assert.Equal(t, []string{"code"}, tb.Constraints[1].Columns)
+	assert.Equal(t, "ck_coupon_amount", tb.Constraints[2].Name)
+	assert.Equal(t, []string{"amount"}, tb.Constraints[2].Columns)
+	assert.Equal(t, "([amount]>(0))", *tb.Constraints[2].CheckExpression)
+	assert.Equal(t, "ck_coupon_min_spend", tb.Constraints[3].Name)
+	assert.Equal(t, []string{"min_spend", "amount"}, tb.Constraints[3].Columns)
 }
@@ -6,43 +6,99 @@ import (
 	"errors"
 	"slices"
 	"sort"
+	"strings"
 
 	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
 )
 
-// GenMySQLTable generates a MySQL table and fills the Table structure.
-func GenMySQLTable(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) (*model.Table, error) {
-	t, err := getMySQLTableInfo(ctx, db, schema, table)
-	if err != nil {
-		return nil, err
+// MySQLIntrospector is the Introspector for MySQL.
+type MySQLIntrospector struct{}
+
+// DefaultSchema returns the database name of the DSN, MySQL uses databases as schemas.
+func (MySQLIntrospector) DefaultSchema(u *dburl.URL) string {
+	if u == nil {
+		return ""
 	}
-	if t == nil {
-		return nil, nil
+	arr := strings.Split(u.Path, "/")
+	if len(arr) > 1 {
+		return arr[1] // database name as schema in MySQL
 	}
+	return ""
+}
 
-	columns, err := getMySQLColumnsInfo(ctx, db, schema, table, ignoreColumns)
-	if err != nil {
-		return nil, err
-	}
-	t.Columns = columns
+func (MySQLIntrospector) ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	return ListMySQLTables(ctx, db, schema)
+}
 
-	indexes, err := getMySQLIndexesInfo(ctx, db, schema, table)
+func (MySQLIntrospector) GetTable(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, error) {
+	return getMySQLTableInfo(ctx, db, schema, table)
+}
+
+func (MySQLIntrospector) GetColumns(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	return getMySQLColumnsInfo(ctx, db, schema, table, ignoreColumns)
+}
+
+func (MySQLIntrospector) GetIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Index, error) {
+	return getMySQLIndexesInfo(ctx, db, schema, table)
+}
+
+func (MySQLIntrospector) GetForeignKeys(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	return getMySQLForeignKeysInfo(ctx, db, schema, table)
+}
+
+func (MySQLIntrospector) GetConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Constraint, error) {
+	return getMySQLConstraintsInfo(ctx, db, schema, table)
+}
+
+// GenMySQLTable generates a MySQL table and fills the Table structure.
+func GenMySQLTable(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) (*model.Table, error) {
+	return GenTable(ctx, MySQLIntrospector{}, db, schema, table, ignoreColumns)
+}
+
+// ListMySQLTables lists the names of all tables and views in the given MySQL schema.
+func ListMySQLTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const tablesSql = `
+		select table_name
+		from information_schema.tables
+		where table_schema = ? and table_type in ('BASE TABLE', 'VIEW')
+		order by table_name;
+	`
+	rows, err := db.QueryContext(ctx, tablesSql, schema)
 	if err != nil {
 		return nil, err
 	}
-	t.Indexes = indexes
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
 
-	return t, nil
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
 }
 
 func getMySQLTableInfo(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, error) {
 	const tableSql = `
-		select table_schema, table_name, table_comment
+		select table_schema, table_name,
+			   case when table_type = 'VIEW' then null else table_comment end as table_comment,
+			   case
+				   when table_type = 'VIEW' then 'view'
+				   when create_options like '%partitioned%' then 'partitioned_table'
+				   else 'table' end as table_kind
 		from information_schema.tables
 		where table_schema = ? and table_name = ?;
 	`
 	var t model.Table
-	err := db.QueryRowContext(ctx, tableSql, schema, table).Scan(&t.Schema, &t.Name, &t.Comment)
+	err := db.QueryRowContext(ctx, tableSql, schema, table).Scan(&t.Schema, &t.Name, &t.Comment, &t.Kind)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -52,12 +108,54 @@ func getMySQLTableInfo(ctx context.Context, db *sql.DB, schema, table string) (*
 	return &t, nil
 }
 
+// mysqlLogicalTypes maps MySQL specific base types to logical types, see fillLogicalType.
+var mysqlLogicalTypes = map[string]string{
+	"mediumint":  model.LogicalTypeInt32,
+	"float":      model.LogicalTypeFloat32,
+	"year":       model.LogicalTypeInt16,
+	"bit":        model.LogicalTypeBinary,
+	"tinytext":   model.LogicalTypeText,
+	"mediumtext": model.LogicalTypeText,
+	"longtext":   model.LogicalTypeText,
+	"tinyblob":   model.LogicalTypeBinary,
+	"mediumblob": model.LogicalTypeBinary,
+	"longblob":   model.LogicalTypeBinary,
+	"set":        model.LogicalTypeString,
+}
+
+// FillLogicalType implements LogicalTypeFiller.
+func (MySQLIntrospector) FillLogicalType(col *model.Column) {
+	fillMySQLLogicalType(col)
+}
+
+func fillMySQLLogicalType(col *model.Column) {
+	fillLogicalType(col, mysqlLogicalTypes)
+	// tinyint(1) and bit(1) are the conventional MySQL booleans
+	if strings.EqualFold(col.Type, "tinyint(1)") || strings.EqualFold(col.Type, "bit(1)") {
+		col.LogicalType = model.LogicalTypeBool
+	}
+}
+
 func getMySQLColumnsInfo(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
 	const columnsSql = `
 		select ordinal_position, column_name, column_type,
 			   is_nullable = 'YES' as is_nullable,
 			   column_default, column_key = 'PRI' as is_primary_key,
-			   column_comment
+			   column_comment,
+			   data_type,
+			   case
+				   when data_type in ('char', 'varchar', 'binary', 'varbinary') then character_maximum_length
+				   when data_type = 'bit' then numeric_precision end as max_length,
+			   case when data_type in ('decimal', 'numeric') then numeric_precision end as numeric_precision,
+			   case when data_type in ('decimal', 'numeric') then numeric_scale end as numeric_scale,
+			   column_type like '%unsigned%' as is_unsigned,
+			   character_set_name, collation_name,
+			   extra like '%auto_increment%' as is_auto_increment,
+			   extra like '%VIRTUAL GENERATED%' or extra like '%STORED GENERATED%' as is_generated,
+			   nullif(generation_expression, '') as generation_expression,
+			   case
+				   when extra like '%VIRTUAL GENERATED%' then 'virtual'
+				   when extra like '%STORED GENERATED%' then 'stored' end as generation_kind
 		from information_schema.columns
 		where table_schema = ? and table_name = ?
 		order by ordinal_position;
@@ -76,9 +174,16 @@ func getMySQLColumnsInfo(ctx context.Context, db *sql.DB, schema, table string,
 	var columns []*model.Column
 	for rows.Next() {
 		var col model.Column
-		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &col.Comment); err != nil {
+		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &col.Comment,
+			&col.BaseType, &col.MaxLength, &col.NumericPrecision, &col.NumericScale, &col.IsUnsigned, &col.Charset, &col.Collation,
+			&col.IsAutoIncrement, &col.IsGenerated, &col.GenerationExpression, &col.GenerationKind); err != nil {
 			return nil, err
 		}
+		fillValueGeneration(&col)
+		if col.BaseType == "enum" || col.BaseType == "set" {
+			col.EnumValues = parseEnumValues(col.Type)
+		}
+		fillMySQLLogicalType(&col)
 		if !slices.Contains(ignoreColumns, col.Name) {
 			columns = append(columns, &col)
 		}
@@ -148,3 +253,217 @@ func getMySQLIndexesInfo(ctx context.Context, db *sql.DB, schema, table string)
 
 	return indexes, nil
 }
+
+func getMySQLForeignKeysInfo(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	const foreignKeysSql = `
+		select kcu.constraint_name, kcu.column_name,
+			   kcu.referenced_table_schema, kcu.referenced_table_name, kcu.referenced_column_name,
+			   rc.delete_rule, rc.update_rule
+		from information_schema.key_column_usage kcu
+				 join information_schema.referential_constraints rc
+					  on rc.constraint_schema = kcu.constraint_schema
+						  and rc.constraint_name = kcu.constraint_name
+						  and rc.table_name = kcu.table_name
+		where kcu.table_schema = ? and kcu.table_name = ?
+		  and kcu.referenced_table_name is not null
+		order by kcu.constraint_name, kcu.ordinal_position;
+	`
+	rows, err := db.QueryContext(ctx, foreignKeysSql, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var foreignKeys []*model.ForeignKey
+	for rows.Next() {
+		var name, column, refSchema, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&name, &column, &refSchema, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != name {
+			foreignKeys = append(foreignKeys, &model.ForeignKey{
+				Name:             name,
+				Schema:           schema,
+				Table:            table,
+				ReferencedSchema: refSchema,
+				ReferencedTable:  refTable,
+				OnDelete:         onDelete,
+				OnUpdate:         onUpdate,
+			})
+		}
+
+		fk := foreignKeys[len(foreignKeys)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+	}
+	return foreignKeys, nil
+}
+
+func getMySQLConstraintsInfo(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Constraint, error) {
+	const constraintsSql = `
+		select tc.constraint_name,
+			   case tc.constraint_type
+				   when 'PRIMARY KEY' then 'primary_key'
+				   when 'UNIQUE' then 'unique'
+				   else 'check' end as constraint_type,
+			   kcu.column_name,
+			   cc.check_clause
+		from information_schema.table_constraints tc
+				 left join information_schema.key_column_usage kcu
+						   on kcu.constraint_schema = tc.constraint_schema
+							   and kcu.table_name = tc.table_name
+							   and kcu.constraint_name = tc.constraint_name
+				 left join information_schema.check_constraints cc
+						   on cc.constraint_schema = tc.constraint_schema
+							   and cc.constraint_name = tc.constraint_name
+		where tc.table_schema = ? and tc.table_name = ?
+		  and tc.constraint_type in ('PRIMARY KEY', 'UNIQUE', 'CHECK')
+		order by tc.constraint_name, kcu.ordinal_position;
+	`
+	rows, err := db.QueryContext(ctx, constraintsSql, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	// MySQL does not record the columns of check constraints, they are inferred from the expression later
+	var constraints []*model.Constraint
+	for rows.Next() {
+		var name, constraintType string
+		var column, checkClause *string
+		if err := rows.Scan(&name, &constraintType, &column, &checkClause); err != nil {
+			return nil, err
+		}
+
+		if len(constraints) == 0 || constraints[len(constraints)-1].Name != name {
+			constraints = append(constraints, &model.Constraint{
+				Name:            name,
+				Type:            constraintType,
+				CheckExpression: checkClause,
+			})
+		}
+		if column != nil {
+			c := constraints[len(constraints)-1]
+			c.Columns = append(c.Columns, *column)
+		}
+	}
+	return constraints, nil
+}
+
+func (MySQLIntrospector) ListRoutines(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const routinesSql = `
+		select distinct routine_name
+		from information_schema.routines
+		where routine_schema = ?
+		order by routine_name;
+	`
+	rows, err := db.QueryContext(ctx, routinesSql, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var routines []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		routines = append(routines, name)
+	}
+	return routines, nil
+}
+
+func (MySQLIntrospector) GetRoutines(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Routine, error) {
+	return getMySQLRoutinesInfo(ctx, db, schema, name)
+}
+
+func getMySQLRoutinesInfo(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Routine, error) {
+	const routinesSql = `
+		select routine_schema, routine_name, specific_name, lower(routine_type) as kind,
+			   case when routine_type = 'FUNCTION' then dtd_identifier end as return_type,
+			   nullif(routine_comment, '') as routine_comment
+		from information_schema.routines
+		where routine_schema = ? and routine_name = ?
+		order by routine_type desc;
+	`
+	rows, err := db.QueryContext(ctx, routinesSql, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var routines []*model.Routine
+	for rows.Next() {
+		var r model.Routine
+		if err := rows.Scan(&r.Schema, &r.Name, &r.SpecificName, &r.Kind, &r.ReturnType, &r.Comment); err != nil {
+			return nil, err
+		}
+		routines = append(routines, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, r := range routines {
+		params, err := getMySQLParametersInfo(ctx, db, schema, r)
+		if err != nil {
+			return nil, err
+		}
+		r.Parameters = params
+	}
+	return routines, nil
+}
+
+func getMySQLParametersInfo(ctx context.Context, db *sql.DB, schema string, routine *model.Routine) ([]*model.RoutineParameter, error) {
+	// ordinal_position 0 is the return value of a function
+	const parametersSql = `
+		select ordinal_position, coalesce(parameter_name, ''), lower(coalesce(parameter_mode, 'IN')), dtd_identifier, data_type
+		from information_schema.parameters
+		where specific_schema = ? and specific_name = ? and routine_type = upper(?) and ordinal_position > 0
+		order by ordinal_position;
+	`
+	rows, err := db.QueryContext(ctx, parametersSql, schema, routine.SpecificName, routine.Kind)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var params []*model.RoutineParameter
+	for rows.Next() {
+		var p model.RoutineParameter
+		var baseType string
+		if err := rows.Scan(&p.Ordinal, &p.Name, &p.Mode, &p.Type, &baseType); err != nil {
+			return nil, err
+		}
+		fillParameterLogicalType(&p, baseType, "mysql")
+		params = append(params, &p)
+	}
+	return params, nil
+}
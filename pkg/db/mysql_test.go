@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"github.com/DanielLiu1123/gencoder/pkg/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/mysql"
@@ -70,11 +71,21 @@ func TestGenMySQLTable(t *testing.T) {
 	assert.NotNil(t, tb)
 	assert.Equal(t, "testdb", tb.Schema)
 	assert.Equal(t, "user", tb.Name)
+	assert.Equal(t, "table", tb.Kind)
 	assert.Equal(t, "User account information", *tb.Comment)
 
 	assert.Equal(t, 9, len(tb.Columns))
 	assert.Equal(t, true, tb.Columns[0].IsPrimaryKey)
 	assert.Equal(t, false, tb.Columns[1].IsPrimaryKey)
+	assert.Equal(t, "varchar(64)", tb.Columns[1].Type)
+	assert.Equal(t, "varchar", tb.Columns[1].BaseType)
+	assert.Equal(t, 64, *tb.Columns[1].MaxLength)
+	assert.Equal(t, "utf8mb4", *tb.Columns[1].Charset)
+	assert.NotNil(t, tb.Columns[1].Collation)
+	assert.Nil(t, tb.Columns[0].Charset)
+	assert.Equal(t, true, tb.Columns[0].IsAutoIncrement)
+	assert.Equal(t, true, tb.Columns[0].IsDatabaseGenerated)
+	assert.Equal(t, false, tb.Columns[1].IsDatabaseGenerated)
 
 	assert.Equal(t, 5, len(tb.Indexes))
 	assert.Equal(t, "PRIMARY", tb.Indexes[0].Name)   // Primary key
@@ -86,4 +97,157 @@ func TestGenMySQLTable(t *testing.T) {
 	assert.Equal(t, "idx_status_created", tb.Indexes[4].Name)
 	assert.Equal(t, "status", tb.Indexes[4].Columns[0].Name)
 	assert.Equal(t, "created_at", tb.Indexes[4].Columns[1].Name)
+
+	tables, err := ListMySQLTables(context.Background(), db, schema)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, tables)
+
+	_, err = db.Exec(`CREATE TABLE testdb.user_role (
+		user_id INT NOT NULL,
+		role    VARCHAR(32) NOT NULL,
+		CONSTRAINT fk_user_role_user FOREIGN KEY (user_id) REFERENCES testdb.user (id) ON DELETE CASCADE
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenMySQLTable(context.Background(), db, schema, "user_role", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(tb.ForeignKeys))
+	assert.Equal(t, "fk_user_role_user", tb.ForeignKeys[0].Name)
+	assert.Equal(t, []string{"user_id"}, tb.ForeignKeys[0].Columns)
+	assert.Equal(t, "testdb", tb.ForeignKeys[0].ReferencedSchema)
+	assert.Equal(t, "user", tb.ForeignKeys[0].ReferencedTable)
+	assert.Equal(t, []string{"id"}, tb.ForeignKeys[0].ReferencedColumns)
+	assert.Equal(t, "CASCADE", tb.ForeignKeys[0].OnDelete)
+	assert.Equal(t, false, tb.ForeignKeys[0].IsDeferrable)
+
+	_, err = db.Exec(`CREATE VIEW testdb.active_user AS SELECT id, username FROM testdb.user WHERE deleted_at IS NULL;`)
+	require.NoError(t, err)
+
+	tb, err = GenMySQLTable(context.Background(), db, schema, "active_user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "view", tb.Kind)
+	assert.Nil(t, tb.Comment)
+	assert.Equal(t, 2, len(tb.Columns))
+	assert.Equal(t, "username", tb.Columns[1].Name)
+
+	_, err = db.Exec(`CREATE TABLE testdb.product (
+		price DECIMAL(10, 2) NOT NULL,
+		stock INT UNSIGNED NOT NULL,
+		total DECIMAL(12, 2) AS (price * stock) VIRTUAL,
+		note TEXT,
+		image BLOB,
+		flags BIT(8)
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenMySQLTable(context.Background(), db, schema, "product", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "decimal", tb.Columns[0].BaseType)
+	assert.Equal(t, 10, *tb.Columns[0].NumericPrecision)
+	assert.Equal(t, 2, *tb.Columns[0].NumericScale)
+	assert.Equal(t, false, tb.Columns[0].IsUnsigned)
+	assert.Equal(t, "int", tb.Columns[1].BaseType)
+	assert.Nil(t, tb.Columns[1].NumericPrecision)
+	assert.Equal(t, true, tb.Columns[1].IsUnsigned)
+	assert.Equal(t, "decimal", tb.Columns[0].LogicalType)
+	assert.Equal(t, "int64", tb.Columns[1].LogicalType) // widened for unsigned
+	assert.Equal(t, true, tb.Columns[2].IsGenerated)
+	assert.Equal(t, "virtual", *tb.Columns[2].GenerationKind)
+	assert.Contains(t, *tb.Columns[2].GenerationExpression, "`price` * `stock`")
+	assert.Equal(t, true, tb.Columns[2].IsDatabaseGenerated)
+	assert.Nil(t, tb.Columns[3].MaxLength) // text and blob types are unbounded
+	assert.Nil(t, tb.Columns[4].MaxLength)
+	assert.Equal(t, 8, *tb.Columns[5].MaxLength)
+
+	tb, err = GenMySQLTable(context.Background(), db, schema, "user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "status", tb.Columns[8].Name)
+	assert.Equal(t, []string{"active", "inactive", "suspended"}, tb.Columns[8].EnumValues)
+	assert.Nil(t, tb.Columns[8].UserType)
+	assert.Equal(t, "enum", tb.Columns[8].LogicalType)
+	assert.Equal(t, "int32", tb.Columns[0].LogicalType)
+	assert.Equal(t, "string", tb.Columns[1].LogicalType)
+	assert.Equal(t, "timestamp", tb.Columns[6].LogicalType)
+	assert.Nil(t, tb.Columns[1].EnumValues)
+
+	_, err = db.Exec(`CREATE TABLE testdb.coupon (
+		id INT PRIMARY KEY,
+		code VARCHAR(32) NOT NULL,
+		amount DECIMAL(10, 2) NOT NULL CHECK (amount > 0),
+		min_spend DECIMAL(10, 2) NOT NULL,
+		CONSTRAINT uk_coupon_code UNIQUE (code),
+		CONSTRAINT ck_coupon_min_spend CHECK (min_spend >= amount)
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenMySQLTable(context.Background(), db, schema, "coupon", nil)
+	require.NoError(t, err)
+	assert.Len(t, tb.Constraints, 4)
+	assert.Equal(t, "primary_key", tb.Constraints[0].Type)
+	assert.Equal(t, []string{"id"}, tb.Constraints[0].Columns)
+	assert.Equal(t, "PRIMARY", *tb.Constraints[0].IndexName)
+	assert.Equal(t, "uk_coupon_code", tb.Constraints[1].Name)
+	assert.Equal(t, []string{"code"}, tb.Constraints[1].Columns)
+	assert.Equal(t, "uk_coupon_code", *tb.Constraints[1].IndexName)
+	assert.Equal(t, "ck_coupon_min_spend", tb.Constraints[2].Name)
+	assert.Equal(t, "check", tb.Constraints[2].Type)
+	assert.Equal(t, []string{"min_spend", "amount"}, tb.Constraints[2].Columns)
+	assert.Contains(t, *tb.Constraints[2].CheckExpression, "`min_spend` >= `amount`")
+	assert.Equal(t, "coupon_chk_1", tb.Constraints[3].Name) // generated name
+	assert.Equal(t, []string{"amount"}, tb.Constraints[3].Columns)
+}
+
+func TestMySQLIntrospector_GetRoutines(t *testing.T) {
+	err := exec.Command("docker", "info").Run()
+	if err != nil {
+		t.Skip("Docker not available, skipping MySQL tests")
+	}
+
+	ctx := context.Background()
+	mysqlContainer, err := mysql.Run(ctx,
+		"mysql:latest",
+		mysql.WithDatabase("testdb"),
+		mysql.WithUsername("root"),
+		mysql.WithPassword("root"),
+	)
+	require.NoError(t, err)
+	defer func() {
+		if err := mysqlContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	}()
+
+	host, err := mysqlContainer.Host(ctx)
+	require.NoError(t, err)
+	port, err := mysqlContainer.MappedPort(ctx, "3306")
+	require.NoError(t, err)
+
+	db, err := dburl.Open(fmt.Sprintf("mysql://root:root@%s:%s/testdb", host, port.Port()))
+	require.NoError(t, err)
+
+	_, err = db.Exec(`CREATE FUNCTION testdb.add_tax(price DECIMAL(10,2)) RETURNS DECIMAL(10,2) DETERMINISTIC COMMENT 'Adds the tax' RETURN price * 1.1`)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE PROCEDURE testdb.count_users(IN min_id INT, OUT total BIGINT) SELECT 1 INTO total`)
+	require.NoError(t, err)
+
+	names, err := MySQLIntrospector{}.ListRoutines(ctx, db, "testdb")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"add_tax", "count_users"}, names)
+
+	routines, err := MySQLIntrospector{}.GetRoutines(ctx, db, "testdb", "add_tax")
+	require.NoError(t, err)
+	require.Len(t, routines, 1)
+	assert.Equal(t, model.RoutineKindFunction, routines[0].Kind)
+	assert.Equal(t, "decimal(10,2)", *routines[0].ReturnType)
+	assert.Equal(t, "Adds the tax", *routines[0].Comment)
+	require.Len(t, routines[0].Parameters, 1)
+	assert.Equal(t, model.LogicalTypeDecimal, routines[0].Parameters[0].LogicalType)
+
+	routines, err = MySQLIntrospector{}.GetRoutines(ctx, db, "testdb", "count_users")
+	require.NoError(t, err)
+	assert.Equal(t, model.RoutineKindProcedure, routines[0].Kind)
+	assert.Nil(t, routines[0].ReturnType)
+	assert.Equal(t, []string{model.ParameterModeIn, model.ParameterModeOut},
+		[]string{routines[0].Parameters[0].Mode, routines[0].Parameters[1].Mode})
 }
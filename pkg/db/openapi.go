@@ -0,0 +1,346 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISchema is the subset of the OpenAPI 3.x schema object used to build tables.
+type openAPISchema struct {
+	Ref         string           `yaml:"$ref"`
+	Type        any              `yaml:"type"` // A string, or a list of strings in OpenAPI 3.1, e.g. [string, "null"]
+	Format      string           `yaml:"format"`
+	Title       string           `yaml:"title"`
+	Description string           `yaml:"description"`
+	Enum        []any            `yaml:"enum"`
+	Default     any              `yaml:"default"`
+	Nullable    bool             `yaml:"nullable"`
+	ReadOnly    bool             `yaml:"readOnly"`
+	MaxLength   *int             `yaml:"maxLength"`
+	Required    []string         `yaml:"required"`
+	Properties  openAPIProps     `yaml:"properties"`
+	Items       *openAPISchema   `yaml:"items"`
+	AllOf       []*openAPISchema `yaml:"allOf"`
+	PrimaryKey  bool             `yaml:"x-primary-key"`
+}
+
+type openAPIProp struct {
+	name   string
+	schema *openAPISchema
+}
+
+// openAPIProps keeps the declaration order of the properties, which becomes the column order.
+type openAPIProps []*openAPIProp
+
+func (p *openAPIProps) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("expected a mapping")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var s openAPISchema
+		if err := node.Content[i+1].Decode(&s); err != nil {
+			return err
+		}
+		*p = append(*p, &openAPIProp{name: node.Content[i].Value, schema: &s})
+	}
+	return nil
+}
+
+type openAPIDocument struct {
+	OpenAPI    string `yaml:"openapi"`
+	Components struct {
+		Schemas openAPIProps `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// typeName returns the type of the schema and whether null is allowed by an OpenAPI 3.1 type list.
+func (s *openAPISchema) typeName() (string, bool) {
+	switch t := s.Type.(type) {
+	case string:
+		return t, false
+	case []any:
+		name, nullable := "", false
+		for _, v := range t {
+			if v == "null" {
+				nullable = true
+			} else if name == "" {
+				name = fmt.Sprint(v)
+			}
+		}
+		return name, nullable
+	}
+	if len(s.Properties) > 0 {
+		return "object", false
+	}
+	return "", false
+}
+
+// ParseOpenAPI maps each object of components.schemas in an OpenAPI 3.x document (YAML or JSON) to a table:
+// properties become columns, required properties are not nullable, and a $ref to another object becomes a foreign key.
+// The property marked with x-primary-key (or else the one named id) is the primary key.
+func ParseOpenAPI(content []byte, schema string) ([]*model.Table, error) {
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, only 3.x is supported", doc.OpenAPI)
+	}
+
+	schemas := make(map[string]*openAPISchema, len(doc.Components.Schemas))
+	for _, s := range doc.Components.Schemas {
+		schemas[s.name] = s.schema
+	}
+	p := &openAPIParser{schemas: schemas}
+
+	var tables []*model.Table
+	for _, s := range doc.Components.Schemas {
+		props, required := p.flatten(s.schema)
+		if typ, _ := s.schema.typeName(); typ != "object" && len(props) == 0 {
+			continue // Enums and primitive aliases are used through the columns that reference them
+		}
+		tables = append(tables, p.table(schema, s.name, s.schema, props, required))
+	}
+
+	for _, t := range tables {
+		for _, fk := range t.ForeignKeys {
+			for _, ref := range tables {
+				if ref.Name != fk.ReferencedTable {
+					continue
+				}
+				for _, col := range ref.Columns {
+					if col.IsPrimaryKey {
+						fk.ReferencedColumns = append(fk.ReferencedColumns, col.Name)
+					}
+				}
+			}
+		}
+		// Objects without primary key cannot be referenced
+		t.ForeignKeys = slices.DeleteFunc(t.ForeignKeys, func(fk *model.ForeignKey) bool { return len(fk.ReferencedColumns) == 0 })
+	}
+	return tables, nil
+}
+
+type openAPIParser struct {
+	schemas map[string]*openAPISchema
+}
+
+// resolve follows a $ref to a schema of the document, it returns the name of the referenced schema.
+func (p *openAPIParser) resolve(s *openAPISchema) (*openAPISchema, string) {
+	if s == nil || s.Ref == "" {
+		return s, ""
+	}
+	name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	if target, ok := p.schemas[name]; ok {
+		return target, name
+	}
+	return &openAPISchema{}, name
+}
+
+// flatten returns the properties and required properties of the schema, merging the schemas of allOf.
+func (p *openAPIParser) flatten(s *openAPISchema) (openAPIProps, map[string]bool) {
+	required := make(map[string]bool)
+	var props openAPIProps
+	var visit func(s *openAPISchema, depth int)
+	visit = func(s *openAPISchema, depth int) {
+		s, _ = p.resolve(s)
+		if s == nil || depth > 32 {
+			return
+		}
+		for _, sub := range s.AllOf {
+			visit(sub, depth+1)
+		}
+		for _, prop := range s.Properties {
+			props = append(props, prop)
+		}
+		for _, r := range s.Required {
+			required[r] = true
+		}
+	}
+	visit(s, 0)
+	return props, required
+}
+
+func (p *openAPIParser) table(schema, name string, s *openAPISchema, props openAPIProps, required map[string]bool) *model.Table {
+	t := &model.Table{Name: name, Schema: schema, Kind: model.TableKindTable, Indexes: []*model.Index{}}
+	if comment := firstNonEmpty(s.Description, s.Title); comment != "" {
+		t.Comment = &comment
+	}
+
+	for _, prop := range props {
+		col, ref := p.column(prop, required[prop.name])
+		col.Ordinal = len(t.Columns) + 1
+		t.Columns = append(t.Columns, col)
+		if ref != "" && col.ArrayDimensions == 0 { // An array of objects is a one-to-many, not a foreign key of the column
+			t.ForeignKeys = append(t.ForeignKeys, &model.ForeignKey{
+				Name:             name + "_" + prop.name + "_fkey",
+				Schema:           schema,
+				Table:            name,
+				Columns:          []string{prop.name},
+				ReferencedSchema: schema,
+				ReferencedTable:  ref,
+				OnDelete:         "NO ACTION",
+				OnUpdate:         "NO ACTION",
+			})
+		}
+	}
+
+	pk := -1
+	for i, prop := range props {
+		if prop.schema.PrimaryKey {
+			pk = i
+		}
+	}
+	if pk < 0 {
+		for i, prop := range props {
+			if prop.name == "id" {
+				pk = i
+			}
+		}
+	}
+	if pk >= 0 {
+		col := t.Columns[pk]
+		col.IsPrimaryKey = true
+		col.IsNullable = false
+		t.Constraints = append(t.Constraints, &model.Constraint{Name: name + "_pkey", Type: model.ConstraintTypePrimaryKey, Columns: []string{col.Name}})
+	}
+	return t
+}
+
+// column maps a property to a column, it returns the name of the referenced object schema for relations.
+func (p *openAPIParser) column(prop *openAPIProp, required bool) (*model.Column, string) {
+	s := prop.schema
+	col := &model.Column{Name: prop.name, IsDatabaseGenerated: s.ReadOnly}
+
+	target, refName := p.resolve(s)
+	if len(s.AllOf) == 1 { // A $ref with siblings (description, nullable) is wrapped in allOf in OpenAPI 3.0
+		target, refName = p.resolve(s.AllOf[0])
+	}
+	typ, nullableType := target.typeName()
+	col.IsNullable = !required || s.Nullable || target.Nullable || nullableType
+	if comment := firstNonEmpty(s.Description, target.Description, target.Title); comment != "" {
+		col.Comment = &comment
+	}
+	if s.Default != nil {
+		col.DefaultValue = defaultText(s.Default)
+	}
+
+	ref := ""
+	element := target
+	for typ == "array" {
+		col.ArrayDimensions++
+		element, refName = p.resolve(element.Items)
+		if element == nil {
+			element = &openAPISchema{}
+		}
+		typ, _ = element.typeName()
+	}
+
+	base, logicalType := openAPIBaseType(typ, element.Format)
+	if len(element.Enum) > 0 {
+		for _, v := range element.Enum {
+			if v != nil {
+				col.EnumValues = append(col.EnumValues, fmt.Sprint(v))
+			}
+		}
+		logicalType = model.LogicalTypeEnum
+		if refName != "" {
+			col.UserType = &model.UserType{Name: refName, Kind: model.UserTypeKindEnum, EnumValues: col.EnumValues}
+		}
+	}
+	if typ == "object" && refName != "" {
+		base = refName
+		ref = refName
+	}
+	if element.MaxLength != nil {
+		col.MaxLength = element.MaxLength
+		if logicalType == model.LogicalTypeText {
+			logicalType = model.LogicalTypeString
+		}
+	}
+
+	col.BaseType = base
+	col.Type = base + strings.Repeat("[]", col.ArrayDimensions)
+	col.LogicalType = logicalType
+	if col.ArrayDimensions > 0 {
+		col.ElementLogicalType = &logicalType
+		col.LogicalType = model.LogicalTypeArray
+	}
+	return col, ref
+}
+
+// openAPIBaseType returns the base type (the format if any) and the logical type of an OpenAPI type.
+func openAPIBaseType(typ, format string) (string, string) {
+	base := firstNonEmpty(format, typ)
+	switch typ {
+	case "string":
+		switch format {
+		case "date":
+			return base, model.LogicalTypeDate
+		case "date-time":
+			return base, model.LogicalTypeTimestamptz
+		case "time":
+			return base, model.LogicalTypeTime
+		case "uuid":
+			return base, model.LogicalTypeUUID
+		case "byte", "binary":
+			return base, model.LogicalTypeBinary
+		case "decimal":
+			return base, model.LogicalTypeDecimal
+		}
+		return base, model.LogicalTypeText
+	case "integer":
+		switch format {
+		case "int32":
+			return base, model.LogicalTypeInt32
+		case "int16":
+			return base, model.LogicalTypeInt16
+		case "int8":
+			return base, model.LogicalTypeInt8
+		}
+		return base, model.LogicalTypeInt64
+	case "number":
+		switch format {
+		case "float":
+			return base, model.LogicalTypeFloat32
+		case "decimal":
+			return base, model.LogicalTypeDecimal
+		}
+		return base, model.LogicalTypeFloat64
+	case "boolean":
+		return base, model.LogicalTypeBool
+	case "object":
+		return base, model.LogicalTypeJSON
+	}
+	return base, model.LogicalTypeOther
+}
+
+// defaultText returns the text of a scalar default, other values are encoded as JSON.
+func defaultText(v any) *string {
+	switch v.(type) {
+	case map[string]any, []any:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		text := string(b)
+		return &text
+	}
+	text := fmt.Sprint(v)
+	return &text
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOpenAPI(t *testing.T) {
+	doc := `
+openapi: 3.0.3
+info:
+  title: Blog
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Status:
+      type: string
+      enum: [draft, published]
+    User:
+      type: object
+      description: User account
+      required: [id, username]
+      properties:
+        id:
+          type: integer
+          format: int64
+          readOnly: true
+        username:
+          type: string
+          maxLength: 64
+          description: Login name
+        createdAt:
+          type: string
+          format: date-time
+    Post:
+      type: object
+      required: [slug, author]
+      properties:
+        slug:
+          type: string
+          x-primary-key: true
+        author:
+          $ref: '#/components/schemas/User'
+        status:
+          $ref: '#/components/schemas/Status'
+        rating:
+          type: number
+          format: float
+          nullable: true
+          default: 0.5
+        tags:
+          type: array
+          items:
+            type: string
+    Comment:
+      allOf:
+        - $ref: '#/components/schemas/Post'
+        - type: object
+          properties:
+            body:
+              type: string
+`
+	tables, err := ParseOpenAPI([]byte(doc), "blog")
+	require.NoError(t, err)
+	require.Len(t, tables, 3)
+
+	user, post, comment := tables[0], tables[1], tables[2]
+	assert.Equal(t, "User", user.Name)
+	assert.Equal(t, "blog", user.Schema)
+	assert.Equal(t, "User account", *user.Comment)
+	require.Len(t, user.Columns, 3)
+
+	id := user.Columns[0]
+	assert.True(t, id.IsPrimaryKey)
+	assert.False(t, id.IsNullable)
+	assert.True(t, id.IsDatabaseGenerated)
+	assert.Equal(t, "int64", id.Type)
+	assert.Equal(t, model.LogicalTypeInt64, id.LogicalType)
+	assert.Equal(t, []*model.Constraint{{Name: "User_pkey", Type: model.ConstraintTypePrimaryKey, Columns: []string{"id"}}}, user.Constraints)
+
+	username := user.Columns[1]
+	assert.False(t, username.IsNullable)
+	assert.Equal(t, 64, *username.MaxLength)
+	assert.Equal(t, "Login name", *username.Comment)
+	assert.Equal(t, model.LogicalTypeString, username.LogicalType)
+
+	createdAt := user.Columns[2]
+	assert.True(t, createdAt.IsNullable)
+	assert.Equal(t, 3, createdAt.Ordinal)
+	assert.Equal(t, "date-time", createdAt.Type)
+	assert.Equal(t, model.LogicalTypeTimestamptz, createdAt.LogicalType)
+
+	require.Len(t, post.Columns, 5)
+	assert.True(t, post.Columns[0].IsPrimaryKey)
+	author := post.Columns[1]
+	assert.Equal(t, "User", author.Type)
+	assert.False(t, author.IsNullable)
+	require.Len(t, post.ForeignKeys, 1)
+	assert.Equal(t, &model.ForeignKey{
+		Name:              "Post_author_fkey",
+		Schema:            "blog",
+		Table:             "Post",
+		Columns:           []string{"author"},
+		ReferencedSchema:  "blog",
+		ReferencedTable:   "User",
+		ReferencedColumns: []string{"id"},
+		OnDelete:          "NO ACTION",
+		OnUpdate:          "NO ACTION",
+	}, post.ForeignKeys[0])
+
+	status := post.Columns[2]
+	assert.Equal(t, model.LogicalTypeEnum, status.LogicalType)
+	assert.Equal(t, []string{"draft", "published"}, status.EnumValues)
+	assert.Equal(t, "Status", status.UserType.Name)
+
+	rating := post.Columns[3]
+	assert.True(t, rating.IsNullable)
+	assert.Equal(t, "0.5", *rating.DefaultValue)
+	assert.Equal(t, model.LogicalTypeFloat32, rating.LogicalType)
+
+	tags := post.Columns[4]
+	assert.Equal(t, "string[]", tags.Type)
+	assert.Equal(t, 1, tags.ArrayDimensions)
+	assert.Equal(t, model.LogicalTypeArray, tags.LogicalType)
+	assert.Equal(t, model.LogicalTypeText, *tags.ElementLogicalType)
+
+	assert.Equal(t, "Comment", comment.Name)
+	assert.Len(t, comment.Columns, 6)
+	assert.Equal(t, "body", comment.Columns[5].Name)
+	assert.True(t, comment.Columns[0].IsPrimaryKey)
+}
+
+func TestParseOpenAPI_whenVersionIsNot3_thenReturnError(t *testing.T) {
+	_, err := ParseOpenAPI([]byte("swagger: '2.0'\n"), "")
+	assert.Error(t, err)
+}
+
+func TestParseOpenAPI_when31TypeListHasNull_thenNullable(t *testing.T) {
+	doc := `
+openapi: 3.1.0
+components:
+  schemas:
+    Tag:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: [string, "null"]
+`
+	tables, err := ParseOpenAPI([]byte(doc), "")
+	require.NoError(t, err)
+	require.Len(t, tables, 1)
+	assert.True(t, tables[0].Columns[0].IsNullable)
+	assert.Equal(t, "string", tables[0].Columns[0].Type)
+	assert.Empty(t, tables[0].Constraints)
+}
+
+func TestParseOpenAPI_whenRefIsArrayOrHasNoPrimaryKey_thenNoForeignKey(t *testing.T) {
+	doc := `
+openapi: 3.0.3
+info:
+  title: Blog
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        id:
+          type: integer
+        posts:
+          type: array
+          items:
+            $ref: '#/components/schemas/Post'
+    Post:
+      type: object
+      properties:
+        id:
+          type: integer
+        author:
+          $ref: '#/components/schemas/User'
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: object
+      properties:
+        city:
+          type: string
+`
+	tables, err := ParseOpenAPI([]byte(doc), "blog")
+	require.NoError(t, err)
+	require.Len(t, tables, 3)
+
+	user, post := tables[0], tables[1]
+	assert.Equal(t, "Post[]", user.Columns[1].Type)
+	assert.Empty(t, user.ForeignKeys)
+	require.Len(t, post.ForeignKeys, 1)
+	assert.Equal(t, []string{"author"}, post.ForeignKeys[0].Columns)
+	assert.Equal(t, []string{"id"}, post.ForeignKeys[0].ReferencedColumns)
+}
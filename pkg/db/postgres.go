@@ -3,50 +3,103 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"slices"
 	"sort"
 
 	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
 )
 
+// PostgresIntrospector is the Introspector for PostgreSQL.
+type PostgresIntrospector struct{}
+
+// DefaultSchema returns public, the default schema in PostgreSQL.
+func (PostgresIntrospector) DefaultSchema(u *dburl.URL) string {
+	return "public"
+}
+
+func (PostgresIntrospector) ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	return ListPostgresTables(ctx, db, schema)
+}
+
+func (PostgresIntrospector) GetTable(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, error) {
+	return getPostgresTableInfo(ctx, db, schema, table)
+}
+
+func (PostgresIntrospector) GetColumns(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	return getPostgresColumnsInfo(ctx, db, schema, table, ignoreColumns)
+}
+
+func (PostgresIntrospector) GetIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Index, error) {
+	return getPostgresIndexesInfo(ctx, db, schema, table)
+}
+
+func (PostgresIntrospector) GetForeignKeys(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	return getPostgresForeignKeysInfo(ctx, db, schema, table)
+}
+
+func (PostgresIntrospector) GetConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Constraint, error) {
+	return getPostgresConstraintsInfo(ctx, db, schema, table)
+}
+
 // GenPostgresTable generates a PostgreSQL table and fills the Table structure.
-func GenPostgresTable(ctx context.Context, db *sql.DB, schema string, name string, ignoreColumns []string) (*model.Table, error) {
-	t, err := getPostgresTableInfo(ctx, db, schema, name)
-	if err != nil {
-		return nil, err
-	}
-	if t == nil {
-		return nil, nil
-	}
+func GenPostgresTable(ctx context.Context, db *sql.DB, schema, name string, ignoreColumns []string) (*model.Table, error) {
+	return GenTable(ctx, PostgresIntrospector{}, db, schema, name, ignoreColumns)
+}
 
-	columns, err := getPostgresColumnsInfo(ctx, db, schema, name, ignoreColumns)
+// ListPostgresTables lists the names of all tables, views, materialized views and foreign tables in the given PostgreSQL schema.
+func ListPostgresTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const tablesSql = `
+		SELECT c.relname AS table_name
+		FROM pg_class c
+				 JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+		  AND c.relkind IN ('r', 'v', 'm', 'f', 'p')
+		ORDER BY c.relname;
+	`
+	rows, err := db.QueryContext(ctx, tablesSql, schema)
 	if err != nil {
 		return nil, err
 	}
-	t.Columns = columns
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
 
-	indexes, err := getPostgresIndexesInfo(ctx, db, schema, name)
-	if err != nil {
-		return nil, err
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
 	}
-	t.Indexes = indexes
-
-	return t, nil
+	return tables, nil
 }
 
 func getPostgresTableInfo(ctx context.Context, db *sql.DB, schema, name string) (*model.Table, error) {
 	const tableSql = `
-		SELECT table_schema,
-			   table_name,
-			   obj_description(pg_class.oid) AS table_comment
-		FROM information_schema.tables
-		JOIN pg_class ON relname = table_name
-		WHERE table_schema = $1
-		  AND table_name = $2;
+		SELECT n.nspname                        AS table_schema,
+			   c.relname                        AS table_name,
+			   obj_description(c.oid, 'pg_class') AS table_comment,
+			   CASE c.relkind
+				   WHEN 'v' THEN 'view'
+				   WHEN 'm' THEN 'materialized_view'
+				   WHEN 'f' THEN 'foreign_table'
+				   WHEN 'p' THEN 'partitioned_table'
+				   ELSE 'table' END             AS table_kind
+		FROM pg_class c
+				 JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1
+		  AND c.relname = $2
+		  AND c.relkind IN ('r', 'v', 'm', 'f', 'p');
 	`
 	var t model.Table
-	err := db.QueryRowContext(ctx, tableSql, schema, name).Scan(&t.Schema, &t.Name, &t.Comment)
+	err := db.QueryRowContext(ctx, tableSql, schema, name).Scan(&t.Schema, &t.Name, &t.Comment, &t.Kind)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -56,18 +109,110 @@ func getPostgresTableInfo(ctx context.Context, db *sql.DB, schema, name string)
 	return &t, nil
 }
 
+// postgresLogicalTypes maps PostgreSQL specific base types to logical types, see fillLogicalType.
+var postgresLogicalTypes = map[string]string{
+	"time without time zone":      model.LogicalTypeTime,
+	"time with time zone":         model.LogicalTypeTime,
+	"timestamp without time zone": model.LogicalTypeTimestamp,
+	"timestamp with time zone":    model.LogicalTypeTimestamptz,
+	"jsonb":                       model.LogicalTypeJSON,
+	"bytea":                       model.LogicalTypeBinary,
+	"bit":                         model.LogicalTypeBinary,
+	"bit varying":                 model.LogicalTypeBinary,
+	"money":                       model.LogicalTypeDecimal,
+	"xml":                         model.LogicalTypeText,
+	"citext":                      model.LogicalTypeText,
+	"name":                        model.LogicalTypeString,
+}
+
+// FillLogicalType implements LogicalTypeFiller.
+func (PostgresIntrospector) FillLogicalType(col *model.Column) {
+	fillPostgresLogicalType(col)
+}
+
+func fillPostgresLogicalType(col *model.Column) {
+	fillLogicalType(col, postgresLogicalTypes)
+	if col.BaseType == "bit" && col.MaxLength != nil && *col.MaxLength == 1 {
+		col.LogicalType = model.LogicalTypeBool
+	}
+}
+
 func getPostgresColumnsInfo(ctx context.Context, db *sql.DB, schema, name string, ignoreColumns []string) ([]*model.Column, error) {
 	const columnsSql = `
 		SELECT a.attnum                             AS ordinal,
 			   a.attname                            AS column_name,
 			   format_type(a.atttypid, a.atttypmod) AS data_type,
 			   NOT a.attnotnull                     AS is_nullable,
-			   pg_get_expr(ad.adbin, ad.adrelid)    AS default_value,
+			   CASE
+				   WHEN a.attgenerated = '' THEN pg_get_expr(ad.adbin, ad.adrelid)
+				   END                              AS default_value,
 			   COALESCE(ct.contype = 'p', false)    AS is_primary,
-			   d.description                        AS comment
+			   d.description                        AS comment,
+			   format_type(bt.oid, NULL)            AS base_type,
+			   CASE
+				   WHEN m.typmod > 0 AND bt.typname IN ('varchar', 'bpchar') THEN m.typmod - 4
+				   WHEN m.typmod > 0 AND bt.typname IN ('bit', 'varbit') THEN m.typmod
+				   END                              AS max_length,
+			   CASE
+				   WHEN m.typmod > 0 AND bt.typname = 'numeric' THEN ((m.typmod - 4) >> 16) & 65535
+				   END                              AS numeric_precision,
+			   CASE
+				   WHEN m.typmod > 0 AND bt.typname = 'numeric' THEN (m.typmod - 4) & 65535
+				   END                              AS numeric_scale,
+			   false                                AS is_unsigned,
+			   CASE
+				   WHEN co.oid IS NOT NULL
+					   THEN (SELECT pg_encoding_to_char(encoding) FROM pg_database WHERE datname = current_database())
+				   END                              AS charset,
+			   co.collname                          AS collation,
+			   CASE
+				   WHEN t.typcategory = 'A' THEN GREATEST(a.attndims, 1)
+				   ELSE 0 END                       AS array_dimensions,
+			   a.attidentity <> ''                  AS is_identity,
+			   CASE a.attidentity
+				   WHEN 'a' THEN 'always'
+				   WHEN 'd' THEN 'by_default'
+				   END                              AS identity_generation,
+			   pg_get_serial_sequence(format('%I.%I', n.nspname, c.relname), a.attname) AS sequence_name,
+			   a.attgenerated <> ''                 AS is_generated,
+			   CASE
+				   WHEN a.attgenerated <> '' THEN pg_get_expr(ad.adbin, ad.adrelid)
+				   END                              AS generation_expression,
+			   CASE a.attgenerated
+				   WHEN 's' THEN 'stored'
+				   WHEN 'v' THEN 'virtual'
+				   END                              AS generation_kind,
+			   (SELECT json_agg(e.enumlabel ORDER BY e.enumsortorder)
+				FROM pg_enum e
+				WHERE e.enumtypid = bt.oid)::text   AS enum_values,
+			   CASE et.typtype
+				   WHEN 'e' THEN 'enum'
+				   WHEN 'd' THEN 'domain'
+				   WHEN 'c' THEN 'composite'
+				   END                              AS user_type_kind,
+			   etn.nspname                          AS user_type_schema,
+			   et.typname                           AS user_type_name,
+			   CASE
+				   WHEN et.typtype = 'd' THEN format_type(et.typbasetype, et.typtypmod)
+				   END                              AS domain_base_type,
+			   (SELECT json_agg(json_build_object('name', ca.attname, 'type', format_type(ca.atttypid, ca.atttypmod))
+								ORDER BY ca.attnum)
+				FROM pg_attribute ca
+				WHERE et.typtype = 'c'
+				  AND ca.attrelid = et.typrelid
+				  AND ca.attnum > 0
+				  AND NOT ca.attisdropped)::text    AS composite_attributes
 		FROM pg_attribute a
 				 JOIN pg_class c ON c.oid = a.attrelid
 				 JOIN pg_namespace n ON n.oid = c.relnamespace
+				 JOIN pg_type t ON t.oid = a.atttypid
+				 -- element type of arrays
+				 JOIN pg_type et ON et.oid = CASE WHEN t.typcategory = 'A' THEN t.typelem ELSE t.oid END
+				 JOIN pg_namespace etn ON etn.oid = et.typnamespace
+				 -- base type of domains
+				 JOIN pg_type bt ON bt.oid = CASE WHEN et.typtype = 'd' THEN et.typbasetype ELSE et.oid END
+				 CROSS JOIN LATERAL (SELECT CASE WHEN et.typtype = 'd' THEN et.typtypmod ELSE a.atttypmod END AS typmod) m
+				 LEFT JOIN pg_collation co ON co.oid = a.attcollation
 				 LEFT JOIN pg_constraint ct ON ct.conrelid = c.oid AND a.attnum = ANY (ct.conkey) AND ct.contype = 'p'
 				 LEFT JOIN pg_attrdef ad ON ad.adrelid = c.oid AND ad.adnum = a.attnum
 				 LEFT JOIN pg_description d ON d.objoid = c.oid AND d.objsubid = a.attnum
@@ -91,9 +236,35 @@ func getPostgresColumnsInfo(ctx context.Context, db *sql.DB, schema, name string
 	var columns []*model.Column
 	for rows.Next() {
 		var col model.Column
-		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &col.Comment); err != nil {
+		var enumValues, userTypeKind, domainBaseType, compositeAttributes *string
+		var userTypeSchema, userTypeName string
+		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &col.Comment,
+			&col.BaseType, &col.MaxLength, &col.NumericPrecision, &col.NumericScale, &col.IsUnsigned, &col.Charset, &col.Collation, &col.ArrayDimensions,
+			&col.IsIdentity, &col.IdentityGeneration, &col.SequenceName, &col.IsGenerated, &col.GenerationExpression, &col.GenerationKind,
+			&enumValues, &userTypeKind, &userTypeSchema, &userTypeName, &domainBaseType, &compositeAttributes); err != nil {
 			return nil, err
 		}
+		fillValueGeneration(&col)
+		if enumValues != nil {
+			if err := json.Unmarshal([]byte(*enumValues), &col.EnumValues); err != nil {
+				return nil, err
+			}
+		}
+		if userTypeKind != nil {
+			col.UserType = &model.UserType{
+				Schema:     userTypeSchema,
+				Name:       userTypeName,
+				Kind:       *userTypeKind,
+				BaseType:   domainBaseType,
+				EnumValues: col.EnumValues,
+			}
+			if compositeAttributes != nil {
+				if err := json.Unmarshal([]byte(*compositeAttributes), &col.UserType.Attributes); err != nil {
+					return nil, err
+				}
+			}
+		}
+		fillPostgresLogicalType(&col)
 		if !slices.Contains(ignoreColumns, col.Name) {
 			columns = append(columns, &col)
 		}
@@ -173,3 +344,273 @@ func getPostgresIndexesInfo(ctx context.Context, db *sql.DB, schema, name string
 
 	return indexes, nil
 }
+
+func getPostgresForeignKeysInfo(ctx context.Context, db *sql.DB, schema, name string) ([]*model.ForeignKey, error) {
+	const foreignKeysSql = `
+		SELECT con.conname                AS constraint_name,
+			   a.attname                  AS column_name,
+			   rn.nspname                 AS referenced_schema,
+			   rc.relname                 AS referenced_table,
+			   ra.attname                 AS referenced_column,
+			   CASE con.confdeltype
+				   WHEN 'r' THEN 'RESTRICT'
+				   WHEN 'c' THEN 'CASCADE'
+				   WHEN 'n' THEN 'SET NULL'
+				   WHEN 'd' THEN 'SET DEFAULT'
+				   ELSE 'NO ACTION' END   AS on_delete,
+			   CASE con.confupdtype
+				   WHEN 'r' THEN 'RESTRICT'
+				   WHEN 'c' THEN 'CASCADE'
+				   WHEN 'n' THEN 'SET NULL'
+				   WHEN 'd' THEN 'SET DEFAULT'
+				   ELSE 'NO ACTION' END   AS on_update,
+			   con.condeferrable          AS is_deferrable
+		FROM pg_constraint con
+				 JOIN pg_class c ON c.oid = con.conrelid
+				 JOIN pg_namespace n ON n.oid = c.relnamespace
+				 JOIN pg_class rc ON rc.oid = con.confrelid
+				 JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+				 CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ordinality)
+				 JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+				 JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE con.contype = 'f'
+		  AND n.nspname = $1
+		  AND c.relname = $2
+		ORDER BY con.conname, k.ordinality;
+	`
+	rows, err := db.QueryContext(ctx, foreignKeysSql, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var foreignKeys []*model.ForeignKey
+	for rows.Next() {
+		var constraintName, column, refSchema, refTable, refColumn, onDelete, onUpdate string
+		var isDeferrable bool
+		if err := rows.Scan(&constraintName, &column, &refSchema, &refTable, &refColumn, &onDelete, &onUpdate, &isDeferrable); err != nil {
+			return nil, err
+		}
+
+		if len(foreignKeys) == 0 || foreignKeys[len(foreignKeys)-1].Name != constraintName {
+			foreignKeys = append(foreignKeys, &model.ForeignKey{
+				Name:             constraintName,
+				Schema:           schema,
+				Table:            name,
+				ReferencedSchema: refSchema,
+				ReferencedTable:  refTable,
+				OnDelete:         onDelete,
+				OnUpdate:         onUpdate,
+				IsDeferrable:     isDeferrable,
+			})
+		}
+
+		fk := foreignKeys[len(foreignKeys)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+	}
+	return foreignKeys, nil
+}
+
+func getPostgresConstraintsInfo(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Constraint, error) {
+	const constraintsSql = `
+		SELECT con.conname                           AS constraint_name,
+			   CASE con.contype
+				   WHEN 'p' THEN 'primary_key'
+				   WHEN 'u' THEN 'unique'
+				   WHEN 'x' THEN 'exclusion'
+				   ELSE 'check' END                  AS constraint_type,
+			   COALESCE((SELECT json_agg(a.attname ORDER BY k.ord)
+						 FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+								  JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum)::text,
+						'[]')                        AS columns,
+			   CASE
+				   WHEN con.contype = 'c' THEN pg_get_expr(con.conbin, con.conrelid)
+				   END                               AS check_expression,
+			   ic.relname                            AS index_name
+		FROM pg_constraint con
+				 JOIN pg_class c ON c.oid = con.conrelid
+				 JOIN pg_namespace n ON n.oid = c.relnamespace
+				 LEFT JOIN pg_class ic ON ic.oid = con.conindid AND con.contype IN ('p', 'u', 'x')
+		WHERE n.nspname = $1
+		  AND c.relname = $2
+		  AND con.contype IN ('p', 'u', 'c', 'x')
+		ORDER BY con.conname;
+	`
+	rows, err := db.QueryContext(ctx, constraintsSql, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var constraints []*model.Constraint
+	for rows.Next() {
+		var c model.Constraint
+		var columns string
+		if err := rows.Scan(&c.Name, &c.Type, &columns, &c.CheckExpression, &c.IndexName); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(columns), &c.Columns); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, &c)
+	}
+	return constraints, nil
+}
+
+func (PostgresIntrospector) ListRoutines(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const routinesSql = `
+		SELECT DISTINCT p.proname
+		FROM pg_proc p
+				 JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = $1
+		  AND p.prokind IN ('f', 'p')
+		ORDER BY p.proname;
+	`
+	rows, err := db.QueryContext(ctx, routinesSql, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var routines []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		routines = append(routines, name)
+	}
+	return routines, nil
+}
+
+func (PostgresIntrospector) GetRoutines(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Routine, error) {
+	return getPostgresRoutinesInfo(ctx, db, schema, name)
+}
+
+func getPostgresRoutinesInfo(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Routine, error) {
+	const routinesSql = `
+		SELECT p.oid,
+			   n.nspname                          AS routine_schema,
+			   p.proname                          AS routine_name,
+			   p.proname || '_' || p.oid          AS specific_name,
+			   CASE p.prokind
+				   WHEN 'p' THEN 'procedure'
+				   ELSE 'function' END            AS kind,
+			   CASE
+				   WHEN p.prokind = 'f' THEN pg_get_function_result(p.oid)
+				   END                            AS return_type,
+			   obj_description(p.oid, 'pg_proc') AS routine_comment
+		FROM pg_proc p
+				 JOIN pg_namespace n ON n.oid = p.pronamespace
+		WHERE n.nspname = $1
+		  AND p.proname = $2
+		  AND p.prokind IN ('f', 'p')
+		ORDER BY p.oid;
+	`
+	rows, err := db.QueryContext(ctx, routinesSql, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var oids []int64
+	var routines []*model.Routine
+	for rows.Next() {
+		var oid int64
+		var r model.Routine
+		if err := rows.Scan(&oid, &r.Schema, &r.Name, &r.SpecificName, &r.Kind, &r.ReturnType, &r.Comment); err != nil {
+			return nil, err
+		}
+		oids = append(oids, oid)
+		routines = append(routines, &r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, r := range routines {
+		if err := fillPostgresParametersInfo(ctx, db, oids[i], r); err != nil {
+			return nil, err
+		}
+	}
+	return routines, nil
+}
+
+// fillPostgresParametersInfo sets the parameters of the routine, the TABLE columns of a function become its result columns.
+func fillPostgresParametersInfo(ctx context.Context, db *sql.DB, oid int64, routine *model.Routine) error {
+	const parametersSql = `
+		SELECT a.ordinality                                   AS ordinal,
+			   COALESCE(p.proargnames[a.ordinality], '')      AS parameter_name,
+			   COALESCE(p.proargmodes[a.ordinality], 'i')::text AS parameter_mode,
+			   format_type(a.type, NULL)                      AS data_type,
+			   ip.parameter_default
+		FROM pg_proc p
+				 JOIN pg_namespace n ON n.oid = p.pronamespace
+				 CROSS JOIN LATERAL unnest(COALESCE(p.proallargtypes, p.proargtypes::oid[])) WITH ORDINALITY AS a(type, ordinality)
+				 LEFT JOIN information_schema.parameters ip
+						   ON ip.specific_schema = n.nspname
+							   AND ip.specific_name = p.proname || '_' || p.oid
+							   AND ip.ordinal_position = a.ordinality
+		WHERE p.oid = $1
+		ORDER BY a.ordinality;
+	`
+	rows, err := db.QueryContext(ctx, parametersSql, oid)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var p model.RoutineParameter
+		var mode string
+		if err := rows.Scan(&p.Ordinal, &p.Name, &mode, &p.Type, &p.DefaultValue); err != nil {
+			return err
+		}
+		if mode == "t" {
+			col := typeColumn(p.Type, p.Type)
+			col.Ordinal = len(routine.ResultColumns) + 1
+			col.Name = p.Name
+			fillPostgresLogicalType(col)
+			routine.ResultColumns = append(routine.ResultColumns, col)
+			continue
+		}
+		p.Mode = postgresParameterModes[mode]
+		fillParameterLogicalType(&p, p.Type, "postgres")
+		routine.Parameters = append(routine.Parameters, &p)
+	}
+	return rows.Err()
+}
+
+// postgresParameterModes maps pg_proc.proargmodes to parameter modes.
+var postgresParameterModes = map[string]string{
+	"i": model.ParameterModeIn,
+	"o": model.ParameterModeOut,
+	"b": model.ParameterModeInOut,
+	"v": model.ParameterModeVariadic,
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"github.com/DanielLiu1123/gencoder/pkg/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -79,6 +80,7 @@ func TestGenPostgresTable(t *testing.T) {
 	assert.NotNil(t, tb)
 	assert.Equal(t, "public", tb.Schema)
 	assert.Equal(t, "user", tb.Name)
+	assert.Equal(t, "table", tb.Kind)
 	assert.Equal(t, "User account information", *tb.Comment)
 
 	assert.Equal(t, 9, len(tb.Columns))
@@ -87,6 +89,17 @@ func TestGenPostgresTable(t *testing.T) {
 	assert.Equal(t, false, tb.Columns[1].IsPrimaryKey)
 	assert.Equal(t, "first_name", tb.Columns[4].Name)
 	assert.Equal(t, true, tb.Columns[4].IsNullable)
+	assert.Equal(t, "character varying(64)", tb.Columns[1].Type)
+	assert.Equal(t, "character varying", tb.Columns[1].BaseType)
+	assert.Equal(t, 64, *tb.Columns[1].MaxLength)
+	assert.Equal(t, "default", *tb.Columns[1].Collation)
+	assert.Equal(t, "UTF8", *tb.Columns[1].Charset)
+	assert.Nil(t, tb.Columns[0].MaxLength)
+	assert.Nil(t, tb.Columns[0].Collation)
+	assert.Equal(t, "public.user_id_seq", *tb.Columns[0].SequenceName)
+	assert.Equal(t, false, tb.Columns[0].IsIdentity)
+	assert.Equal(t, true, tb.Columns[0].IsDatabaseGenerated)
+	assert.Equal(t, false, tb.Columns[1].IsDatabaseGenerated)
 
 	assert.Equal(t, 5, len(tb.Indexes))
 	assert.Equal(t, "user_pkey", tb.Indexes[0].Name)    // Primary key
@@ -98,4 +111,210 @@ func TestGenPostgresTable(t *testing.T) {
 	assert.Equal(t, "idx_status_created", tb.Indexes[4].Name)
 	assert.Equal(t, "status", tb.Indexes[4].Columns[0].Name)
 	assert.Equal(t, "created_at", tb.Indexes[4].Columns[1].Name)
+
+	tables, err := ListPostgresTables(context.Background(), db, schema)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, tables)
+
+	_, err = db.Exec(`CREATE TABLE testdb.public.user_role (
+		user_id INT NOT NULL,
+		role    VARCHAR(32) NOT NULL,
+		CONSTRAINT fk_user_role_user FOREIGN KEY (user_id) REFERENCES testdb.public."user" (id)
+			ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenPostgresTable(context.Background(), db, schema, "user_role", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, len(tb.ForeignKeys))
+	assert.Equal(t, "fk_user_role_user", tb.ForeignKeys[0].Name)
+	assert.Equal(t, []string{"user_id"}, tb.ForeignKeys[0].Columns)
+	assert.Equal(t, "public", tb.ForeignKeys[0].ReferencedSchema)
+	assert.Equal(t, "user", tb.ForeignKeys[0].ReferencedTable)
+	assert.Equal(t, []string{"id"}, tb.ForeignKeys[0].ReferencedColumns)
+	assert.Equal(t, "CASCADE", tb.ForeignKeys[0].OnDelete)
+	assert.Equal(t, "NO ACTION", tb.ForeignKeys[0].OnUpdate)
+	assert.Equal(t, true, tb.ForeignKeys[0].IsDeferrable)
+
+	_, err = db.Exec(`CREATE VIEW testdb.public.active_user AS SELECT id, username FROM testdb.public."user" WHERE deleted_at IS NULL;
+	CREATE MATERIALIZED VIEW testdb.public.user_stats AS SELECT status, count(*) AS total FROM testdb.public."user" GROUP BY status;
+	COMMENT ON VIEW testdb.public.active_user IS 'Users not deleted';
+	COMMENT ON MATERIALIZED VIEW testdb.public.user_stats IS 'User count by status';
+	COMMENT ON COLUMN testdb.public.user_stats.total IS 'Number of users';`)
+	require.NoError(t, err)
+
+	tb, err = GenPostgresTable(context.Background(), db, schema, "active_user", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "view", tb.Kind)
+	assert.Equal(t, "Users not deleted", *tb.Comment)
+	assert.Equal(t, 2, len(tb.Columns))
+	assert.Equal(t, "username", tb.Columns[1].Name)
+
+	tb, err = GenPostgresTable(context.Background(), db, schema, "user_stats", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "materialized_view", tb.Kind)
+	assert.Equal(t, "User count by status", *tb.Comment)
+	assert.Equal(t, 2, len(tb.Columns))
+	assert.Equal(t, "Number of users", *tb.Columns[1].Comment)
+
+	_, err = db.Exec(`CREATE TABLE testdb.public.product (
+		price NUMERIC(10, 2) NOT NULL,
+		tags  TEXT[],
+		codes CHAR(3)[][],
+		id    BIGINT GENERATED ALWAYS AS IDENTITY,
+		total NUMERIC(12, 2) GENERATED ALWAYS AS (price * 2) STORED
+	);`)
+	require.NoError(t, err)
+
+	tb, err = GenPostgresTable(context.Background(), db, schema, "product", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "numeric", tb.Columns[0].BaseType)
+	assert.Equal(t, 10, *tb.Columns[0].NumericPrecision)
+	assert.Equal(t, 2, *tb.Columns[0].NumericScale)
+	assert.Equal(t, 0, tb.Columns[0].ArrayDimensions)
+	assert.Equal(t, "text", tb.Columns[1].BaseType)
+	assert.Equal(t, 1, tb.Columns[1].ArrayDimensions)
+	assert.Equal(t, 3, *tb.Columns[2].MaxLength)
+	assert.Equal(t, 2, tb.Columns[2].ArrayDimensions)
+	assert.Equal(t, "array", tb.Columns[2].LogicalType)
+	assert.Equal(t, "string", *tb.Columns[2].ElementLogicalType)
+	assert.Equal(t, "int64", tb.Columns[3].LogicalType)
+	assert.Equal(t, true, tb.Columns[3].IsIdentity)
+	assert.Equal(t, "always", *tb.Columns[3].IdentityGeneration)
+	assert.Equal(t, "public.product_id_seq", *tb.Columns[3].SequenceName)
+	assert.Equal(t, true, tb.Columns[3].IsDatabaseGenerated)
+	assert.Equal(t, true, tb.Columns[4].IsGenerated)
+	assert.Equal(t, "stored", *tb.Columns[4].GenerationKind)
+	assert.Equal(t, "(price * (2)::numeric)", *tb.Columns[4].GenerationExpression)
+	assert.Nil(t, tb.Columns[4].DefaultValue)
+	assert.Equal(t, true, tb.Columns[4].IsDatabaseGenerated)
+
+	_, err = db.Exec(`
+		CREATE TYPE mood AS ENUM ('sad', 'ok', 'happy');
+		CREATE DOMAIN email AS VARCHAR(255) CHECK (VALUE LIKE '%@%');
+		CREATE TYPE address AS (city TEXT, zip VARCHAR(10));
+		CREATE TABLE testdb.public.profile (
+			mood    mood,
+			moods   mood[],
+			email   email,
+			address address
+		);`)
+	require.NoError(t, err)
+
+	tb, err = GenPostgresTable(context.Background(), db, schema, "profile", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sad", "ok", "happy"}, tb.Columns[0].EnumValues)
+	assert.Equal(t, &model.UserType{Schema: "public", Name: "mood", Kind: "enum", EnumValues: []string{"sad", "ok", "happy"}}, tb.Columns[0].UserType)
+	assert.Equal(t, 1, tb.Columns[1].ArrayDimensions)
+	assert.Equal(t, "mood", tb.Columns[1].UserType.Name)
+	assert.Equal(t, "enum", tb.Columns[0].LogicalType)
+	assert.Equal(t, "enum", *tb.Columns[1].ElementLogicalType)
+	assert.Equal(t, "string", tb.Columns[2].LogicalType) // domain over varchar(255)
+	assert.Equal(t, "other", tb.Columns[3].LogicalType)
+	assert.Equal(t, "character varying", tb.Columns[2].BaseType)
+	assert.Equal(t, 255, *tb.Columns[2].MaxLength)
+	assert.Equal(t, "domain", tb.Columns[2].UserType.Kind)
+	assert.Equal(t, "character varying(255)", *tb.Columns[2].UserType.BaseType)
+	assert.Nil(t, tb.Columns[2].EnumValues)
+	assert.Equal(t, "composite", tb.Columns[3].UserType.Kind)
+	assert.Equal(t, []*model.UserTypeAttribute{{Name: "city", Type: "text"}, {Name: "zip", Type: "character varying(10)"}}, tb.Columns[3].UserType.Attributes)
+
+	_, err = db.Exec(`
+		CREATE EXTENSION IF NOT EXISTS btree_gist;
+		CREATE TABLE testdb.public.booking (
+			id        INT PRIMARY KEY,
+			code      VARCHAR(32) NOT NULL CONSTRAINT uk_booking_code UNIQUE,
+			room      INT NOT NULL,
+			during    TSRANGE NOT NULL,
+			guests    INT NOT NULL CONSTRAINT ck_booking_guests CHECK (guests > 0),
+			CONSTRAINT ex_booking_room EXCLUDE USING gist (room WITH =, during WITH &&)
+		);`)
+	require.NoError(t, err)
+
+	tb, err = GenPostgresTable(context.Background(), db, schema, "booking", nil)
+	require.NoError(t, err)
+	assert.Len(t, tb.Constraints, 4)
+	assert.Equal(t, "booking_pkey", tb.Constraints[0].Name)
+	assert.Equal(t, "primary_key", tb.Constraints[0].Type)
+	assert.Equal(t, "booking_pkey", *tb.Constraints[0].IndexName)
+	assert.Equal(t, "uk_booking_code", tb.Constraints[1].Name)
+	assert.Equal(t, []string{"code"}, tb.Constraints[1].Columns)
+	assert.Equal(t, "exclusion", tb.Constraints[2].Type)
+	assert.Equal(t, []string{"room", "during"}, tb.Constraints[2].Columns)
+	assert.Equal(t, "ex_booking_room", *tb.Constraints[2].IndexName)
+	assert.Equal(t, "ck_booking_guests", tb.Constraints[3].Name)
+	assert.Equal(t, []string{"guests"}, tb.Constraints[3].Columns)
+	assert.Equal(t, "(guests > 0)", *tb.Constraints[3].CheckExpression)
+	assert.Nil(t, tb.Constraints[3].IndexName)
+}
+
+func TestPostgresIntrospector_GetRoutines(t *testing.T) {
+	err := exec.Command("docker", "info").Run()
+	if err != nil {
+		t.Skip("Docker not available, skipping Postgres tests")
+	}
+
+	ctx := context.Background()
+	postgresContainer, err := postgres.Run(ctx,
+		"postgres:latest",
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("root"),
+		postgres.WithPassword("root"),
+		postgres.BasicWaitStrategies(),
+	)
+	require.NoError(t, err)
+	defer func() {
+		if err := postgresContainer.Terminate(ctx); err != nil {
+			t.Fatalf("failed to terminate container: %s", err)
+		}
+	}()
+
+	host, err := postgresContainer.Host(ctx)
+	require.NoError(t, err)
+	port, err := postgresContainer.MappedPort(ctx, "5432")
+	require.NoError(t, err)
+
+	db, err := dburl.Open(fmt.Sprintf("postgres://root:root@%s:%s/testdb?sslmode=disable", host, port.Port()))
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+	CREATE FUNCTION add(a integer, b integer DEFAULT 1) RETURNS integer AS 'SELECT a + b' LANGUAGE sql;
+	CREATE FUNCTION add(a numeric, b numeric) RETURNS numeric AS 'SELECT a + b' LANGUAGE sql;
+	CREATE FUNCTION users_by_name(name text) RETURNS TABLE(id bigint, tags text[]) AS 'SELECT 1::bigint, ARRAY[name]' LANGUAGE sql;
+	CREATE PROCEDURE reset(INOUT total integer) AS 'SELECT 0' LANGUAGE sql;
+	COMMENT ON FUNCTION add(integer, integer) IS 'Adds two integers';`)
+	require.NoError(t, err)
+
+	names, err := PostgresIntrospector{}.ListRoutines(ctx, db, "public")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"add", "reset", "users_by_name"}, names)
+
+	routines, err := PostgresIntrospector{}.GetRoutines(ctx, db, "public", "add")
+	require.NoError(t, err)
+	require.Len(t, routines, 2)
+
+	add := routines[0]
+	assert.Equal(t, model.RoutineKindFunction, add.Kind)
+	assert.Equal(t, "integer", *add.ReturnType)
+	assert.Equal(t, "Adds two integers", *add.Comment)
+	require.Len(t, add.Parameters, 2)
+	assert.Equal(t, "a", add.Parameters[0].Name)
+	assert.Equal(t, model.ParameterModeIn, add.Parameters[0].Mode)
+	assert.Equal(t, model.LogicalTypeInt32, add.Parameters[0].LogicalType)
+	assert.Equal(t, "1", *add.Parameters[1].DefaultValue)
+	assert.NotEqual(t, add.SpecificName, routines[1].SpecificName)
+
+	routines, err = PostgresIntrospector{}.GetRoutines(ctx, db, "public", "users_by_name")
+	require.NoError(t, err)
+	require.Len(t, routines[0].Parameters, 1)
+	require.Len(t, routines[0].ResultColumns, 2)
+	assert.Equal(t, "tags", routines[0].ResultColumns[1].Name)
+	assert.Equal(t, model.LogicalTypeArray, routines[0].ResultColumns[1].LogicalType)
+
+	routines, err = PostgresIntrospector{}.GetRoutines(ctx, db, "public", "reset")
+	require.NoError(t, err)
+	assert.Equal(t, model.RoutineKindProcedure, routines[0].Kind)
+	assert.Nil(t, routines[0].ReturnType)
+	assert.Equal(t, model.ParameterModeInOut, routines[0].Parameters[0].Mode)
 }
@@ -0,0 +1,582 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// ProtoSchema collects the messages and enums of .proto files,
+// type references between the files are resolved when the messages are read.
+type ProtoSchema struct {
+	messages []*model.Message // Top-level messages in declaration order
+	enums    map[string]*model.MessageEnum
+	types    map[string]string // Full name to kind (message or enum) of every declared type
+}
+
+func NewProtoSchema() *ProtoSchema {
+	return &ProtoSchema{enums: make(map[string]*model.MessageEnum), types: make(map[string]string)}
+}
+
+// ParseProto parses a single .proto file, see ProtoSchema.Add.
+func ParseProto(file, content string) ([]*model.Message, error) {
+	schema := NewProtoSchema()
+	if err := schema.Add(file, content); err != nil {
+		return nil, err
+	}
+	return schema.Messages(), nil
+}
+
+// Add parses the messages and enums of a .proto file, services, extensions and options are ignored.
+func (s *ProtoSchema) Add(file, content string) error {
+	tokens, err := tokenizeProto(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	p := &protoParser{schema: s, tokens: tokens, file: file, syntax: "proto2"}
+	if err := p.parseFile(); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	return nil
+}
+
+// Messages returns the top-level messages, nested messages are in Message.Messages.
+func (s *ProtoSchema) Messages() []*model.Message {
+	var resolve func(msg *model.Message)
+	resolve = func(msg *model.Message) {
+		for _, f := range msg.Fields {
+			s.resolveField(msg, f)
+			f.IsOptional = hasPresence(msg, f)
+		}
+		for _, nested := range msg.Messages {
+			resolve(nested)
+		}
+	}
+	for _, msg := range s.messages {
+		resolve(msg)
+	}
+	return s.messages
+}
+
+// resolveField resolves the type of the field the way protoc does, from the innermost scope to the package root.
+func (s *ProtoSchema) resolveField(msg *model.Message, f *model.MessageField) {
+	if f.Kind == model.MessageFieldKindMap {
+		return
+	}
+	if _, ok := protoScalarTypes[f.Type]; ok {
+		f.Kind = model.MessageFieldKindScalar
+		return
+	}
+
+	f.Kind = model.MessageFieldKindMessage
+	f.TypeFullName = strings.TrimPrefix(f.Type, ".")
+	if strings.HasPrefix(f.Type, ".") {
+		if kind, ok := s.types[f.TypeFullName]; ok {
+			f.Kind = kind
+		}
+		return
+	}
+	for scope := msg.FullName; ; {
+		name := f.Type
+		if scope != "" {
+			name = scope + "." + f.Type
+		}
+		if kind, ok := s.types[name]; ok {
+			f.Kind, f.TypeFullName = kind, name
+			return
+		}
+		if scope == "" {
+			return
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// hasPresence tells whether the field distinguishes an unset value from the default value.
+func hasPresence(msg *model.Message, f *model.MessageField) bool {
+	switch {
+	case f.IsRepeated || f.Kind == model.MessageFieldKindMap || f.Label == "required":
+		return false
+	case f.Label == "optional" || f.OneOf != nil || f.Kind == model.MessageFieldKindMessage:
+		return true
+	}
+	return msg.Syntax != "proto3" // Fields of proto2 and editions track presence by default
+}
+
+var protoScalarTypes = map[string]string{
+	"double":   model.LogicalTypeFloat64,
+	"float":    model.LogicalTypeFloat32,
+	"int32":    model.LogicalTypeInt32,
+	"sint32":   model.LogicalTypeInt32,
+	"sfixed32": model.LogicalTypeInt32,
+	"uint32":   model.LogicalTypeInt64,
+	"fixed32":  model.LogicalTypeInt64,
+	"int64":    model.LogicalTypeInt64,
+	"sint64":   model.LogicalTypeInt64,
+	"sfixed64": model.LogicalTypeInt64,
+	"uint64":   model.LogicalTypeInt64,
+	"fixed64":  model.LogicalTypeInt64,
+	"bool":     model.LogicalTypeBool,
+	"string":   model.LogicalTypeText,
+	"bytes":    model.LogicalTypeBinary,
+}
+
+// protoWellKnownTypes are the logical types of the well-known message types that hold a single value.
+var protoWellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp":   model.LogicalTypeTimestamptz,
+	"google.protobuf.Struct":      model.LogicalTypeJSON,
+	"google.protobuf.Value":       model.LogicalTypeJSON,
+	"google.protobuf.ListValue":   model.LogicalTypeJSON,
+	"google.protobuf.DoubleValue": model.LogicalTypeFloat64,
+	"google.protobuf.FloatValue":  model.LogicalTypeFloat32,
+	"google.protobuf.Int64Value":  model.LogicalTypeInt64,
+	"google.protobuf.UInt64Value": model.LogicalTypeInt64,
+	"google.protobuf.Int32Value":  model.LogicalTypeInt32,
+	"google.protobuf.UInt32Value": model.LogicalTypeInt64,
+	"google.protobuf.BoolValue":   model.LogicalTypeBool,
+	"google.protobuf.StringValue": model.LogicalTypeText,
+	"google.protobuf.BytesValue":  model.LogicalTypeBinary,
+	"google.type.Date":            model.LogicalTypeDate,
+	"google.type.TimeOfDay":       model.LogicalTypeTime,
+	"google.type.Decimal":         model.LogicalTypeDecimal,
+}
+
+// MessageTable maps a message to a table, fields become columns in declaration order and the field named id is the primary key.
+// Fields that track presence are nullable, enum fields carry the enum values, and message and map fields are json.
+func (s *ProtoSchema) MessageTable(msg *model.Message, schema string) *model.Table {
+	name := strings.ReplaceAll(strings.TrimPrefix(strings.TrimPrefix(msg.FullName, msg.Package), "."), ".", "_")
+	t := &model.Table{Name: name, Schema: schema, Kind: model.TableKindTable, Comment: msg.Comment, Indexes: []*model.Index{}}
+
+	for _, f := range msg.Fields {
+		col := &model.Column{
+			Name:         f.Name,
+			Ordinal:      len(t.Columns) + 1,
+			BaseType:     f.Type,
+			Type:         f.Type,
+			IsNullable:   f.IsOptional,
+			DefaultValue: f.DefaultValue,
+			Comment:      f.Comment,
+		}
+
+		switch f.Kind {
+		case model.MessageFieldKindScalar:
+			col.LogicalType = protoScalarTypes[f.Type]
+			col.IsUnsigned = strings.HasPrefix(f.Type, "uint") || strings.HasPrefix(f.Type, "fixed")
+		case model.MessageFieldKindEnum:
+			col.LogicalType = model.LogicalTypeEnum
+			if enum := s.enums[f.TypeFullName]; enum != nil {
+				for _, v := range enum.Values {
+					col.EnumValues = append(col.EnumValues, v.Name)
+				}
+				col.UserType = &model.UserType{Schema: msg.Package, Name: enum.FullName, Kind: model.UserTypeKindEnum, EnumValues: col.EnumValues}
+			}
+		case model.MessageFieldKindMessage:
+			col.LogicalType = model.LogicalTypeJSON
+			if logicalType, ok := protoWellKnownTypes[f.TypeFullName]; ok {
+				col.LogicalType = logicalType
+			}
+		case model.MessageFieldKindMap:
+			col.LogicalType = model.LogicalTypeJSON
+			col.BaseType = "map"
+		}
+
+		if f.IsRepeated {
+			elementType := col.LogicalType
+			col.ElementLogicalType = &elementType
+			col.LogicalType = model.LogicalTypeArray
+			col.ArrayDimensions = 1
+			col.Type = f.Type + "[]"
+		}
+		if f.Name == "id" && !f.IsRepeated {
+			col.IsPrimaryKey = true
+			col.IsNullable = false
+			t.Constraints = append(t.Constraints, &model.Constraint{Name: name + "_pkey", Type: model.ConstraintTypePrimaryKey, Columns: []string{f.Name}})
+		}
+		t.Columns = append(t.Columns, col)
+	}
+	return t
+}
+
+type protoParser struct {
+	schema *ProtoSchema
+	tokens []*protoToken
+	pos    int
+	file   string
+	pkg    string
+	syntax string
+}
+
+func (p *protoParser) peek() *protoToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *protoParser) next() (*protoToken, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of file")
+	}
+	p.pos++
+	return t, nil
+}
+
+func (p *protoParser) accept(text string) bool {
+	if t := p.peek(); t != nil && t.kind != protoString && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *protoParser) expect(text string) (*protoToken, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind == protoString || t.text != text {
+		return nil, fmt.Errorf("line %d: expected %q, got %q", t.line, text, t.text)
+	}
+	return t, nil
+}
+
+func (p *protoParser) ident() (*protoToken, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != protoIdent {
+		return nil, fmt.Errorf("line %d: expected a name, got %q", t.line, t.text)
+	}
+	return t, nil
+}
+
+// skipStatement skips to the end of the statement, including the block of statements like service and extend.
+func (p *protoParser) skipStatement() error {
+	depth := 0
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		if t.kind != protoSymbol {
+			continue
+		}
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+			if depth == 0 {
+				p.accept(";")
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *protoParser) parseFile() error {
+	for p.peek() != nil {
+		t := p.peek()
+		switch {
+		case p.accept(";"):
+		case p.accept("syntax"), p.accept("edition"):
+			if _, err := p.expect("="); err != nil {
+				return err
+			}
+			v, err := p.next()
+			if err != nil {
+				return err
+			}
+			p.syntax = v.text
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case p.accept("package"):
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			p.pkg = name.text
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case p.accept("message"):
+			msg, err := p.parseMessage(t, p.pkg)
+			if err != nil {
+				return err
+			}
+			p.schema.messages = append(p.schema.messages, msg)
+		case p.accept("enum"):
+			if _, err := p.parseEnum(t, p.pkg); err != nil {
+				return err
+			}
+		case t.kind == protoIdent: // import, option, service, extend
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+		}
+	}
+	return nil
+}
+
+// parseMessage parses a message after the message keyword, first is the keyword token, which holds the comment.
+func (p *protoParser) parseMessage(first *protoToken, scope string) (*model.Message, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	open, err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+	msg := &model.Message{
+		Name:     name.text,
+		FullName: qualifyProtoName(scope, name.text),
+		Package:  p.pkg,
+		File:     p.file,
+		Syntax:   p.syntax,
+		Comment:  protoComment(first.leading, open.trailing),
+	}
+	p.schema.types[msg.FullName] = model.MessageFieldKindMessage
+
+	if err := p.parseMessageBody(msg, nil); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// parseMessageBody parses the statements up to the closing brace of a message or of a oneof of the message.
+func (p *protoParser) parseMessageBody(msg *model.Message, oneOf *string) error {
+	for {
+		t := p.peek()
+		if t == nil {
+			return fmt.Errorf("message %s is not closed", msg.Name)
+		}
+		switch {
+		case p.accept("}"):
+			return nil
+		case p.accept(";"):
+		case oneOf == nil && p.accept("message"):
+			nested, err := p.parseMessage(t, msg.FullName)
+			if err != nil {
+				return err
+			}
+			msg.Messages = append(msg.Messages, nested)
+		case oneOf == nil && p.accept("enum"):
+			enum, err := p.parseEnum(t, msg.FullName)
+			if err != nil {
+				return err
+			}
+			msg.Enums = append(msg.Enums, enum)
+		case oneOf == nil && p.accept("oneof"):
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if _, err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(msg, &name.text); err != nil {
+				return err
+			}
+		case t.text == "option", t.text == "reserved", t.text == "extensions", t.text == "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			if err := p.parseField(msg, oneOf); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *protoParser) parseField(msg *model.Message, oneOf *string) error {
+	first := p.peek()
+	f := &model.MessageField{OneOf: oneOf}
+	if p.accept("optional") || p.accept("required") || p.accept("repeated") {
+		f.Label = first.text
+	}
+
+	typ, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if typ.text == "group" {
+		return fmt.Errorf("line %d: groups are not supported", typ.line)
+	}
+	f.Type = typ.text
+	if typ.text == "map" && p.accept("<") {
+		key, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if _, err := p.expect(","); err != nil {
+			return err
+		}
+		value, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if _, err := p.expect(">"); err != nil {
+			return err
+		}
+		f.Kind = model.MessageFieldKindMap
+		f.Type = "map<" + key.text + ", " + value.text + ">"
+		f.MapKeyType, f.MapValueType = &key.text, &value.text
+	}
+
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	f.Name = name.text
+	if _, err := p.expect("="); err != nil {
+		return err
+	}
+	number, err := p.next()
+	if err != nil {
+		return err
+	}
+	if f.Number, err = strconv.Atoi(number.text); err != nil {
+		return fmt.Errorf("line %d: invalid field number %q", number.line, number.text)
+	}
+
+	if p.accept("[") {
+		if err := p.parseFieldOptions(f); err != nil {
+			return err
+		}
+	}
+	end, err := p.expect(";")
+	if err != nil {
+		return err
+	}
+
+	f.IsRepeated = f.Label == "repeated"
+	f.Comment = protoComment(first.leading, end.trailing)
+	msg.Fields = append(msg.Fields, f)
+	return nil
+}
+
+// parseFieldOptions parses the options of a field after the opening bracket, only default is kept.
+func (p *protoParser) parseFieldOptions(f *model.MessageField) error {
+	for {
+		t, err := p.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case t.kind == protoSymbol && t.text == "]":
+			return nil
+		case t.kind == protoIdent && t.text == "default" && p.accept("="):
+			v, err := p.next()
+			if err != nil {
+				return err
+			}
+			value := v.text
+			f.DefaultValue = &value
+		case t.kind == protoSymbol && t.text == "{": // Message literals of custom options
+			p.pos--
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *protoParser) parseEnum(first *protoToken, scope string) (*model.MessageEnum, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	open, err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+	enum := &model.MessageEnum{
+		Name:     name.text,
+		FullName: qualifyProtoName(scope, name.text),
+		Comment:  protoComment(first.leading, open.trailing),
+	}
+	p.schema.types[enum.FullName] = model.MessageFieldKindEnum
+	p.schema.enums[enum.FullName] = enum
+
+	for !p.accept("}") {
+		t := p.peek()
+		if t == nil {
+			return nil, fmt.Errorf("enum %s is not closed", enum.Name)
+		}
+		if p.accept(";") {
+			continue
+		}
+		if t.text == "option" || t.text == "reserved" {
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		valueName, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect("="); err != nil {
+			return nil, err
+		}
+		number, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(number.text, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid enum value %q", number.line, number.text)
+		}
+		if p.accept("[") {
+			if err := p.parseFieldOptions(&model.MessageField{}); err != nil {
+				return nil, err
+			}
+		}
+		end, err := p.expect(";")
+		if err != nil {
+			return nil, err
+		}
+		enum.Values = append(enum.Values, &model.MessageEnumValue{
+			Name:    valueName.text,
+			Number:  int(n),
+			Comment: protoComment(t.leading, end.trailing),
+		})
+	}
+	return enum, nil
+}
+
+func qualifyProtoName(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// protoComment returns the leading comment, or the trailing one if there is no leading comment.
+func protoComment(leading, trailing string) *string {
+	if leading != "" {
+		return &leading
+	}
+	if trailing != "" {
+		return &trailing
+	}
+	return nil
+}
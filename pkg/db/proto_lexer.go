@@ -0,0 +1,150 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type protoTokenKind int
+
+const (
+	protoIdent protoTokenKind = iota // Identifiers, including qualified names like google.protobuf.Timestamp
+	protoNumber
+	protoString
+	protoSymbol
+)
+
+type protoToken struct {
+	kind     protoTokenKind
+	text     string // Unquoted for strings
+	line     int
+	leading  string // Comment attached to the token, i.e. the comment lines right above it
+	trailing string // Comment on the same line after the token
+}
+
+// tokenizeProto splits a .proto file into tokens, comments are attached to the tokens as documentation,
+// comments separated from the next token by a blank line are detached and dropped like protoc does.
+func tokenizeProto(src string) ([]*protoToken, error) {
+	var tokens []*protoToken
+	var pending []string
+	pendingEnd := -1 // Line the pending comments end at
+	pendingAt := 0   // Index of the token following the pending comments
+	line := 1
+
+	comment := func(text string, start, end int) {
+		if len(tokens) > 0 && tokens[len(tokens)-1].line == start && pendingEnd < 0 {
+			prev := tokens[len(tokens)-1]
+			prev.trailing = joinComment(prev.trailing, text)
+			return
+		}
+		if len(pending) > 0 && start > pendingEnd+1 {
+			pending = nil
+		}
+		pending = append(pending, text)
+		pendingEnd, pendingAt = end, len(tokens)
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			comment(cleanLineComment(src[i+2:i+end]), line, line)
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			text := src[i+2 : i+2+end]
+			start := line
+			line += strings.Count(text, "\n")
+			comment(cleanBlockComment(text), start, line)
+			i += end + 4
+		case c == '"' || c == '\'':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				if src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, &protoToken{kind: protoString, text: sb.String(), line: line})
+			i = j + 1
+		case isProtoIdentStart(c) || (c == '.' && i+1 < len(src) && isProtoIdentStart(src[i+1])):
+			j := i + 1
+			for j < len(src) && (isProtoIdentPart(src[j]) || (src[j] == '.' && j+1 < len(src) && isProtoIdentStart(src[j+1]))) {
+				j++
+			}
+			tokens = append(tokens, &protoToken{kind: protoIdent, text: src[i:j], line: line})
+			i = j
+		case unicode.IsDigit(rune(c)) || ((c == '-' || c == '.') && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			j := i + 1
+			for j < len(src) && (isProtoIdentPart(src[j]) || src[j] == '.' ||
+				((src[j] == '-' || src[j] == '+') && (src[j-1] == 'e' || src[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, &protoToken{kind: protoNumber, text: src[i:j], line: line})
+			i = j
+		default:
+			tokens = append(tokens, &protoToken{kind: protoSymbol, text: string(c), line: line})
+			i++
+		}
+
+		// Attach the pending comments to the token right below them
+		if len(pending) > 0 && len(tokens) > pendingAt {
+			if next := tokens[pendingAt]; next.line <= pendingEnd+1 {
+				next.leading = strings.Join(pending, "\n")
+			}
+			pending, pendingEnd = nil, -1
+		}
+	}
+	return tokens, nil
+}
+
+func isProtoIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isProtoIdentPart(c byte) bool {
+	return isProtoIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+func cleanLineComment(text string) string {
+	return strings.TrimSpace(strings.TrimPrefix(text, "/"))
+}
+
+// cleanBlockComment strips the leading * of the lines of a /* */ comment.
+func cleanBlockComment(text string) string {
+	lines := strings.Split(strings.TrimPrefix(text, "*"), "\n")
+	var result []string
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		l = strings.TrimSpace(strings.TrimPrefix(l, "*"))
+		result = append(result, l)
+	}
+	return strings.TrimSpace(strings.Join(result, "\n"))
+}
+
+func joinComment(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
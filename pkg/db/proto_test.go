@@ -0,0 +1,184 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderProto = `
+syntax = "proto3";
+
+package shop.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/shop/v1;shopv1";
+
+// An order placed by a customer.
+message Order {
+  int64 id = 1;
+  string customer = 2; // Customer name
+  optional string note = 3 [json_name = "remark"];
+  repeated Item items = 4;
+  Status status = 5;
+  google.protobuf.Timestamp created_at = 6;
+  map<string, string> labels = 7;
+
+  oneof payment {
+    string card = 8;
+    string voucher = 9;
+  }
+
+  /* A line of the order. */
+  message Item {
+    string sku = 1;
+    uint32 quantity = 2;
+  }
+
+  reserved 10 to 12;
+}
+
+// Detached comment
+
+enum Status {
+  // Not paid yet
+  STATUS_UNSPECIFIED = 0;
+  STATUS_PAID = 1;
+}
+
+service OrderService {
+  rpc GetOrder(Order) returns (Order) {
+    option deprecated = true;
+  }
+}
+`
+
+func TestParseProto(t *testing.T) {
+	messages, err := ParseProto("order.proto", orderProto)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	order := messages[0]
+	assert.Equal(t, "Order", order.Name)
+	assert.Equal(t, "shop.v1.Order", order.FullName)
+	assert.Equal(t, "shop.v1", order.Package)
+	assert.Equal(t, "proto3", order.Syntax)
+	assert.Equal(t, "An order placed by a customer.", *order.Comment)
+	require.Len(t, order.Fields, 9)
+
+	id := order.Fields[0]
+	assert.Equal(t, 1, id.Number)
+	assert.Equal(t, model.MessageFieldKindScalar, id.Kind)
+	assert.False(t, id.IsOptional)
+
+	assert.Equal(t, "Customer name", *order.Fields[1].Comment)
+	assert.True(t, order.Fields[2].IsOptional)
+	assert.Equal(t, "optional", order.Fields[2].Label)
+
+	items := order.Fields[3]
+	assert.True(t, items.IsRepeated)
+	assert.False(t, items.IsOptional)
+	assert.Equal(t, model.MessageFieldKindMessage, items.Kind)
+	assert.Equal(t, "shop.v1.Order.Item", items.TypeFullName)
+
+	status := order.Fields[4]
+	assert.Equal(t, model.MessageFieldKindEnum, status.Kind)
+	assert.Equal(t, "shop.v1.Status", status.TypeFullName)
+	assert.False(t, status.IsOptional)
+
+	createdAt := order.Fields[5]
+	assert.Equal(t, "google.protobuf.Timestamp", createdAt.TypeFullName)
+	assert.True(t, createdAt.IsOptional)
+
+	labels := order.Fields[6]
+	assert.Equal(t, model.MessageFieldKindMap, labels.Kind)
+	assert.Equal(t, "map<string, string>", labels.Type)
+	assert.Equal(t, "string", *labels.MapValueType)
+
+	card := order.Fields[7]
+	assert.Equal(t, "payment", *card.OneOf)
+	assert.True(t, card.IsOptional)
+
+	require.Len(t, order.Messages, 1)
+	item := order.Messages[0]
+	assert.Equal(t, "shop.v1.Order.Item", item.FullName)
+	assert.Equal(t, "A line of the order.", *item.Comment)
+	assert.Equal(t, []string{"sku", "quantity"}, []string{item.Fields[0].Name, item.Fields[1].Name})
+}
+
+func TestProtoSchema_MessageTable(t *testing.T) {
+	schema := NewProtoSchema()
+	require.NoError(t, schema.Add("order.proto", orderProto))
+	messages := schema.Messages()
+
+	table := schema.MessageTable(messages[0], "public")
+	assert.Equal(t, "Order", table.Name)
+	assert.Equal(t, "public", table.Schema)
+	assert.Equal(t, []*model.Constraint{{Name: "Order_pkey", Type: model.ConstraintTypePrimaryKey, Columns: []string{"id"}}}, table.Constraints)
+
+	cols := table.Columns
+	assert.True(t, cols[0].IsPrimaryKey)
+	assert.Equal(t, model.LogicalTypeInt64, cols[0].LogicalType)
+	assert.False(t, cols[1].IsNullable)
+	assert.Equal(t, model.LogicalTypeText, cols[1].LogicalType)
+	assert.True(t, cols[2].IsNullable)
+	assert.Equal(t, "Item[]", cols[3].Type)
+	assert.Equal(t, model.LogicalTypeArray, cols[3].LogicalType)
+	assert.Equal(t, model.LogicalTypeJSON, *cols[3].ElementLogicalType)
+	assert.Equal(t, model.LogicalTypeEnum, cols[4].LogicalType)
+	assert.Equal(t, []string{"STATUS_UNSPECIFIED", "STATUS_PAID"}, cols[4].EnumValues)
+	assert.Equal(t, "shop.v1.Status", cols[4].UserType.Name)
+	assert.Equal(t, model.LogicalTypeTimestamptz, cols[5].LogicalType)
+	assert.Equal(t, model.LogicalTypeJSON, cols[6].LogicalType)
+
+	item := schema.MessageTable(messages[0].Messages[0], "public")
+	assert.Equal(t, "Order_Item", item.Name)
+	assert.Empty(t, item.Constraints)
+	assert.True(t, item.Columns[1].IsUnsigned)
+}
+
+func TestParseProto_whenProto2_thenUnlabeledFieldsArePresenceTracked(t *testing.T) {
+	messages, err := ParseProto("user.proto", `
+		message User {
+			required int64 id = 1;
+			optional string name = 2 [default = "anonymous"];
+			repeated string tags = 3;
+		}`)
+	require.NoError(t, err)
+
+	fields := messages[0].Fields
+	assert.Equal(t, "proto2", messages[0].Syntax)
+	assert.False(t, fields[0].IsOptional)
+	assert.True(t, fields[1].IsOptional)
+	assert.Equal(t, "anonymous", *fields[1].DefaultValue)
+	assert.False(t, fields[2].IsOptional)
+}
+
+func TestParseProto_whenMessageIsNotClosed_thenReturnError(t *testing.T) {
+	_, err := ParseProto("broken.proto", `syntax = "proto3"; message User { int64 id = 1;`)
+	assert.ErrorContains(t, err, "broken.proto")
+}
+
+func Test_tokenizeProto(t *testing.T) {
+	tokens, err := tokenizeProto(`
+// Leading
+// comment
+int64 id = -1; // Trailing
+
+/* Detached */
+
+string name = 0x2;`)
+	require.NoError(t, err)
+
+	var texts []string
+	for _, token := range tokens {
+		texts = append(texts, token.text)
+	}
+	assert.Equal(t, []string{"int64", "id", "=", "-1", ";", "string", "name", "=", "0x2", ";"}, texts)
+	assert.Equal(t, "Leading\ncomment", tokens[0].leading)
+	assert.Equal(t, "Trailing", tokens[4].trailing)
+	assert.Empty(t, tokens[5].leading)
+}
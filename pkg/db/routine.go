@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// RoutineIntrospector is implemented by the introspectors of databases with stored procedures and functions,
+// the built-in MySQL, PostgreSQL and SQL Server introspectors implement it.
+type RoutineIntrospector interface {
+	// ListRoutines lists the distinct names of all procedures and functions in the given schema, used to resolve routine name patterns.
+	ListRoutines(ctx context.Context, db *sql.DB, schema string) ([]string, error)
+	// GetRoutines returns the routines with the given name, several for overloaded functions, empty if there is none.
+	GetRoutines(ctx context.Context, db *sql.DB, schema, name string) ([]*model.Routine, error)
+}
+
+// GenRoutines returns the procedures and functions with the given name,
+// it fails if the introspector does not support routines.
+func GenRoutines(ctx context.Context, introspector Introspector, db *sql.DB, schema, name string) ([]*model.Routine, error) {
+	ri, ok := introspector.(RoutineIntrospector)
+	if !ok {
+		return nil, fmt.Errorf("routines are not supported by %T", introspector)
+	}
+	return ri.GetRoutines(ctx, db, schema, name)
+}
+
+// fillParameterLogicalType sets the logical type of the parameter from its type like for a column of the dialect.
+func fillParameterLogicalType(p *model.RoutineParameter, baseType, dialect string) {
+	col := typeColumn(p.Type, baseType)
+	fillDialectLogicalType(col, dialect)
+	p.LogicalType = col.LogicalType
+}
+
+// typeColumn returns a nullable column of the given type, array suffixes of the base type become array dimensions.
+func typeColumn(typ, baseType string) *model.Column {
+	col := &model.Column{Type: typ, BaseType: baseType, IsNullable: true}
+	for strings.HasSuffix(col.BaseType, "[]") {
+		col.BaseType = strings.TrimSuffix(col.BaseType, "[]")
+		col.ArrayDimensions++
+	}
+	return col
+}
@@ -0,0 +1,24 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenRoutines_whenIntrospectorDoesNotSupportRoutines_thenReturnError(t *testing.T) {
+	_, err := GenRoutines(context.Background(), SQLiteIntrospector{}, nil, "main", "add")
+	assert.ErrorContains(t, err, "routines are not supported")
+}
+
+func Test_fillParameterLogicalType(t *testing.T) {
+	p := &model.RoutineParameter{Type: "integer[]"}
+	fillParameterLogicalType(p, "integer[]", "postgres")
+	assert.Equal(t, model.LogicalTypeArray, p.LogicalType)
+
+	p = &model.RoutineParameter{Type: "int"}
+	fillParameterLogicalType(p, "int", "mysql")
+	assert.Equal(t, model.LogicalTypeInt32, p.LogicalType)
+}
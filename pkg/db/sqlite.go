@@ -0,0 +1,595 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
+)
+
+// SQLiteIntrospector is the Introspector for SQLite.
+//
+// SQLite keeps no comments, constraint names or generation expressions in its catalog,
+// they are read from the original CREATE TABLE statement instead.
+type SQLiteIntrospector struct{}
+
+// DefaultSchema returns main, the schema of the main database file in SQLite.
+func (SQLiteIntrospector) DefaultSchema(*dburl.URL) string {
+	return "main"
+}
+
+// DriverName returns sqlite, the name of the pure Go driver modernc.org/sqlite, implements DriverNamer.
+func (SQLiteIntrospector) DriverName() string {
+	return "sqlite"
+}
+
+func (SQLiteIntrospector) ListTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	return ListSQLiteTables(ctx, db, schema)
+}
+
+func (SQLiteIntrospector) GetTable(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, error) {
+	t, ddl, err := getSQLiteTableInfo(ctx, db, schema, table)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	fillSQLiteTableComment(t, ddl)
+	return t, nil
+}
+
+func (SQLiteIntrospector) GetColumns(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	_, ddl, err := getSQLiteTableInfo(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := getSQLiteColumnsInfo(ctx, db, schema, table, ignoreColumns)
+	if err != nil {
+		return nil, err
+	}
+	fillSQLiteColumnComments(columns, ddl)
+	fillSQLiteValueGeneration(columns, ddl)
+	return columns, nil
+}
+
+func (SQLiteIntrospector) GetIndexes(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Index, error) {
+	return getSQLiteIndexesInfo(ctx, db, schema, table)
+}
+
+func (SQLiteIntrospector) GetForeignKeys(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	return getSQLiteForeignKeysInfo(ctx, db, schema, table)
+}
+
+func (SQLiteIntrospector) GetConstraints(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Constraint, error) {
+	_, ddl, err := getSQLiteTableInfo(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	return getSQLiteConstraintsInfo(ctx, db, schema, table, ddl)
+}
+
+// GenSQLiteTable generates a SQLite table and fills the Table structure.
+func GenSQLiteTable(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) (*model.Table, error) {
+	return GenTable(ctx, SQLiteIntrospector{}, db, schema, table, ignoreColumns)
+}
+
+// ListSQLiteTables lists the names of all tables and views in the given SQLite schema.
+func ListSQLiteTables(ctx context.Context, db *sql.DB, schema string) ([]string, error) {
+	const tablesSql = `
+		select name
+		from pragma_table_list
+		where schema = ? and type in ('table', 'view') and name not like 'sqlite_%'
+		order by name;
+	`
+	rows, err := db.QueryContext(ctx, tablesSql, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, nil
+}
+
+func getSQLiteTableInfo(ctx context.Context, db *sql.DB, schema, table string) (*model.Table, string, error) {
+	const tableSql = `
+		select tl.schema, tl.name, case tl.type when 'view' then 'view' else 'table' end, coalesce(m.sql, '')
+		from pragma_table_list as tl
+		left join sqlite_schema as m on m.name = tl.name and m.type = tl.type
+		where tl.schema = ? and tl.name = ? and tl.type in ('table', 'view');
+	`
+	var t model.Table
+	var ddl string
+	err := db.QueryRowContext(ctx, tableSql, schema, table).Scan(&t.Schema, &t.Name, &t.Kind, &ddl)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	return &t, ddl, nil
+}
+
+func getSQLiteColumnsInfo(ctx context.Context, db *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	const columnsSql = `
+		select cid + 1, name, type, "notnull" = 0 as is_nullable, dflt_value, pk > 0 as is_primary_key, hidden
+		from pragma_table_xinfo(?, ?)
+		where hidden <> 1
+		order by cid;
+	`
+	rows, err := db.QueryContext(ctx, columnsSql, table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var columns []*model.Column
+	for rows.Next() {
+		var col model.Column
+		var hidden int
+		if err := rows.Scan(&col.Ordinal, &col.Name, &col.Type, &col.IsNullable, &col.DefaultValue, &col.IsPrimaryKey, &hidden); err != nil {
+			return nil, err
+		}
+		fillSQLiteTypeDetails(&col)
+		fillSQLiteLogicalType(&col)
+		// hidden: 2 for virtual generated columns, 3 for stored generated columns
+		if hidden == 2 || hidden == 3 {
+			kind := "virtual"
+			if hidden == 3 {
+				kind = "stored"
+			}
+			col.IsGenerated = true
+			col.GenerationKind = &kind
+		}
+		if !slices.Contains(ignoreColumns, col.Name) {
+			columns = append(columns, &col)
+		}
+	}
+	return columns, nil
+}
+
+var sqliteTypeRegexp = regexp.MustCompile(`^([^(]*?)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?\s*$`)
+
+// fillSQLiteTypeDetails parses the declared column type (e.g. VARCHAR(64), DECIMAL(10,2), INT UNSIGNED) into the structured type details,
+// SQLite keeps the declared type as written, so it is the only source of them.
+func fillSQLiteTypeDetails(col *model.Column) {
+	matches := sqliteTypeRegexp.FindStringSubmatch(strings.TrimSpace(col.Type))
+	if matches == nil {
+		col.BaseType = strings.ToLower(strings.TrimSpace(col.Type))
+		return
+	}
+
+	var words []string
+	for _, w := range strings.Fields(strings.ToLower(matches[1])) {
+		if w == "unsigned" {
+			col.IsUnsigned = true
+			continue
+		}
+		words = append(words, w)
+	}
+	col.BaseType = strings.Join(words, " ")
+
+	if matches[2] == "" {
+		return
+	}
+	first, _ := strconv.Atoi(matches[2])
+	if col.BaseType == "decimal" || col.BaseType == "numeric" {
+		scale := 0
+		if matches[3] != "" {
+			scale, _ = strconv.Atoi(matches[3])
+		}
+		col.NumericPrecision = &first
+		col.NumericScale = &scale
+		return
+	}
+	col.MaxLength = &first
+}
+
+// sqliteLogicalTypes maps SQLite specific base types to logical types, see fillLogicalType.
+var sqliteLogicalTypes = map[string]string{
+	"integer": model.LogicalTypeInt64, // INTEGER columns are 64-bit, including the rowid
+	"float":   model.LogicalTypeFloat64,
+}
+
+// FillLogicalType implements LogicalTypeFiller.
+func (SQLiteIntrospector) FillLogicalType(col *model.Column) {
+	fillSQLiteLogicalType(col)
+}
+
+// fillSQLiteLogicalType sets the logical type of the column,
+// declared types without a well known name fall back to the SQLite type affinity rules.
+func fillSQLiteLogicalType(col *model.Column) {
+	fillLogicalType(col, sqliteLogicalTypes)
+	if col.LogicalType != model.LogicalTypeOther {
+		return
+	}
+	t := strings.ToUpper(col.BaseType)
+	switch {
+	case strings.Contains(t, "INT"):
+		col.LogicalType = model.LogicalTypeInt64
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		col.LogicalType = model.LogicalTypeText
+	case strings.Contains(t, "BLOB"), t == "":
+		col.LogicalType = model.LogicalTypeBinary
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		col.LogicalType = model.LogicalTypeFloat64
+	default:
+		col.LogicalType = model.LogicalTypeDecimal
+	}
+}
+
+func getSQLiteIndexesInfo(ctx context.Context, db *sql.DB, schema, table string) ([]*model.Index, error) {
+	const indexesSql = `
+		select il.name, il."unique" as is_unique, il.origin = 'pk' as is_primary,
+			   ii.seqno + 1 as ordinal, coalesce(ii.name, '') as column_name
+		from pragma_index_list(?, ?) as il
+		join pragma_index_info(il.name, ?) as ii
+		order by il.name, ii.seqno;
+	`
+	rows, err := db.QueryContext(ctx, indexesSql, table, schema, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	indexMap := make(map[string]*model.Index)
+	for rows.Next() {
+		var indexName, columnName string
+		var isUnique, isPrimary bool
+		var ordinal int
+
+		if err := rows.Scan(&indexName, &isUnique, &isPrimary, &ordinal, &columnName); err != nil {
+			return nil, err
+		}
+
+		if _, exists := indexMap[indexName]; !exists {
+			indexMap[indexName] = &model.Index{
+				Name:      indexName,
+				IsUnique:  isUnique,
+				IsPrimary: isPrimary,
+				Columns:   []*model.IndexColumn{},
+			}
+		}
+
+		indexMap[indexName].Columns = append(indexMap[indexName].Columns, &model.IndexColumn{
+			Ordinal: ordinal,
+			Name:    columnName,
+		})
+	}
+
+	indexes := make([]*model.Index, 0, len(indexMap))
+	for _, index := range indexMap {
+		indexes = append(indexes, index)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		if indexes[i].IsPrimary != indexes[j].IsPrimary {
+			return indexes[i].IsPrimary
+		}
+		if indexes[i].IsUnique != indexes[j].IsUnique {
+			return indexes[i].IsUnique
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+
+	return indexes, nil
+}
+
+var sqliteLineCommentRegexp = regexp.MustCompile(`^\s*["` + "`" + `\[]?(\w+)["` + "`" + `\]]?\s.*?--\s*(.*)$`)
+
+// fillSQLiteTableComment extracts the table comment from the original CREATE TABLE statement,
+// SQLite has no comment support, so a "-- comment" on the CREATE TABLE line is used as the table comment.
+func fillSQLiteTableComment(t *model.Table, ddl string) {
+	for _, line := range strings.Split(ddl, "\n") {
+		if strings.Contains(line, "--") && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(line)), "CREATE ") {
+			comment := strings.TrimSpace(line[strings.Index(line, "--")+2:])
+			if comment != "" {
+				t.Comment = &comment
+			}
+			return
+		}
+	}
+}
+
+// fillSQLiteColumnComments extracts column comments from the original CREATE TABLE statement,
+// a trailing "-- comment" on a column definition line is used as the column comment.
+func fillSQLiteColumnComments(columns []*model.Column, ddl string) {
+	for _, line := range strings.Split(ddl, "\n") {
+		if !strings.Contains(line, "--") || strings.HasPrefix(strings.ToUpper(strings.TrimSpace(line)), "CREATE ") {
+			continue
+		}
+		matches := sqliteLineCommentRegexp.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		for _, col := range columns {
+			if strings.EqualFold(col.Name, matches[1]) {
+				comment := strings.TrimSpace(matches[2])
+				if comment != "" {
+					col.Comment = &comment
+				}
+				break
+			}
+		}
+	}
+}
+
+func getSQLiteForeignKeysInfo(ctx context.Context, db *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	const foreignKeysSql = `
+		select id, "from", "table", coalesce("to", ''), on_delete, on_update
+		from pragma_foreign_key_list(?, ?)
+		order by id, seq;
+	`
+	rows, err := db.QueryContext(ctx, foreignKeysSql, table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	// SQLite does not keep foreign key constraint names, so the name stays empty
+	var foreignKeys []*model.ForeignKey
+	lastID := -1
+	for rows.Next() {
+		var id int
+		var column, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&id, &column, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return nil, err
+		}
+
+		if id != lastID {
+			lastID = id
+			foreignKeys = append(foreignKeys, &model.ForeignKey{
+				Schema:           schema,
+				Table:            table,
+				ReferencedSchema: schema,
+				ReferencedTable:  refTable,
+				OnDelete:         onDelete,
+				OnUpdate:         onUpdate,
+			})
+		}
+
+		fk := foreignKeys[len(foreignKeys)-1]
+		fk.Columns = append(fk.Columns, column)
+		fk.ReferencedColumns = append(fk.ReferencedColumns, refColumn)
+	}
+	return foreignKeys, nil
+}
+
+var (
+	sqliteAutoincrementRegexp = regexp.MustCompile(`(?i)\bAUTOINCREMENT\b`)
+	sqliteGeneratedAsRegexp   = regexp.MustCompile(`(?i)\bAS\s*\(`)
+	sqliteWithoutRowidRegexp  = regexp.MustCompile(`(?i)\)\s*WITHOUT\s+ROWID`)
+)
+
+// fillSQLiteValueGeneration fills the value generation info from the original CREATE TABLE statement.
+// A single INTEGER PRIMARY KEY column is an alias of the rowid, it is auto generated like AUTOINCREMENT columns.
+func fillSQLiteValueGeneration(columns []*model.Column, ddl string) {
+	definitions := make(map[string]string)
+	for _, def := range splitSQLiteDefinitions(ddl) {
+		fields := strings.Fields(def)
+		if len(fields) > 0 {
+			definitions[strings.ToLower(strings.Trim(fields[0], "\"`[]"))] = def
+		}
+	}
+
+	var pkColumns []*model.Column
+	for _, col := range columns {
+		if col.IsPrimaryKey {
+			pkColumns = append(pkColumns, col)
+		}
+	}
+	if len(pkColumns) == 1 && strings.EqualFold(pkColumns[0].Type, "INTEGER") && !sqliteWithoutRowidRegexp.MatchString(ddl) {
+		pkColumns[0].IsAutoIncrement = true
+	}
+
+	for _, col := range columns {
+		def := definitions[strings.ToLower(col.Name)]
+		if sqliteAutoincrementRegexp.MatchString(def) {
+			col.IsAutoIncrement = true
+		}
+		if col.IsGenerated {
+			if loc := sqliteGeneratedAsRegexp.FindStringIndex(def); loc != nil {
+				if expr, ok := extractParenthesized(def[loc[1]-1:]); ok {
+					col.GenerationExpression = &expr
+				}
+			}
+		}
+		fillValueGeneration(col)
+	}
+}
+
+// splitSQLiteDefinitions splits the body of a CREATE TABLE statement into column and constraint definitions,
+// comments are removed.
+func splitSQLiteDefinitions(ddl string) []string {
+	start := strings.Index(ddl, "(")
+	if start < 0 {
+		return nil
+	}
+
+	var defs []string
+	var cur strings.Builder
+	flush := func() {
+		if def := strings.TrimSpace(cur.String()); def != "" {
+			defs = append(defs, def)
+		}
+		cur.Reset()
+	}
+
+	depth := 0
+	for i := start + 1; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case c == '-' && strings.HasPrefix(ddl[i:], "--"):
+			for i < len(ddl) && ddl[i] != '\n' {
+				i++
+			}
+			cur.WriteByte(' ')
+			continue
+		case c == '/' && strings.HasPrefix(ddl[i:], "/*"):
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				return defs
+			}
+			i += end + 3
+			cur.WriteByte(' ')
+			continue
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(ddl[i+1:], closing)
+			if end < 0 {
+				return defs
+			}
+			cur.WriteString(ddl[i : i+end+2])
+			i += end + 1
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth == 0 {
+				flush()
+				return defs
+			}
+			depth--
+		case c == ',' && depth == 0:
+			flush()
+			continue
+		}
+		cur.WriteByte(c)
+	}
+	flush()
+	return defs
+}
+
+var (
+	sqliteCheckRegexp           = regexp.MustCompile(`(?i)(?:\bCONSTRAINT\s+["` + "`" + `\[]?(\w+)["` + "`" + `\]]?\s+)?\bCHECK\s*\(`)
+	sqliteTableConstraintRegexp = regexp.MustCompile(`(?i)^(CONSTRAINT|PRIMARY|UNIQUE|CHECK|FOREIGN)\b`)
+)
+
+// getSQLiteConstraintsInfo reads primary key and unique constraints from their automatic indexes,
+// and check constraints from the original CREATE TABLE statement.
+// SQLite does not keep the names of primary key and unique constraints, so their names stay empty.
+func getSQLiteConstraintsInfo(ctx context.Context, db *sql.DB, schema, table, ddl string) ([]*model.Constraint, error) {
+	// INTEGER PRIMARY KEY is an alias of the rowid and has no index, its columns come from the table info
+	const constraintsSql = `
+		select il.name, il.origin, coalesce(ii.name, ''), ii.seqno
+		from pragma_index_list(?, ?) as il
+		join pragma_index_info(il.name, ?) as ii
+		where il.origin in ('pk', 'u')
+		union all
+		select '', 'pk', ti.name, ti.pk
+		from pragma_table_info(?, ?) as ti
+		where ti.pk > 0
+		  and not exists (select 1 from pragma_index_list(?, ?) where origin = 'pk')
+		order by 1, 4;
+	`
+	rows, err := db.QueryContext(ctx, constraintsSql, table, schema, schema, table, schema, table, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			panic(err)
+		}
+	}(rows)
+
+	var constraints []*model.Constraint
+	lastIndexName := "-"
+	for rows.Next() {
+		var indexName, origin, column string
+		var seqno int
+		if err := rows.Scan(&indexName, &origin, &column, &seqno); err != nil {
+			return nil, err
+		}
+
+		if indexName != lastIndexName {
+			lastIndexName = indexName
+			c := &model.Constraint{Type: model.ConstraintTypeUnique}
+			if origin == "pk" {
+				c.Type = model.ConstraintTypePrimaryKey
+			}
+			if indexName != "" {
+				c.IndexName = &indexName
+			}
+			constraints = append(constraints, c)
+		}
+		c := constraints[len(constraints)-1]
+		c.Columns = append(c.Columns, column)
+	}
+
+	for _, def := range splitSQLiteDefinitions(ddl) {
+		for _, loc := range sqliteCheckRegexp.FindAllStringSubmatchIndex(def, -1) {
+			expr, ok := extractParenthesized(def[loc[1]-1:])
+			if !ok {
+				continue
+			}
+			c := &model.Constraint{
+				Type:            model.ConstraintTypeCheck,
+				CheckExpression: &expr,
+			}
+			if loc[2] >= 0 {
+				c.Name = def[loc[2]:loc[3]]
+			}
+			if !sqliteTableConstraintRegexp.MatchString(def) {
+				c.Columns = []string{strings.Trim(strings.Fields(def)[0], "\"`[]")}
+			}
+			constraints = append(constraints, c)
+		}
+	}
+	return constraints, nil
+}
+
+// extractParenthesized returns the content of the balanced parentheses at the beginning of s.
+func extractParenthesized(s string) (string, bool) {
+	if !strings.HasPrefix(s, "(") {
+		return "", false
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(s[1:i]), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,345 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestGenSQLiteTable(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`CREATE TABLE user ( -- User account information
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username VARCHAR(64) NOT NULL, -- Username, required
+		password VARCHAR(128) NOT NULL,
+		email VARCHAR(128) NOT NULL DEFAULT '', -- User email, required
+		first_name VARCHAR(64), -- First name of the user
+		last_name VARCHAR(64), -- Last name of the user
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP, -- Record creation timestamp
+		status VARCHAR(9) DEFAULT 'active', -- Account status
+		deleted_at TIMESTAMP, -- Record deletion timestamp
+		CONSTRAINT unique_email UNIQUE (email)
+	);
+	CREATE INDEX idx_name ON user (username);
+	CREATE INDEX idx_status_created ON user (status, created_at);
+	CREATE INDEX idx_full_name ON user (first_name, last_name);`)
+	require.NoError(t, err)
+
+	tb, err := GenSQLiteTable(context.Background(), db, "main", "user", []string{"deleted_at"})
+	require.NoError(t, err)
+
+	assert.NotNil(t, tb)
+	assert.Equal(t, "main", tb.Schema)
+	assert.Equal(t, "user", tb.Name)
+	assert.Equal(t, "table", tb.Kind)
+	assert.Equal(t, "User account information", *tb.Comment)
+
+	assert.Equal(t, 8, len(tb.Columns))
+	assert.Equal(t, "id", tb.Columns[0].Name)
+	assert.Equal(t, 1, tb.Columns[0].Ordinal)
+	assert.Equal(t, "INTEGER", tb.Columns[0].Type)
+	assert.Equal(t, true, tb.Columns[0].IsPrimaryKey)
+	assert.Equal(t, false, tb.Columns[1].IsPrimaryKey)
+	assert.Equal(t, false, tb.Columns[1].IsNullable)
+	assert.Equal(t, "Username, required", *tb.Columns[1].Comment)
+	assert.Equal(t, "varchar", tb.Columns[1].BaseType)
+	assert.Equal(t, 64, *tb.Columns[1].MaxLength)
+	assert.Equal(t, true, tb.Columns[0].IsAutoIncrement)
+	assert.Equal(t, true, tb.Columns[0].IsDatabaseGenerated)
+	assert.Equal(t, false, tb.Columns[1].IsDatabaseGenerated)
+	assert.Nil(t, tb.Columns[2].Comment)
+	assert.Equal(t, "''", *tb.Columns[3].DefaultValue)
+	assert.Equal(t, "first_name", tb.Columns[4].Name)
+	assert.Equal(t, true, tb.Columns[4].IsNullable)
+
+	assert.Equal(t, 4, len(tb.Indexes))
+	assert.Equal(t, true, tb.Indexes[0].IsUnique) // Unique constraint
+	assert.Equal(t, "email", tb.Indexes[0].Columns[0].Name)
+	assert.Equal(t, "idx_full_name", tb.Indexes[1].Name)
+	assert.Equal(t, "first_name", tb.Indexes[1].Columns[0].Name)
+	assert.Equal(t, "last_name", tb.Indexes[1].Columns[1].Name)
+	assert.Equal(t, "idx_name", tb.Indexes[2].Name)
+	assert.Equal(t, "idx_status_created", tb.Indexes[3].Name)
+	assert.Equal(t, "status", tb.Indexes[3].Columns[0].Name)
+	assert.Equal(t, 2, tb.Indexes[3].Columns[1].Ordinal)
+}
+
+func TestGenSQLiteTable_whenTableNotExists_thenReturnNil(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	tb, err := GenSQLiteTable(context.Background(), db, "main", "not_exists", nil)
+	require.NoError(t, err)
+	assert.Nil(t, tb)
+}
+
+func TestGenSQLiteTable_whenTableHasForeignKeys(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`CREATE TABLE user (
+		id INTEGER PRIMARY KEY,
+		tenant_id INTEGER NOT NULL,
+		UNIQUE (tenant_id, id)
+	);
+	CREATE TABLE role (
+		id INTEGER PRIMARY KEY
+	);
+	CREATE TABLE user_role (
+		tenant_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role_id INTEGER,
+		FOREIGN KEY (tenant_id, user_id) REFERENCES user (tenant_id, id) ON DELETE CASCADE,
+		FOREIGN KEY (role_id) REFERENCES role (id) ON DELETE SET NULL ON UPDATE RESTRICT
+	);`)
+	require.NoError(t, err)
+
+	tb, err := GenSQLiteTable(context.Background(), db, "main", "user_role", nil)
+	require.NoError(t, err)
+
+	assert.Len(t, tb.ForeignKeys, 2)
+	var userFk, roleFk = tb.ForeignKeys[0], tb.ForeignKeys[1]
+	if userFk.ReferencedTable != "user" {
+		userFk, roleFk = roleFk, userFk
+	}
+	assert.Equal(t, "user_role", userFk.Table)
+	assert.Equal(t, "main", userFk.ReferencedSchema)
+	assert.Equal(t, "user", userFk.ReferencedTable)
+	assert.Equal(t, []string{"tenant_id", "user_id"}, userFk.Columns)
+	assert.Equal(t, []string{"tenant_id", "id"}, userFk.ReferencedColumns)
+	assert.Equal(t, "CASCADE", userFk.OnDelete)
+	assert.Equal(t, "NO ACTION", userFk.OnUpdate)
+	assert.Equal(t, "role", roleFk.ReferencedTable)
+	assert.Equal(t, []string{"role_id"}, roleFk.Columns)
+	assert.Equal(t, "SET NULL", roleFk.OnDelete)
+	assert.Equal(t, "RESTRICT", roleFk.OnUpdate)
+}
+
+func TestListSQLiteTables(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`CREATE TABLE user (id INTEGER PRIMARY KEY AUTOINCREMENT);
+	CREATE TABLE order_item (id INTEGER PRIMARY KEY);
+	CREATE VIEW v_user AS SELECT id FROM user;`)
+	require.NoError(t, err)
+
+	tables, err := ListSQLiteTables(context.Background(), db, "main")
+	require.NoError(t, err)
+
+	// sqlite_sequence is an internal table
+	assert.Equal(t, []string{"order_item", "user", "v_user"}, tables)
+}
+
+func TestGenSQLiteTable_whenRelationIsView(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`CREATE TABLE user (id INTEGER PRIMARY KEY, username VARCHAR(64) NOT NULL, deleted_at TIMESTAMP);
+	CREATE VIEW active_user AS SELECT id, username FROM user WHERE deleted_at IS NULL;`)
+	require.NoError(t, err)
+
+	tb, err := GenSQLiteTable(context.Background(), db, "main", "active_user", nil)
+	require.NoError(t, err)
+
+	assert.NotNil(t, tb)
+	assert.Equal(t, "active_user", tb.Name)
+	assert.Equal(t, "view", tb.Kind)
+	assert.Equal(t, 2, len(tb.Columns))
+	assert.Equal(t, "username", tb.Columns[1].Name)
+	assert.Equal(t, "VARCHAR(64)", tb.Columns[1].Type)
+	assert.Empty(t, tb.Indexes)
+}
+
+func Test_fillSQLiteTypeDetails(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	tests := []struct {
+		name string
+		typ  string
+		want model.Column
+	}{
+		{name: "no modifiers", typ: "INTEGER", want: model.Column{BaseType: "integer"}},
+		{name: "empty type", typ: "", want: model.Column{BaseType: ""}},
+		{name: "length", typ: "VARCHAR(64)", want: model.Column{BaseType: "varchar", MaxLength: intPtr(64)}},
+		{name: "precision and scale", typ: "DECIMAL(10, 2)", want: model.Column{BaseType: "decimal", NumericPrecision: intPtr(10), NumericScale: intPtr(2)}},
+		{name: "precision only", typ: "NUMERIC(8)", want: model.Column{BaseType: "numeric", NumericPrecision: intPtr(8), NumericScale: intPtr(0)}},
+		{name: "unsigned", typ: "UNSIGNED BIG INT", want: model.Column{BaseType: "big int", IsUnsigned: true}},
+		{name: "multiple words with length", typ: "CHARACTER VARYING(255)", want: model.Column{BaseType: "character varying", MaxLength: intPtr(255)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			col := &model.Column{Type: tt.typ}
+			fillSQLiteTypeDetails(col)
+			tt.want.Type = tt.typ
+			assert.Equal(t, &tt.want, col)
+		})
+	}
+}
+
+func Test_fillSQLiteLogicalType(t *testing.T) {
+	tests := []struct {
+		typ  string
+		want string
+	}{
+		{typ: "INTEGER", want: model.LogicalTypeInt64},
+		{typ: "INT", want: model.LogicalTypeInt32},
+		{typ: "VARCHAR(64)", want: model.LogicalTypeString},
+		{typ: "TEXT", want: model.LogicalTypeText},
+		{typ: "DATETIME", want: model.LogicalTypeTimestamp},
+		{typ: "BOOLEAN", want: model.LogicalTypeBool},
+		{typ: "UNSIGNED BIG INT", want: model.LogicalTypeInt64},    // affinity: INT
+		{typ: "NATIVE CHARACTER(70)", want: model.LogicalTypeText}, // affinity: TEXT
+		{typ: "", want: model.LogicalTypeBinary},                   // affinity: BLOB
+		{typ: "DOUBLE PRECISION", want: model.LogicalTypeFloat64},  // well known name
+		{typ: "FLOAT8", want: model.LogicalTypeFloat64},            // affinity: REAL
+		{typ: "FLOATING POINT", want: model.LogicalTypeInt64},      // affinity: INT, as in SQLite
+		{typ: "MONEY", want: model.LogicalTypeDecimal},             // affinity: NUMERIC
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			col := &model.Column{Type: tt.typ}
+			fillSQLiteTypeDetails(col)
+			fillSQLiteLogicalType(col)
+			assert.Equal(t, tt.want, col.LogicalType)
+		})
+	}
+}
+
+func TestGenSQLiteTable_whenColumnsAreGenerated(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`CREATE TABLE product (
+		id INTEGER PRIMARY KEY, -- rowid alias, (not AUTOINCREMENT)
+		price DECIMAL(10, 2) NOT NULL,
+		qty INT NOT NULL DEFAULT 0,
+		total DECIMAL(12, 2) GENERATED ALWAYS AS (price * qty) STORED,
+		label TEXT AS (printf('%s x %d', 'item', qty)) -- virtual by default
+	);
+	CREATE TABLE product_tag (
+		product_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (product_id, tag)
+	);`)
+	require.NoError(t, err)
+
+	tb, err := GenSQLiteTable(context.Background(), db, "main", "product", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, len(tb.Columns))
+	assert.Equal(t, true, tb.Columns[0].IsAutoIncrement)
+	assert.Equal(t, true, tb.Columns[0].IsDatabaseGenerated)
+	assert.Equal(t, false, tb.Columns[2].IsDatabaseGenerated)
+	assert.Equal(t, true, tb.Columns[3].IsGenerated)
+	assert.Equal(t, "stored", *tb.Columns[3].GenerationKind)
+	assert.Equal(t, "price * qty", *tb.Columns[3].GenerationExpression)
+	assert.Equal(t, true, tb.Columns[3].IsDatabaseGenerated)
+	assert.Equal(t, "label", tb.Columns[4].Name)
+	assert.Equal(t, "virtual", *tb.Columns[4].GenerationKind)
+	assert.Equal(t, "printf('%s x %d', 'item', qty)", *tb.Columns[4].GenerationExpression)
+
+	tb, err = GenSQLiteTable(context.Background(), db, "main", "product_tag", nil)
+	require.NoError(t, err)
+
+	// composite primary key is not a rowid alias
+	assert.Equal(t, false, tb.Columns[0].IsAutoIncrement)
+	assert.Equal(t, false, tb.Columns[0].IsDatabaseGenerated)
+}
+
+func TestGenSQLiteTable_whenTableHasConstraints(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`CREATE TABLE product (
+		id INTEGER PRIMARY KEY,
+		sku TEXT NOT NULL UNIQUE,
+		price DECIMAL(10, 2) NOT NULL CHECK (price > 0),
+		discount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+		CONSTRAINT ck_discount CHECK (discount >= 0 AND discount < price)
+	);
+	CREATE TABLE product_tag (
+		product_id INTEGER NOT NULL,
+		tag TEXT NOT NULL,
+		PRIMARY KEY (product_id, tag)
+	);`)
+	require.NoError(t, err)
+
+	tb, err := GenSQLiteTable(context.Background(), db, "main", "product", nil)
+	require.NoError(t, err)
+
+	assert.Len(t, tb.Constraints, 4)
+	assert.Equal(t, "primary_key", tb.Constraints[0].Type)
+	assert.Equal(t, []string{"id"}, tb.Constraints[0].Columns)
+	assert.Nil(t, tb.Constraints[0].IndexName) // rowid alias has no index
+	assert.Equal(t, "unique", tb.Constraints[1].Type)
+	assert.Equal(t, []string{"sku"}, tb.Constraints[1].Columns)
+	assert.Equal(t, tb.Indexes[0].Name, *tb.Constraints[1].IndexName)
+	assert.Equal(t, "check", tb.Constraints[2].Type)
+	assert.Equal(t, "", tb.Constraints[2].Name)
+	assert.Equal(t, []string{"price"}, tb.Constraints[2].Columns)
+	assert.Equal(t, "price > 0", *tb.Constraints[2].CheckExpression)
+	assert.Equal(t, "ck_discount", tb.Constraints[3].Name)
+	assert.Equal(t, []string{"discount", "price"}, tb.Constraints[3].Columns)
+	assert.Equal(t, "discount >= 0 AND discount < price", *tb.Constraints[3].CheckExpression)
+
+	tb, err = GenSQLiteTable(context.Background(), db, "main", "product_tag", nil)
+	require.NoError(t, err)
+
+	assert.Len(t, tb.Constraints, 1)
+	assert.Equal(t, "primary_key", tb.Constraints[0].Type)
+	assert.Equal(t, []string{"product_id", "tag"}, tb.Constraints[0].Columns)
+	assert.Equal(t, tb.Indexes[0].Name, *tb.Constraints[0].IndexName)
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
+)
+
+// StaticIntrospector serves tables that are already loaded into memory, e.g. parsed from DDL files,
+// the db connection passed to its methods is ignored and can be nil.
+type StaticIntrospector struct {
+	Schema string // Default schema
+	Tables []*model.Table
+}
+
+func (s StaticIntrospector) DefaultSchema(*dburl.URL) string {
+	return s.Schema
+}
+
+func (s StaticIntrospector) ListTables(_ context.Context, _ *sql.DB, schema string) ([]string, error) {
+	var tables []string
+	for _, t := range s.Tables {
+		if strings.EqualFold(t.Schema, schema) {
+			tables = append(tables, t.Name)
+		}
+	}
+	return tables, nil
+}
+
+func (s StaticIntrospector) GetTable(_ context.Context, _ *sql.DB, schema, table string) (*model.Table, error) {
+	t := s.find(schema, table)
+	if t == nil {
+		return nil, nil
+	}
+	return &model.Table{Name: t.Name, Schema: t.Schema, Kind: t.Kind, Comment: t.Comment}, nil
+}
+
+func (s StaticIntrospector) GetColumns(_ context.Context, _ *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	t := s.find(schema, table)
+	if t == nil {
+		return nil, nil
+	}
+	var columns []*model.Column
+	for _, col := range t.Columns {
+		if slices.Contains(ignoreColumns, col.Name) {
+			continue
+		}
+		c := *col
+		columns = append(columns, &c)
+	}
+	return columns, nil
+}
+
+func (s StaticIntrospector) GetIndexes(_ context.Context, _ *sql.DB, schema, table string) ([]*model.Index, error) {
+	t := s.find(schema, table)
+	if t == nil {
+		return nil, nil
+	}
+	var indexes []*model.Index
+	if t.Indexes != nil {
+		indexes = make([]*model.Index, 0, len(t.Indexes)) // Keep empty and nil apart like the source
+	}
+	for _, idx := range t.Indexes {
+		i := *idx
+		indexes = append(indexes, &i)
+	}
+	return indexes, nil
+}
+
+func (s StaticIntrospector) GetForeignKeys(_ context.Context, _ *sql.DB, schema, table string) ([]*model.ForeignKey, error) {
+	t := s.find(schema, table)
+	if t == nil {
+		return nil, nil
+	}
+	var foreignKeys []*model.ForeignKey
+	if t.ForeignKeys != nil {
+		foreignKeys = make([]*model.ForeignKey, 0, len(t.ForeignKeys))
+	}
+	for _, fk := range t.ForeignKeys {
+		f := *fk
+		foreignKeys = append(foreignKeys, &f)
+	}
+	return foreignKeys, nil
+}
+
+func (s StaticIntrospector) GetConstraints(_ context.Context, _ *sql.DB, schema, table string) ([]*model.Constraint, error) {
+	t := s.find(schema, table)
+	if t == nil {
+		return nil, nil
+	}
+	var constraints []*model.Constraint
+	if t.Constraints != nil {
+		constraints = make([]*model.Constraint, 0, len(t.Constraints))
+	}
+	for _, c := range t.Constraints {
+		cc := *c
+		constraints = append(constraints, &cc)
+	}
+	return constraints, nil
+}
+
+func (s StaticIntrospector) find(schema, table string) *model.Table {
+	for _, t := range s.Tables {
+		if strings.EqualFold(t.Schema, schema) && strings.EqualFold(t.Name, table) {
+			return t
+		}
+	}
+	return nil
+}
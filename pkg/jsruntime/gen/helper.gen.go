@@ -112,4 +112,44 @@ Handlebars.registerHelper('_removeSuffix', function (s, suffix) {
     }
     return s.endsWith(suffix) ? s.slice(0, -suffix.length) : s;
 });
+
+// _typeOf returns the type of the column (or routine parameter) in the target language,
+// the type set by typeMappings first, then the type catalog entry of dialect:baseType, of the logical type, or of other.
+// Nullable columns get the nullable variant, {{_typeOf 'go' this nullable=false}} forces the not null one.
+// Types are not HTML escaped, e.g. List<Long>.
+Handlebars.registerHelper('_typeOf', function (target, column, options) {
+    if (hasUndefinedOrNull([target, column])) {
+        return undefined;
+    }
+    return new Handlebars.SafeString(typeOf(target, column, options));
+});
+
+// typeCatalog is the built-in type catalog with the overrides of the config, set by gencoder before rendering.
+var typeCatalog = {};
+
+function typeOf(target, column, options) {
+    if (column.types && column.types[target]) {
+        return column.types[target];
+    }
+    const catalog = typeCatalog[target];
+    if (!catalog) {
+        throw new Error('unknown type catalog target: ' + target);
+    }
+    const root = options.data.root;
+    const dialect = root.database ? root.database.dialect : undefined;
+    const entryOf = function (logicalType) {
+        return catalog[dialect + ':' + column.baseType] || catalog[logicalType] || catalog['other'] || {type: ''};
+    };
+    const variantOf = function (entry, nullable) {
+        return nullable && entry.nullable ? entry.nullable : entry.type;
+    };
+    const nullable = isUndefinedOrNull(options.hash.nullable) ? column.isNullable === true : options.hash.nullable;
+    if (column.logicalType !== 'array') {
+        return variantOf(entryOf(column.logicalType), nullable);
+    }
+    const element = entryOf(column.elementLogicalType);
+    return variantOf(catalog['array'] || {type: '{type}'}, nullable)
+        .split('{type}').join(element.type)
+        .split('{nullable}').join(element.nullable || element.type);
+}
 `
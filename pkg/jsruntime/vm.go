@@ -1,6 +1,7 @@
 package jsruntime
 
 import (
+	"encoding/json"
 	"github.com/DanielLiu1123/gencoder/pkg/jsruntime/gen"
 	"github.com/dop251/goja"
 	"log"
@@ -56,3 +57,31 @@ func RunJS(js string) {
 		log.Fatalf("Error running JS: %v", err)
 	}
 }
+
+// SetGlobal sets a global variable of the shared runtime
+func SetGlobal(name string, value goja.Value) {
+	if err := GetVM().Set(name, value); err != nil {
+		log.Fatalf("Error setting global %s: %v", name, err)
+	}
+}
+
+// ToValue converts a value to a plain JS object of the shared runtime through its JSON form,
+// values shared by many renders are converted once rather than with every render context.
+func ToValue(v any) goja.Value {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Fatalf("Error converting value to JSON: %v", err)
+	}
+
+	vm := GetVM()
+	parse, ok := goja.AssertFunction(vm.Get("JSON").ToObject(vm).Get("parse"))
+	if !ok {
+		log.Fatal("Error getting 'JSON.parse' function")
+	}
+
+	value, err := parse(goja.Undefined(), vm.ToValue(string(b)))
+	if err != nil {
+		log.Fatalf("Error parsing JSON: %v", err)
+	}
+	return value
+}
@@ -0,0 +1,222 @@
+package model
+
+import "strings"
+
+// TypeCatalogEntry is the type of a target language for a logical type, or for a dialect:baseType key, e.g. postgres:inet.
+type TypeCatalogEntry struct {
+	Type     string `json:"type" yaml:"type" jsonschema:"description=The type of not null columns,example=int64,required"`
+	Nullable string `json:"nullable,omitempty" yaml:"nullable,omitempty" jsonschema:"description=The type of nullable columns\\, defaults to type,example=*int64"`
+	Import   string `json:"import,omitempty" yaml:"import,omitempty" jsonschema:"description=The import or package the type requires,example=time"`
+}
+
+// TypeCatalog holds the type entries per target language, keyed by logical type or dialect:baseType.
+// The array entry wraps the element type, {type} and {nullable} are replaced by the variants of the element entry.
+type TypeCatalog map[string]map[string]*TypeCatalogEntry
+
+// BuiltinTypeCatalog returns a new copy of the built-in catalog for go, java, kotlin, ts, python, csharp and rust.
+func BuiltinTypeCatalog() TypeCatalog {
+	return TypeCatalog{
+		"go":     goTypes(),
+		"java":   javaTypes(),
+		"kotlin": kotlinTypes(),
+		"ts":     tsTypes(),
+		"python": pythonTypes(),
+		"csharp": csharpTypes(),
+		"rust":   rustTypes(),
+	}
+}
+
+// nullableTypes returns entries whose nullable variant is the type formatted with the nullable pattern, %s is the type.
+func nullableTypes(pattern string, types map[string][2]string) map[string]*TypeCatalogEntry {
+	entries := make(map[string]*TypeCatalogEntry, len(types))
+	for key, t := range types {
+		entries[key] = &TypeCatalogEntry{Type: t[0], Nullable: strings.ReplaceAll(pattern, "%s", t[0]), Import: t[1]}
+	}
+	return entries
+}
+
+func goTypes() map[string]*TypeCatalogEntry {
+	entries := nullableTypes("*%s", map[string][2]string{
+		LogicalTypeString:      {"string"},
+		LogicalTypeText:        {"string"},
+		LogicalTypeInt8:        {"int8"},
+		LogicalTypeInt16:       {"int16"},
+		LogicalTypeInt32:       {"int32"},
+		LogicalTypeInt64:       {"int64"},
+		LogicalTypeDecimal:     {"decimal.Decimal", "github.com/shopspring/decimal"},
+		LogicalTypeFloat32:     {"float32"},
+		LogicalTypeFloat64:     {"float64"},
+		LogicalTypeBool:        {"bool"},
+		LogicalTypeDate:        {"time.Time", "time"},
+		LogicalTypeTime:        {"time.Time", "time"},
+		LogicalTypeTimestamp:   {"time.Time", "time"},
+		LogicalTypeTimestamptz: {"time.Time", "time"},
+		LogicalTypeUUID:        {"uuid.UUID", "github.com/google/uuid"},
+		LogicalTypeEnum:        {"string"},
+		"postgres:inet":        {"netip.Addr", "net/netip"},
+		"postgres:cidr":        {"netip.Prefix", "net/netip"},
+	})
+	// nil is the null of slices and interfaces
+	entries[LogicalTypeJSON] = &TypeCatalogEntry{Type: "json.RawMessage", Nullable: "json.RawMessage", Import: "encoding/json"}
+	entries[LogicalTypeBinary] = &TypeCatalogEntry{Type: "[]byte", Nullable: "[]byte"}
+	entries[LogicalTypeArray] = &TypeCatalogEntry{Type: "[]{type}", Nullable: "[]{type}"}
+	entries[LogicalTypeOther] = &TypeCatalogEntry{Type: "any", Nullable: "any"}
+	return entries
+}
+
+func javaTypes() map[string]*TypeCatalogEntry {
+	boxed := func(primitive, box string) *TypeCatalogEntry {
+		return &TypeCatalogEntry{Type: primitive, Nullable: box}
+	}
+	entries := nullableTypes("%s", map[string][2]string{
+		LogicalTypeString:      {"String"},
+		LogicalTypeText:        {"String"},
+		LogicalTypeDecimal:     {"BigDecimal", "java.math.BigDecimal"},
+		LogicalTypeDate:        {"LocalDate", "java.time.LocalDate"},
+		LogicalTypeTime:        {"LocalTime", "java.time.LocalTime"},
+		LogicalTypeTimestamp:   {"LocalDateTime", "java.time.LocalDateTime"},
+		LogicalTypeTimestamptz: {"OffsetDateTime", "java.time.OffsetDateTime"},
+		LogicalTypeUUID:        {"UUID", "java.util.UUID"},
+		LogicalTypeJSON:        {"String"},
+		LogicalTypeBinary:      {"byte[]"},
+		LogicalTypeArray:       {"List<{nullable}>", "java.util.List"},
+		LogicalTypeEnum:        {"String"},
+		LogicalTypeOther:       {"Object"},
+	})
+	entries[LogicalTypeInt8] = boxed("byte", "Byte")
+	entries[LogicalTypeInt16] = boxed("short", "Short")
+	entries[LogicalTypeInt32] = boxed("int", "Integer")
+	entries[LogicalTypeInt64] = boxed("long", "Long")
+	entries[LogicalTypeFloat32] = boxed("float", "Float")
+	entries[LogicalTypeFloat64] = boxed("double", "Double")
+	entries[LogicalTypeBool] = boxed("boolean", "Boolean")
+	return entries
+}
+
+func kotlinTypes() map[string]*TypeCatalogEntry {
+	return nullableTypes("%s?", map[string][2]string{
+		LogicalTypeString:      {"String"},
+		LogicalTypeText:        {"String"},
+		LogicalTypeInt8:        {"Byte"},
+		LogicalTypeInt16:       {"Short"},
+		LogicalTypeInt32:       {"Int"},
+		LogicalTypeInt64:       {"Long"},
+		LogicalTypeDecimal:     {"BigDecimal", "java.math.BigDecimal"},
+		LogicalTypeFloat32:     {"Float"},
+		LogicalTypeFloat64:     {"Double"},
+		LogicalTypeBool:        {"Boolean"},
+		LogicalTypeDate:        {"LocalDate", "java.time.LocalDate"},
+		LogicalTypeTime:        {"LocalTime", "java.time.LocalTime"},
+		LogicalTypeTimestamp:   {"LocalDateTime", "java.time.LocalDateTime"},
+		LogicalTypeTimestamptz: {"OffsetDateTime", "java.time.OffsetDateTime"},
+		LogicalTypeUUID:        {"UUID", "java.util.UUID"},
+		LogicalTypeJSON:        {"String"},
+		LogicalTypeBinary:      {"ByteArray"},
+		LogicalTypeArray:       {"List<{type}>"},
+		LogicalTypeEnum:        {"String"},
+		LogicalTypeOther:       {"Any"},
+	})
+}
+
+func tsTypes() map[string]*TypeCatalogEntry {
+	entries := nullableTypes("%s | null", map[string][2]string{
+		LogicalTypeString:      {"string"},
+		LogicalTypeText:        {"string"},
+		LogicalTypeInt8:        {"number"},
+		LogicalTypeInt16:       {"number"},
+		LogicalTypeInt32:       {"number"},
+		LogicalTypeInt64:       {"number"},
+		LogicalTypeDecimal:     {"string"}, // keeps the precision
+		LogicalTypeFloat32:     {"number"},
+		LogicalTypeFloat64:     {"number"},
+		LogicalTypeBool:        {"boolean"},
+		LogicalTypeDate:        {"Date"},
+		LogicalTypeTime:        {"string"},
+		LogicalTypeTimestamp:   {"Date"},
+		LogicalTypeTimestamptz: {"Date"},
+		LogicalTypeUUID:        {"string"},
+		LogicalTypeJSON:        {"unknown"},
+		LogicalTypeBinary:      {"Uint8Array"},
+		LogicalTypeEnum:        {"string"},
+		LogicalTypeOther:       {"unknown"},
+	})
+	entries[LogicalTypeArray] = &TypeCatalogEntry{Type: "{type}[]", Nullable: "{type}[] | null"}
+	return entries
+}
+
+func pythonTypes() map[string]*TypeCatalogEntry {
+	return nullableTypes("%s | None", map[string][2]string{
+		LogicalTypeString:      {"str"},
+		LogicalTypeText:        {"str"},
+		LogicalTypeInt8:        {"int"},
+		LogicalTypeInt16:       {"int"},
+		LogicalTypeInt32:       {"int"},
+		LogicalTypeInt64:       {"int"},
+		LogicalTypeDecimal:     {"Decimal", "from decimal import Decimal"},
+		LogicalTypeFloat32:     {"float"},
+		LogicalTypeFloat64:     {"float"},
+		LogicalTypeBool:        {"bool"},
+		LogicalTypeDate:        {"date", "from datetime import date"},
+		LogicalTypeTime:        {"time", "from datetime import time"},
+		LogicalTypeTimestamp:   {"datetime", "from datetime import datetime"},
+		LogicalTypeTimestamptz: {"datetime", "from datetime import datetime"},
+		LogicalTypeUUID:        {"UUID", "from uuid import UUID"},
+		LogicalTypeJSON:        {"Any", "from typing import Any"},
+		LogicalTypeBinary:      {"bytes"},
+		LogicalTypeArray:       {"list[{type}]"},
+		LogicalTypeEnum:        {"str"},
+		LogicalTypeOther:       {"Any", "from typing import Any"},
+		"postgres:interval":    {"timedelta", "from datetime import timedelta"},
+	})
+}
+
+func csharpTypes() map[string]*TypeCatalogEntry {
+	return nullableTypes("%s?", map[string][2]string{
+		LogicalTypeString:      {"string"},
+		LogicalTypeText:        {"string"},
+		LogicalTypeInt8:        {"sbyte"},
+		LogicalTypeInt16:       {"short"},
+		LogicalTypeInt32:       {"int"},
+		LogicalTypeInt64:       {"long"},
+		LogicalTypeDecimal:     {"decimal"},
+		LogicalTypeFloat32:     {"float"},
+		LogicalTypeFloat64:     {"double"},
+		LogicalTypeBool:        {"bool"},
+		LogicalTypeDate:        {"DateOnly"},
+		LogicalTypeTime:        {"TimeOnly"},
+		LogicalTypeTimestamp:   {"DateTime"},
+		LogicalTypeTimestamptz: {"DateTimeOffset"},
+		LogicalTypeUUID:        {"Guid"},
+		LogicalTypeJSON:        {"JsonDocument", "System.Text.Json"},
+		LogicalTypeBinary:      {"byte[]"},
+		LogicalTypeArray:       {"{type}[]"},
+		LogicalTypeEnum:        {"string"},
+		LogicalTypeOther:       {"object"},
+	})
+}
+
+func rustTypes() map[string]*TypeCatalogEntry {
+	return nullableTypes("Option<%s>", map[string][2]string{
+		LogicalTypeString:      {"String"},
+		LogicalTypeText:        {"String"},
+		LogicalTypeInt8:        {"i8"},
+		LogicalTypeInt16:       {"i16"},
+		LogicalTypeInt32:       {"i32"},
+		LogicalTypeInt64:       {"i64"},
+		LogicalTypeDecimal:     {"Decimal", "rust_decimal::Decimal"},
+		LogicalTypeFloat32:     {"f32"},
+		LogicalTypeFloat64:     {"f64"},
+		LogicalTypeBool:        {"bool"},
+		LogicalTypeDate:        {"NaiveDate", "chrono::NaiveDate"},
+		LogicalTypeTime:        {"NaiveTime", "chrono::NaiveTime"},
+		LogicalTypeTimestamp:   {"NaiveDateTime", "chrono::NaiveDateTime"},
+		LogicalTypeTimestamptz: {"DateTime<Utc>", "chrono::{DateTime, Utc}"},
+		LogicalTypeUUID:        {"Uuid", "uuid::Uuid"},
+		LogicalTypeJSON:        {"serde_json::Value"},
+		LogicalTypeBinary:      {"Vec<u8>"},
+		LogicalTypeArray:       {"Vec<{type}>"},
+		LogicalTypeEnum:        {"String"},
+		LogicalTypeOther:       {"String"},
+		"postgres:interval":    {"PgInterval", "sqlx::postgres::types::PgInterval"},
+	})
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinTypeCatalog_whenTargetIsBuiltin_thenShouldCoverAllLogicalTypes(t *testing.T) {
+	logicalTypes := []string{
+		LogicalTypeString, LogicalTypeText, LogicalTypeInt8, LogicalTypeInt16, LogicalTypeInt32, LogicalTypeInt64,
+		LogicalTypeDecimal, LogicalTypeFloat32, LogicalTypeFloat64, LogicalTypeBool, LogicalTypeDate, LogicalTypeTime,
+		LogicalTypeTimestamp, LogicalTypeTimestamptz, LogicalTypeUUID, LogicalTypeJSON, LogicalTypeBinary,
+		LogicalTypeArray, LogicalTypeEnum, LogicalTypeOther,
+	}
+
+	catalog := BuiltinTypeCatalog()
+
+	assert.ElementsMatch(t, []string{"go", "java", "kotlin", "ts", "python", "csharp", "rust"}, mapKeys(catalog))
+	for target, entries := range catalog {
+		for _, lt := range logicalTypes {
+			if assert.Contains(t, entries, lt, "%s has no entry for %s", target, lt) {
+				assert.NotEmpty(t, entries[lt].Type, "%s.%s", target, lt)
+				assert.NotEmpty(t, entries[lt].Nullable, "%s.%s", target, lt)
+			}
+		}
+	}
+}
+
+func TestBuiltinTypeCatalog_whenColumnIsNullable_thenShouldHaveNullableVariant(t *testing.T) {
+	catalog := BuiltinTypeCatalog()
+
+	assert.Equal(t, &TypeCatalogEntry{Type: "int64", Nullable: "*int64"}, catalog["go"][LogicalTypeInt64])
+	assert.Equal(t, &TypeCatalogEntry{Type: "int", Nullable: "Integer"}, catalog["java"][LogicalTypeInt32])
+	assert.Equal(t, &TypeCatalogEntry{Type: "Int", Nullable: "Int?"}, catalog["kotlin"][LogicalTypeInt32])
+	assert.Equal(t, &TypeCatalogEntry{Type: "number", Nullable: "number | null"}, catalog["ts"][LogicalTypeFloat64])
+	assert.Equal(t, &TypeCatalogEntry{Type: "datetime", Nullable: "datetime | None", Import: "from datetime import datetime"}, catalog["python"][LogicalTypeTimestamp])
+	assert.Equal(t, &TypeCatalogEntry{Type: "Guid", Nullable: "Guid?"}, catalog["csharp"][LogicalTypeUUID])
+	assert.Equal(t, &TypeCatalogEntry{Type: "i32", Nullable: "Option<i32>"}, catalog["rust"][LogicalTypeInt32])
+}
+
+func TestBuiltinTypeCatalog_whenCalledTwice_thenShouldReturnNewCopy(t *testing.T) {
+	catalog := BuiltinTypeCatalog()
+	catalog["go"][LogicalTypeInt64].Type = "int"
+
+	assert.Equal(t, "int64", BuiltinTypeCatalog()["go"][LogicalTypeInt64].Type)
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
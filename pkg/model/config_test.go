@@ -60,3 +60,19 @@ func TestConfig_GetHelpers(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_GetTypeCatalog(t *testing.T) {
+	config := Config{
+		TypeCatalog: TypeCatalog{
+			"go":    {LogicalTypeInt64: {Type: "int64", Nullable: "sql.NullInt64", Import: "database/sql"}},
+			"swift": {LogicalTypeInt64: {Type: "Int64", Nullable: "Int64?"}},
+		},
+	}
+
+	catalog := config.GetTypeCatalog()
+
+	assert.Equal(t, &TypeCatalogEntry{Type: "int64", Nullable: "sql.NullInt64", Import: "database/sql"}, catalog["go"][LogicalTypeInt64])
+	assert.Equal(t, &TypeCatalogEntry{Type: "int32", Nullable: "*int32"}, catalog["go"][LogicalTypeInt32])
+	assert.Equal(t, &TypeCatalogEntry{Type: "Int64", Nullable: "Int64?"}, catalog["swift"][LogicalTypeInt64])
+	assert.Equal(t, &TypeCatalogEntry{Type: "long", Nullable: "Long"}, catalog["java"][LogicalTypeInt64])
+}
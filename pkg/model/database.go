@@ -0,0 +1,9 @@
+package model
+
+// Database is the whole-database view of a render context, with all resolved tables of its DatabaseConfig.
+type Database struct {
+	Name     string            `json:"name" yaml:"name"`         // Name of the DatabaseConfig
+	Dialect  string            `json:"dialect" yaml:"dialect"`   // dburl driver name, e.g. mysql, postgres, sqlserver, sqlite3, empty if unknown
+	Tables   []*Table          `json:"tables" yaml:"tables"`     // Ordered by schema and name
+	TableMap map[string]*Table `json:"tableMap" yaml:"tableMap"` // Keyed by name, or by schema.name for names used in several schemas
+}
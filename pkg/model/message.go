@@ -0,0 +1,51 @@
+package model
+
+// Kinds of MessageField.
+const (
+	MessageFieldKindScalar  = "scalar"
+	MessageFieldKindEnum    = "enum"
+	MessageFieldKindMessage = "message"
+	MessageFieldKindMap     = "map"
+)
+
+// Message is a protobuf message, tables read from .proto files carry the message they are generated from.
+type Message struct {
+	Name     string          `json:"name" yaml:"name"`
+	FullName string          `json:"fullName" yaml:"fullName"` // Qualified with the package and the enclosing messages, e.g. shop.v1.Order.Item
+	Package  string          `json:"package" yaml:"package"`
+	File     string          `json:"file" yaml:"file"`
+	Syntax   string          `json:"syntax" yaml:"syntax"` // proto2, proto3 or the edition, e.g. 2023
+	Comment  *string         `json:"comment" yaml:"comment"`
+	Fields   []*MessageField `json:"fields" yaml:"fields"`
+	Enums    []*MessageEnum  `json:"enums" yaml:"enums"`       // Enums declared in the message
+	Messages []*Message      `json:"messages" yaml:"messages"` // Messages declared in the message
+}
+
+type MessageField struct {
+	Name         string  `json:"name" yaml:"name"`
+	Number       int     `json:"number" yaml:"number"`
+	Type         string  `json:"type" yaml:"type"`                 // Type as declared, e.g. int64, Order.Item, google.protobuf.Timestamp
+	TypeFullName string  `json:"typeFullName" yaml:"typeFullName"` // Resolved name of enum and message types, e.g. shop.v1.Order.Item
+	Kind         string  `json:"kind" yaml:"kind"`                 // scalar, enum, message, map
+	Label        string  `json:"label" yaml:"label"`               // optional, required, repeated or empty
+	IsRepeated   bool    `json:"isRepeated" yaml:"isRepeated"`
+	IsOptional   bool    `json:"isOptional" yaml:"isOptional"` // The field tracks presence, e.g. proto3 optional, message fields and oneof members
+	OneOf        *string `json:"oneOf" yaml:"oneOf"`           // Name of the enclosing oneof
+	MapKeyType   *string `json:"mapKeyType" yaml:"mapKeyType"`
+	MapValueType *string `json:"mapValueType" yaml:"mapValueType"`
+	DefaultValue *string `json:"defaultValue" yaml:"defaultValue"` // proto2 [default = ...]
+	Comment      *string `json:"comment" yaml:"comment"`
+}
+
+type MessageEnum struct {
+	Name     string              `json:"name" yaml:"name"`
+	FullName string              `json:"fullName" yaml:"fullName"`
+	Comment  *string             `json:"comment" yaml:"comment"`
+	Values   []*MessageEnumValue `json:"values" yaml:"values"`
+}
+
+type MessageEnumValue struct {
+	Name    string  `json:"name" yaml:"name"`
+	Number  int     `json:"number" yaml:"number"`
+	Comment *string `json:"comment" yaml:"comment"`
+}
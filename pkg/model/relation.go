@@ -0,0 +1,37 @@
+package model
+
+// Types of Relation.
+const (
+	RelationTypeManyToOne  = "many_to_one"
+	RelationTypeOneToMany  = "one_to_many"
+	RelationTypeOneToOne   = "one_to_one"
+	RelationTypeManyToMany = "many_to_many"
+)
+
+// Sources of Relation.
+const (
+	RelationSourceForeignKey = "foreign_key" // Foreign key of the database
+	RelationSourceDeclared   = "declared"    // Declared in the relations of the table config
+	RelationSourceInferred   = "inferred"    // Inferred from the column naming convention, e.g. user_id -> user.id
+)
+
+// Relation is an association from a table to another, every relation is also set on the referenced table in the opposite direction.
+type Relation struct {
+	Name              string           `json:"name" yaml:"name"`                           // Name of the association, e.g. user for user_id, posts for a one-to-many to post
+	Type              string           `json:"type" yaml:"type"`                           // many_to_one, one_to_many, one_to_one, many_to_many
+	Source            string           `json:"source" yaml:"source"`                       // foreign_key, declared, inferred
+	IsInverse         bool             `json:"isInverse" yaml:"isInverse"`                 // Opposite direction of a relation of the referenced table
+	Columns           []string         `json:"columns" yaml:"columns"`                     // Columns of this table
+	ReferencedSchema  string           `json:"referencedSchema" yaml:"referencedSchema"`   // Schema of the other table
+	ReferencedTable   string           `json:"referencedTable" yaml:"referencedTable"`     // Name of the other table
+	ReferencedColumns []string         `json:"referencedColumns" yaml:"referencedColumns"` // Columns of the other table matching Columns
+	Through           *RelationThrough `json:"through" yaml:"through"`                     // Join table of many-to-many relations
+}
+
+// RelationThrough is the join table of a many-to-many relation.
+type RelationThrough struct {
+	Schema            string   `json:"schema" yaml:"schema"`
+	Table             string   `json:"table" yaml:"table"`
+	Columns           []string `json:"columns" yaml:"columns"`                     // Columns of the join table referencing Relation.Columns
+	ReferencedColumns []string `json:"referencedColumns" yaml:"referencedColumns"` // Columns of the join table referencing Relation.ReferencedColumns
+}
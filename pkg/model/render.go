@@ -5,11 +5,17 @@ import (
 )
 
 type RenderContext struct {
-	Table          *Table            `json:"table" yaml:"table"`
-	Properties     map[string]string `json:"properties" yaml:"properties"` // Merged properties
+	Table          *Table            `json:"table" yaml:"table"`                         // Nil for routine contexts
+	Routine        *Routine          `json:"routine,omitempty" yaml:"routine,omitempty"` // Procedure or function of routine contexts
+	Message        *Message          `json:"message,omitempty" yaml:"message,omitempty"` // Protobuf message of tables read from .proto files
+	Database       *Database         `json:"-" yaml:"-"`                                 // All tables of the database of the context, shared by the contexts and attached to templates as database
+	AllTables      []*Table          `json:"-" yaml:"-"`                                 // All tables of all databases, shared by the contexts and attached to templates as allTables
+	Tables         []*Table          `json:"tables,omitempty" yaml:"tables,omitempty"`   // Tables of database and global templates, those of the database or of all databases
+	Properties     map[string]string `json:"properties" yaml:"properties"`               // Merged properties
 	Config         *Config           `json:"config" yaml:"config"`
 	DatabaseConfig *DatabaseConfig   `json:"databaseConfig" yaml:"databaseConfig"`
 	TableConfig    *TableConfig      `json:"tableConfig" yaml:"tableConfig"`
+	RoutineConfig  *RoutineConfig    `json:"routineConfig,omitempty" yaml:"routineConfig,omitempty"`
 }
 
 type FileType int
@@ -20,11 +26,20 @@ const (
 	FileTypeTemplate
 )
 
+// Scopes of template files, what a template is rendered for.
+const (
+	FileScopeTable    = "table"    // Once per table
+	FileScopeRoutine  = "routine"  // Once per procedure or function
+	FileScopeDatabase = "database" // Once per database config
+	FileScopeGlobal   = "global"   // Once
+)
+
 type File struct {
 	Name         string
 	RelativePath string
 	Content      []byte
 	Type         FileType
 	Output       string     // for Template FileType
+	Scope        string     // for Template FileType, see FileScopeTable
 	Template     goja.Value // for Template/Partial FileType
 }
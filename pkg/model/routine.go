@@ -0,0 +1,36 @@
+package model
+
+// Kinds of Routine.
+const (
+	RoutineKindProcedure = "procedure"
+	RoutineKindFunction  = "function"
+)
+
+// Modes of RoutineParameter.
+const (
+	ParameterModeIn       = "in"
+	ParameterModeOut      = "out"
+	ParameterModeInOut    = "inout"
+	ParameterModeVariadic = "variadic"
+)
+
+// Routine is a stored procedure or function.
+type Routine struct {
+	Name          string              `json:"name" yaml:"name"`
+	Schema        string              `json:"schema" yaml:"schema"`
+	SpecificName  string              `json:"specificName" yaml:"specificName"` // Unique name among overloads, e.g. PostgreSQL add_12345
+	Kind          string              `json:"kind" yaml:"kind"`                 // procedure or function
+	Comment       *string             `json:"comment" yaml:"comment"`
+	Parameters    []*RoutineParameter `json:"parameters" yaml:"parameters"`
+	ReturnType    *string             `json:"returnType" yaml:"returnType"`       // Return type of a function, e.g. integer, SETOF users, TABLE(id integer), nil for procedures
+	ResultColumns []*Column           `json:"resultColumns" yaml:"resultColumns"` // Columns of the result set of table-valued functions and procedures, if known
+}
+
+type RoutineParameter struct {
+	Name         string  `json:"name" yaml:"name"` // Empty for unnamed parameters
+	Ordinal      int     `json:"ordinal" yaml:"ordinal"`
+	Mode         string  `json:"mode" yaml:"mode"` // in, out, inout, variadic
+	Type         string  `json:"type" yaml:"type"`
+	LogicalType  string  `json:"logicalType" yaml:"logicalType"`
+	DefaultValue *string `json:"defaultValue" yaml:"defaultValue"`
+}
@@ -1,21 +1,127 @@
 package model
 
+// Kinds of Table, templates can use it to skip write operations for read-only relations.
+const (
+	TableKindTable            = "table"
+	TableKindView             = "view"
+	TableKindMaterializedView = "materialized_view"
+	TableKindForeignTable     = "foreign_table"
+	TableKindPartitionedTable = "partitioned_table"
+)
+
 type Table struct {
-	Name    string    `json:"name" yaml:"name"`
-	Schema  string    `json:"schema" yaml:"schema"`
-	Comment *string   `json:"comment" yaml:"comment"`
-	Columns []*Column `json:"columns" yaml:"columns"`
-	Indexes []*Index  `json:"indexes" yaml:"indexes"`
+	Name         string              `json:"name" yaml:"name"`
+	Schema       string              `json:"schema" yaml:"schema"`
+	Kind         string              `json:"kind" yaml:"kind"` // table, view, materialized_view, foreign_table, partitioned_table
+	Comment      *string             `json:"comment" yaml:"comment"`
+	Description  *string             `json:"description" yaml:"description"` // Comment without annotations
+	Annotations  map[string]string   `json:"annotations" yaml:"annotations"` // Annotations parsed from the comment, e.g. @json(userName) @mask
+	Columns      []*Column           `json:"columns" yaml:"columns"`
+	Indexes      []*Index            `json:"indexes" yaml:"indexes"`           // Empty if the table has no index
+	Constraints  []*Constraint       `json:"constraints" yaml:"constraints"`   // Primary key, unique, check and exclusion constraints, nil if there is none
+	ForeignKeys  []*ForeignKey       `json:"foreignKeys" yaml:"foreignKeys"`   // Nil if there is none
+	ReferencedBy []*ForeignKey       `json:"referencedBy" yaml:"referencedBy"` // Foreign keys of other configured tables that reference this table
+	Relations    []*Relation         `json:"relations" yaml:"relations"`       // Associations with the configured tables from foreign keys, declared and inferred relations
+	Imports      map[string][]string `json:"imports" yaml:"imports"`           // Sorted imports required by the column types per target language of the type mappings
+	Names        *Names              `json:"names" yaml:"names"`               // Name variants computed by the naming strategy
+}
+
+// Names are the variants of a table or column name computed by the naming strategy,
+// after stripping prefixes and suffixes and, for tables if enabled, singularizing the last word.
+type Names struct {
+	Pascal   string `json:"pascal" yaml:"pascal"`     // e.g. UserAccount
+	Camel    string `json:"camel" yaml:"camel"`       // e.g. userAccount
+	Snake    string `json:"snake" yaml:"snake"`       // e.g. user_account
+	Kebab    string `json:"kebab" yaml:"kebab"`       // e.g. user-account
+	Plural   string `json:"plural" yaml:"plural"`     // Snake case with the last word in plural, e.g. user_accounts
+	Singular string `json:"singular" yaml:"singular"` // Snake case with the last word in singular, e.g. user_account
 }
 
 type Column struct {
-	Name         string  `json:"name" yaml:"name"`
-	Ordinal      int     `json:"ordinal" yaml:"ordinal"`
-	Type         string  `json:"type" yaml:"type"`
-	IsNullable   bool    `json:"isNullable" yaml:"isNullable"`
-	DefaultValue *string `json:"defaultValue" yaml:"defaultValue"`
-	IsPrimaryKey bool    `json:"isPrimaryKey" yaml:"isPrimaryKey"`
-	Comment      *string `json:"comment" yaml:"comment"`
+	Name         string            `json:"name" yaml:"name"`
+	Ordinal      int               `json:"ordinal" yaml:"ordinal"`
+	Type         string            `json:"type" yaml:"type"` // Raw type as reported by the database, e.g. varchar(255)
+	IsNullable   bool              `json:"isNullable" yaml:"isNullable"`
+	DefaultValue *string           `json:"defaultValue" yaml:"defaultValue"`
+	IsPrimaryKey bool              `json:"isPrimaryKey" yaml:"isPrimaryKey"`
+	Comment      *string           `json:"comment" yaml:"comment"`
+	Description  *string           `json:"description" yaml:"description"` // Comment without annotations
+	Annotations  map[string]string `json:"annotations" yaml:"annotations"` // Annotations parsed from the comment, e.g. @json(userName) @mask
+
+	// Structured type details
+	BaseType         string  `json:"baseType" yaml:"baseType"`                 // Lowercase type name without modifiers, element type for arrays, e.g. varchar
+	MaxLength        *int    `json:"maxLength" yaml:"maxLength"`               // Declared max length in characters (bytes for binary types), nil if unbounded
+	NumericPrecision *int    `json:"numericPrecision" yaml:"numericPrecision"` // Precision of decimal/numeric types
+	NumericScale     *int    `json:"numericScale" yaml:"numericScale"`         // Scale of decimal/numeric types
+	IsUnsigned       bool    `json:"isUnsigned" yaml:"isUnsigned"`
+	Charset          *string `json:"charset" yaml:"charset"`
+	Collation        *string `json:"collation" yaml:"collation"`
+	ArrayDimensions  int     `json:"arrayDimensions" yaml:"arrayDimensions"` // 0 if the column is not an array
+
+	// Value generation
+	IsAutoIncrement      bool    `json:"isAutoIncrement" yaml:"isAutoIncrement"`           // MySQL auto_increment, SQLite AUTOINCREMENT or rowid alias
+	IsIdentity           bool    `json:"isIdentity" yaml:"isIdentity"`                     // PostgreSQL GENERATED ... AS IDENTITY, MSSQL IDENTITY
+	IdentityGeneration   *string `json:"identityGeneration" yaml:"identityGeneration"`     // always or by_default
+	SequenceName         *string `json:"sequenceName" yaml:"sequenceName"`                 // Sequence backing a serial, identity or sequence default
+	IsGenerated          bool    `json:"isGenerated" yaml:"isGenerated"`                   // Computed/generated column
+	GenerationExpression *string `json:"generationExpression" yaml:"generationExpression"` // Expression of the generated column
+	GenerationKind       *string `json:"generationKind" yaml:"generationKind"`             // stored or virtual
+	IsDatabaseGenerated  bool    `json:"isDatabaseGenerated" yaml:"isDatabaseGenerated"`   // Value is generated by the database, usually excluded from INSERT statements
+
+	EnumValues []string  `json:"enumValues" yaml:"enumValues"` // Labels of MySQL enum/set columns and PostgreSQL enum types, nil for other columns
+	UserType   *UserType `json:"userType" yaml:"userType"`     // User-defined type of the column (element type for arrays), nil for built-in types
+
+	LogicalType        string  `json:"logicalType" yaml:"logicalType"`               // Dialect independent type, e.g. string, int64, timestamptz, array
+	ElementLogicalType *string `json:"elementLogicalType" yaml:"elementLogicalType"` // Logical type of the elements when LogicalType is array
+
+	Types      map[string]string `json:"types" yaml:"types"`           // Type per target language of the type mappings, e.g. java: String
+	Names      *Names            `json:"names" yaml:"names"`           // Name variants computed by the naming strategy
+	Properties map[string]string `json:"properties" yaml:"properties"` // Properties of the column config
+}
+
+// Logical types of Column, the same logical type is used for equivalent types of all databases.
+const (
+	LogicalTypeString      = "string" // Bounded character types, e.g. varchar(255), char(3)
+	LogicalTypeText        = "text"   // Unbounded character types, e.g. text, varchar(max)
+	LogicalTypeInt8        = "int8"
+	LogicalTypeInt16       = "int16"
+	LogicalTypeInt32       = "int32"
+	LogicalTypeInt64       = "int64"
+	LogicalTypeDecimal     = "decimal"
+	LogicalTypeFloat32     = "float32"
+	LogicalTypeFloat64     = "float64"
+	LogicalTypeBool        = "bool"
+	LogicalTypeDate        = "date"
+	LogicalTypeTime        = "time"
+	LogicalTypeTimestamp   = "timestamp"   // Date and time without time zone
+	LogicalTypeTimestamptz = "timestamptz" // Date and time with time zone or offset
+	LogicalTypeUUID        = "uuid"
+	LogicalTypeJSON        = "json"
+	LogicalTypeBinary      = "binary"
+	LogicalTypeArray       = "array"
+	LogicalTypeEnum        = "enum"
+	LogicalTypeOther       = "other" // Types without a portable equivalent, e.g. geometry, interval, composite types
+)
+
+// Kinds of UserType.
+const (
+	UserTypeKindEnum      = "enum"
+	UserTypeKindDomain    = "domain"
+	UserTypeKindComposite = "composite"
+)
+
+type UserType struct {
+	Schema     string               `json:"schema" yaml:"schema"`
+	Name       string               `json:"name" yaml:"name"`
+	Kind       string               `json:"kind" yaml:"kind"`             // enum, domain, composite
+	BaseType   *string              `json:"baseType" yaml:"baseType"`     // Underlying type of a domain, e.g. character varying(255)
+	EnumValues []string             `json:"enumValues" yaml:"enumValues"` // Labels of an enum, or of the enum a domain is based on
+	Attributes []*UserTypeAttribute `json:"attributes" yaml:"attributes"` // Attributes of a composite type
+}
+
+type UserTypeAttribute struct {
+	Name string `json:"name" yaml:"name"`
+	Type string `json:"type" yaml:"type"`
 }
 
 type Index struct {
@@ -29,3 +135,32 @@ type IndexColumn struct {
 	Ordinal int    `json:"ordinal" yaml:"ordinal"`
 	Name    string `json:"name" yaml:"name"`
 }
+
+// Types of Constraint.
+const (
+	ConstraintTypePrimaryKey = "primary_key"
+	ConstraintTypeUnique     = "unique"
+	ConstraintTypeCheck      = "check"
+	ConstraintTypeExclusion  = "exclusion"
+)
+
+type Constraint struct {
+	Name            string   `json:"name" yaml:"name"`
+	Type            string   `json:"type" yaml:"type"` // primary_key, unique, check, exclusion
+	Columns         []string `json:"columns" yaml:"columns"`
+	CheckExpression *string  `json:"checkExpression" yaml:"checkExpression"` // Expression of a check constraint, e.g. (price > 0)
+	IndexName       *string  `json:"indexName" yaml:"indexName"`             // Name of the index backing a primary key, unique or exclusion constraint
+}
+
+type ForeignKey struct {
+	Name              string   `json:"name" yaml:"name"`
+	Schema            string   `json:"schema" yaml:"schema"` // Schema of the referencing table
+	Table             string   `json:"table" yaml:"table"`   // Name of the referencing table
+	Columns           []string `json:"columns" yaml:"columns"`
+	ReferencedSchema  string   `json:"referencedSchema" yaml:"referencedSchema"`
+	ReferencedTable   string   `json:"referencedTable" yaml:"referencedTable"`
+	ReferencedColumns []string `json:"referencedColumns" yaml:"referencedColumns"`
+	OnDelete          string   `json:"onDelete" yaml:"onDelete"` // NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT
+	OnUpdate          string   `json:"onUpdate" yaml:"onUpdate"` // NO ACTION, RESTRICT, CASCADE, SET NULL, SET DEFAULT
+	IsDeferrable      bool     `json:"isDeferrable" yaml:"isDeferrable"`
+}
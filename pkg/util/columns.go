@@ -0,0 +1,41 @@
+package util
+
+import (
+	"log"
+	"maps"
+	"slices"
+
+	"github.com/DanielLiu1123/gencoder/pkg/db"
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// applyColumnConfigs applies the column overrides of the table config to the table,
+// a type override is declared in the dialect of the database, its details and logical type are derived again.
+func applyColumnConfigs(tbCfg *model.TableConfig, dialect string, table *model.Table) {
+	for _, col := range table.Columns {
+		col.Properties = make(map[string]string)
+		colCfg := tbCfg.Columns[col.Name]
+		if colCfg == nil {
+			continue
+		}
+		for k, v := range colCfg.Properties {
+			col.Properties[k] = v
+		}
+		if colCfg.Type != "" {
+			db.RetypeColumn(col, colCfg.Type, dialect)
+		}
+		if colCfg.Comment != nil {
+			col.Comment = colCfg.Comment
+		}
+		if colCfg.Nullable != nil {
+			col.IsNullable = *colCfg.Nullable
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(tbCfg.Columns)) {
+		ignored := slices.Contains(tbCfg.IgnoreColumns, name)
+		if !ignored && !slices.ContainsFunc(table.Columns, func(c *model.Column) bool { return c.Name == name }) {
+			log.Printf("column %s.%s of the table config not found, skipping", table.Name, name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_applyColumnConfigs(t *testing.T) {
+	comment, no := "Display name @mask", false
+	tbCfg := &model.TableConfig{
+		Name: "user",
+		Columns: map[string]*model.ColumnConfig{
+			"name": {
+				Properties: map[string]string{"validation": "notBlank"},
+				Type:       "varchar(64)",
+				Comment:    &comment,
+				Nullable:   &no,
+			},
+		},
+	}
+	table := &model.Table{
+		Name: "user",
+		Columns: []*model.Column{
+			{Name: "id", Type: "bigint", BaseType: "bigint", LogicalType: model.LogicalTypeInt64},
+			{Name: "name", Type: "text", BaseType: "text", IsNullable: true, LogicalType: model.LogicalTypeText},
+		},
+	}
+
+	applyColumnConfigs(tbCfg, "mysql", table)
+
+	assert.Empty(t, table.Columns[0].Properties)
+	name := table.Columns[1]
+	assert.Equal(t, map[string]string{"validation": "notBlank"}, name.Properties)
+	assert.Equal(t, "varchar(64)", name.Type)
+	assert.Equal(t, 64, *name.MaxLength)
+	assert.Equal(t, model.LogicalTypeString, name.LogicalType)
+	assert.Equal(t, &comment, name.Comment)
+	assert.False(t, name.IsNullable)
+}
+
+func Test_mergeColumnConfig(t *testing.T) {
+	yes := true
+	base := &model.ColumnConfig{Properties: map[string]string{"a": "1", "b": "1"}, Type: "text", LogicalName: "title"}
+	override := &model.ColumnConfig{Properties: map[string]string{"b": "2"}, Nullable: &yes}
+
+	merged := mergeColumnConfig(base, override)
+
+	assert.Equal(t, &model.ColumnConfig{
+		Properties:  map[string]string{"a": "1", "b": "2"},
+		Type:        "text",
+		LogicalName: "title",
+		Nullable:    &yes,
+	}, merged)
+}
+
+func TestCollectRenderContexts_whenColumnsAreConfigured_thenShouldApplyOverrides(t *testing.T) {
+	ddl := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(ddl, []byte(`
+CREATE TABLE t_user (id BIGINT PRIMARY KEY, usr_nm TEXT NOT NULL, status TEXT);
+COMMENT ON COLUMN t_user.status IS 'Status';`), 0644))
+
+	comment, no := "Account status @enum(UserStatus)", false
+	cfg := &model.Config{
+		TypeMappings: map[string][]*model.TypeMapping{
+			"java": {{LogicalType: model.LogicalTypeString, Type: "String"}, {LogicalType: model.LogicalTypeText, Type: "Clob"}},
+		},
+		Databases: []*model.DatabaseConfig{
+			{
+				Dialect: "postgres",
+				DDL:     []string{ddl},
+				Tables: []*model.TableConfig{
+					{Name: "t_*", Columns: map[string]*model.ColumnConfig{"status": {Properties: map[string]string{"audit": "true"}}}},
+					{
+						Name: "t_user",
+						Columns: map[string]*model.ColumnConfig{
+							"usr_nm": {LogicalName: "userName"},
+							"status": {Type: "varchar(16)", Comment: &comment, Nullable: &no},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	table := contexts[0].Table
+	usrNm, status := table.Columns[1], table.Columns[2]
+	assert.Equal(t, "usr_nm", usrNm.Name)
+	assert.Equal(t, "userName", usrNm.Names.Camel)
+	assert.Equal(t, "character varying(16)", status.Type)
+	assert.Equal(t, "String", status.Types["java"])
+	assert.False(t, status.IsNullable)
+	assert.Equal(t, "Account status", *status.Description)
+	assert.Equal(t, map[string]string{"enum": "UserStatus"}, status.Annotations)
+	assert.Equal(t, map[string]string{"audit": "true"}, status.Properties)
+}
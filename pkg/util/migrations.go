@@ -0,0 +1,159 @@
+package util
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/db"
+)
+
+// migration is one migration script of a Flyway, golang-migrate or goose migrations directory.
+type migration struct {
+	file       string
+	version    string // Empty for Flyway repeatable migrations, which run after all versioned ones
+	down       bool   // Down (undo) migrations are never applied
+	goose      bool   // The up statements are in the "-- +goose Up" section
+	notSQL     bool   // Go migrations of goose can not be replayed
+	repeatable bool
+}
+
+var (
+	flywayFileRegexp  = regexp.MustCompile(`^([VUR])([0-9._]*)__(.+)\.sql$`)
+	migrateFileRegexp = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+	gooseFileRegexp   = regexp.MustCompile(`^([0-9]+)_(.+)\.(sql|go)$`)
+	versionPartRegexp = regexp.MustCompile(`[0-9]+`)
+)
+
+// readMigrations lists the migrations in the directory (recursively) in the order they are applied.
+func readMigrations(dir string) ([]*migration, error) {
+	var migrations []*migration
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if m := parseMigrationFile(path); m != nil {
+			migrations = append(migrations, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(migrations, func(i, j int) bool {
+		a, b := migrations[i], migrations[j]
+		if a.repeatable != b.repeatable {
+			return b.repeatable
+		}
+		if c := compareVersions(a.version, b.version); c != 0 {
+			return c < 0
+		}
+		return filepath.Base(a.file) < filepath.Base(b.file)
+	})
+	return migrations, nil
+}
+
+// parseMigrationFile recognizes the file name conventions, nil if the file is not a migration.
+func parseMigrationFile(path string) *migration {
+	name := filepath.Base(path)
+	if m := flywayFileRegexp.FindStringSubmatch(name); m != nil {
+		return &migration{file: path, version: m[2], down: m[1] == "U", repeatable: m[1] == "R"}
+	}
+	if m := migrateFileRegexp.FindStringSubmatch(name); m != nil {
+		return &migration{file: path, version: m[1], down: m[3] == "down"}
+	}
+	if m := gooseFileRegexp.FindStringSubmatch(name); m != nil {
+		return &migration{file: path, version: m[1], goose: true, notSQL: m[3] == "go"}
+	}
+	return nil
+}
+
+// compareVersions compares versions like 1.2.10 or 20240101120000 part by part numerically.
+func compareVersions(a, b string) int {
+	pa, pb := versionPartRegexp.FindAllString(a, -1), versionPartRegexp.FindAllString(b, -1)
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		x, y := strings.TrimLeft(pa[i], "0"), strings.TrimLeft(pb[i], "0")
+		if len(x) != len(y) {
+			return len(x) - len(y)
+		}
+		if c := strings.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+// gooseUpSection returns the statements of the "-- +goose Up" section of a goose migration.
+func gooseUpSection(content string) string {
+	var sb strings.Builder
+	up := !strings.Contains(content, "+goose Up")
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			up = true
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			up = false
+			continue
+		}
+		if up {
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// applyMigrations replays the migrations of the directory on the schema,
+// down migrations and the statements that do not change tables are skipped with a warning.
+func applyMigrations(schema *db.DDLSchema, dir string) {
+	migrations, err := readMigrations(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		log.Fatalf("no migrations found in %s", dir)
+	}
+
+	for _, m := range migrations {
+		if m.down {
+			log.Printf("skipping down migration %s", m.file)
+			continue
+		}
+		if m.notSQL {
+			log.Printf("skipping Go migration %s", m.file)
+			continue
+		}
+
+		b, err := os.ReadFile(m.file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		content := string(b)
+		if m.goose {
+			content = gooseUpSection(content)
+		}
+
+		skipped, err := schema.Exec(content)
+		if err != nil {
+			log.Fatal(fmt.Errorf("%s: %w", m.file, err))
+		}
+		for _, statement := range skipped {
+			log.Printf("skipping statement in %s: %s", m.file, firstLine(statement))
+		}
+	}
+}
+
+func firstLine(s string) string {
+	line, _, found := strings.Cut(strings.TrimSpace(s), "\n")
+	if found {
+		return strings.TrimSpace(line) + " ..."
+	}
+	return line
+}
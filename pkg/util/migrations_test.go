@@ -0,0 +1,143 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func Test_readMigrations(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "flyway",
+			files: []string{"V1.10__c.sql", "R__views.sql", "V1.2__b.sql", "V1__a.sql", "U1.2__b.sql", "README.md"},
+			want:  []string{"V1__a.sql", "U1.2__b.sql", "V1.2__b.sql", "V1.10__c.sql", "R__views.sql"},
+		},
+		{
+			name:  "golang-migrate",
+			files: []string{"000002_b.up.sql", "000001_a.down.sql", "000001_a.up.sql", "000010_c.up.sql"},
+			want:  []string{"000001_a.down.sql", "000001_a.up.sql", "000002_b.up.sql", "000010_c.up.sql"},
+		},
+		{
+			name:  "goose",
+			files: []string{"20240102000000_b.go", "20240101000000_a.sql", "sub/20231231000000_init.sql"},
+			want:  []string{"20231231000000_init.sql", "20240101000000_a.sql", "20240102000000_b.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := make(map[string]string)
+			for _, f := range tt.files {
+				files[f] = ""
+			}
+			writeFiles(t, dir, files)
+
+			migrations, err := readMigrations(dir)
+			require.NoError(t, err)
+
+			var got []string
+			for _, m := range migrations {
+				got = append(got, filepath.Base(m.file))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_gooseUpSection(t *testing.T) {
+	content := `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE a (id int);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE a;
+`
+	assert.Equal(t, "-- +goose StatementBegin\nCREATE TABLE a (id int);\n-- +goose StatementEnd\n\n", gooseUpSection(content))
+	assert.Equal(t, "CREATE TABLE a (id int);", gooseUpSection("CREATE TABLE a (id int);"))
+}
+
+func TestCollectRenderContexts_whenMigrationsAreConfigured_thenShouldReplayThem(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"000001_init.up.sql": `
+			CREATE TABLE "user" (id BIGSERIAL PRIMARY KEY, name VARCHAR(32) NOT NULL, legacy TEXT);
+			CREATE TABLE tmp (id INT);
+			INSERT INTO tmp VALUES (1);`,
+		"000001_init.down.sql": `DROP TABLE "user";`,
+		"000002_rename.up.sql": `
+			ALTER TABLE "user" RENAME COLUMN name TO username;
+			ALTER TABLE "user" DROP COLUMN legacy;
+			DROP TABLE tmp;`,
+	})
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Name:       "testdb",
+				Dialect:    "postgres",
+				Migrations: dir,
+				Tables:     []*model.TableConfig{{Name: "*"}},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	tb := contexts[0].Table
+	assert.Equal(t, "public", tb.Schema)
+	assert.Equal(t, "user", tb.Name)
+	require.Len(t, tb.Columns, 2)
+	assert.Equal(t, "username", tb.Columns[1].Name)
+	assert.Equal(t, "character varying(32)", tb.Columns[1].Type)
+}
+
+func TestCollectRenderContexts_whenMySQLMigrationsPositionColumns_thenShouldKeepColumnOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"V1__init.sql": `CREATE TABLE u (id INT PRIMARY KEY, name VARCHAR(32));`,
+		"V2__position.sql": `
+			ALTER TABLE u ADD COLUMN z INT AFTER id;
+			ALTER TABLE u ADD f INT FIRST;
+			ALTER TABLE u MODIFY name VARCHAR(64) NOT NULL AFTER f;
+			ALTER TABLE u CHANGE COLUMN z zz BIGINT FIRST;`,
+	})
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{Dialect: "mysql", Migrations: dir, Tables: []*model.TableConfig{{Name: "u"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	var names []string
+	var ordinals []int
+	for _, col := range contexts[0].Table.Columns {
+		names = append(names, col.Name)
+		ordinals = append(ordinals, col.Ordinal)
+	}
+	assert.Equal(t, []string{"zz", "f", "name", "id"}, names)
+	assert.Equal(t, []int{1, 2, 3, 4}, ordinals)
+	assert.Equal(t, "varchar(64)", contexts[0].Table.Columns[2].Type)
+	assert.False(t, contexts[0].Table.Columns[2].IsNullable)
+}
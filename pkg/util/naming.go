@@ -0,0 +1,262 @@
+package util
+
+import (
+	"maps"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// irregularWords are the built-in irregular words from singular to plural, words mapped to themselves are uncountable.
+var irregularWords = map[string]string{
+	"person":      "people",
+	"man":         "men",
+	"woman":       "women",
+	"child":       "children",
+	"mouse":       "mice",
+	"goose":       "geese",
+	"tooth":       "teeth",
+	"foot":        "feet",
+	"ox":          "oxen",
+	"leaf":        "leaves",
+	"life":        "lives",
+	"knife":       "knives",
+	"wife":        "wives",
+	"half":        "halves",
+	"movie":       "movies",
+	"alias":       "aliases",
+	"status":      "statuses",
+	"bus":         "buses",
+	"analysis":    "analyses",
+	"data":        "data",
+	"metadata":    "metadata",
+	"information": "information",
+	"equipment":   "equipment",
+	"news":        "news",
+	"series":      "series",
+	"species":     "species",
+	"media":       "media",
+}
+
+// namingStrategy computes the name variants of tables and columns.
+type namingStrategy struct {
+	cfg       *model.NamingConfig
+	plurals   map[string]string // singular to plural
+	singulars map[string]string // plural to singular
+}
+
+// getNamingConfig merges the naming strategy of the database into the global one,
+// each field set in the database replaces the global one, irregulars and overrides are merged.
+// Table names are singularized unless a configured naming strategy turns it off, they are kept if there is none.
+func getNamingConfig(cfg *model.Config, dbCfg *model.DatabaseConfig) *model.NamingConfig {
+	merged := &model.NamingConfig{}
+	for _, n := range []*model.NamingConfig{cfg.Naming, dbCfg.Naming} {
+		if n == nil {
+			continue
+		}
+		if merged.Singularize == nil {
+			singularize := true
+			merged.Singularize = &singularize
+		}
+		if len(n.TablePrefixes) > 0 {
+			merged.TablePrefixes = n.TablePrefixes
+		}
+		if len(n.TableSuffixes) > 0 {
+			merged.TableSuffixes = n.TableSuffixes
+		}
+		if len(n.ColumnPrefixes) > 0 {
+			merged.ColumnPrefixes = n.ColumnPrefixes
+		}
+		if len(n.ColumnSuffixes) > 0 {
+			merged.ColumnSuffixes = n.ColumnSuffixes
+		}
+		if n.Singularize != nil {
+			merged.Singularize = n.Singularize
+		}
+		merged.Irregulars = mergeStringMaps(merged.Irregulars, n.Irregulars)
+		merged.Overrides = mergeStringMaps(merged.Overrides, n.Overrides)
+	}
+	return merged
+}
+
+func mergeStringMaps(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]string, len(override))
+	}
+	maps.Copy(merged, override)
+	return merged
+}
+
+func newNamingStrategy(cfg *model.NamingConfig) *namingStrategy {
+	s := &namingStrategy{
+		cfg:       cfg,
+		plurals:   maps.Clone(irregularWords),
+		singulars: make(map[string]string, len(irregularWords)+len(cfg.Irregulars)),
+	}
+	for singular, plural := range cfg.Irregulars {
+		s.plurals[strings.ToLower(singular)] = strings.ToLower(plural)
+	}
+	for singular, plural := range s.plurals {
+		s.singulars[plural] = singular
+	}
+	return s
+}
+
+// fillNames sets the name variants of the table and its columns,
+// the logical names of the column configs take precedence over the overrides of the naming strategy.
+func (s *namingStrategy) fillNames(table *model.Table, columns map[string]*model.ColumnConfig) {
+	singularize := s.cfg.Singularize != nil && *s.cfg.Singularize
+	table.Names = s.names(table.Name, s.cfg.Overrides[table.Name], s.cfg.TablePrefixes, s.cfg.TableSuffixes, singularize)
+	for _, col := range table.Columns {
+		override := s.cfg.Overrides[table.Name+"."+col.Name]
+		if colCfg := columns[col.Name]; colCfg != nil && colCfg.LogicalName != "" {
+			override = colCfg.LogicalName
+		}
+		col.Names = s.names(col.Name, override, s.cfg.ColumnPrefixes, s.cfg.ColumnSuffixes, false)
+	}
+}
+
+func (s *namingStrategy) names(name, override string, prefixes, suffixes []string, singularize bool) *model.Names {
+	var words []string
+	if override != "" {
+		words = splitWords(override)
+	} else {
+		words = splitWords(stripAffixes(name, prefixes, suffixes))
+		if singularize && len(words) > 0 {
+			words[len(words)-1] = s.singular(words[len(words)-1])
+		}
+	}
+	if len(words) == 0 {
+		words = []string{strings.ToLower(name)}
+	}
+
+	last := len(words) - 1
+	singular := append(words[:last:last], s.singular(words[last]))
+	plural := append(words[:last:last], s.plural(singular[last]))
+
+	var rest strings.Builder
+	for _, w := range words[1:] {
+		rest.WriteString(upperFirst(w))
+	}
+	return &model.Names{
+		Pascal:   upperFirst(words[0]) + rest.String(),
+		Camel:    words[0] + rest.String(),
+		Snake:    strings.Join(words, "_"),
+		Kebab:    strings.Join(words, "-"),
+		Plural:   strings.Join(plural, "_"),
+		Singular: strings.Join(singular, "_"),
+	}
+}
+
+// stripAffixes strips the longest matching prefix and suffix, ignoring case, unless nothing would be left.
+func stripAffixes(name string, prefixes, suffixes []string) string {
+	lower := strings.ToLower(name)
+	for _, p := range longestFirst(prefixes) {
+		if len(p) < len(name) && strings.HasPrefix(lower, strings.ToLower(p)) {
+			name, lower = name[len(p):], lower[len(p):]
+			break
+		}
+	}
+	for _, suffix := range longestFirst(suffixes) {
+		if len(suffix) < len(name) && strings.HasSuffix(lower, strings.ToLower(suffix)) {
+			name = name[:len(name)-len(suffix)]
+			break
+		}
+	}
+	return name
+}
+
+func longestFirst(affixes []string) []string {
+	sorted := append([]string(nil), affixes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	return sorted
+}
+
+// splitWords splits a snake, kebab, camel or pascal case name into lowercase words, e.g. HTTPServer_id is http, server and id.
+func splitWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	flush()
+	return words
+}
+
+func upperFirst(word string) string {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return word
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+func (s *namingStrategy) singular(word string) string {
+	if singular, ok := s.singulars[word]; ok {
+		return singular
+	}
+	if _, ok := s.plurals[word]; ok {
+		return word
+	}
+	switch {
+	case hasAnySuffix(word, "ss", "us", "is"):
+		return word
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case hasAnySuffix(word, "sses", "xes", "zes", "ches", "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func (s *namingStrategy) plural(word string) string {
+	if plural, ok := s.plurals[word]; ok {
+		return plural
+	}
+	if _, ok := s.singulars[word]; ok {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "y") && len(word) > 1 && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return strings.TrimSuffix(word, "y") + "ies"
+	case hasAnySuffix(word, "s", "x", "z", "ch", "sh"):
+		return word + "es"
+	}
+	return word + "s"
+}
+
+func hasAnySuffix(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitWords(t *testing.T) {
+	assert.Equal(t, []string{"user", "accounts"}, splitWords("user_accounts"))
+	assert.Equal(t, []string{"user", "accounts"}, splitWords("user-accounts"))
+	assert.Equal(t, []string{"user", "account", "id"}, splitWords("UserAccountID"))
+	assert.Equal(t, []string{"http", "server", "id"}, splitWords("HTTPServer_id"))
+	assert.Equal(t, []string{"user", "account"}, splitWords("userAccount"))
+	assert.Equal(t, []string{"address2", "line"}, splitWords("address2Line"))
+	assert.Empty(t, splitWords("__"))
+}
+
+func Test_stripAffixes(t *testing.T) {
+	assert.Equal(t, "user_accounts", stripAffixes("t_user_accounts", []string{"t_"}, nil))
+	assert.Equal(t, "user", stripAffixes("tbl_user", []string{"t_", "tbl_"}, nil))
+	assert.Equal(t, "User", stripAffixes("T_User_Tab", []string{"t_"}, []string{"_tab"}))
+	assert.Equal(t, "t_", stripAffixes("t_", []string{"t_"}, nil))
+}
+
+func Test_namingStrategy_singularAndPlural(t *testing.T) {
+	s := newNamingStrategy(&model.NamingConfig{Irregulars: map[string]string{"cactus": "cacti", "sheep": "sheep"}})
+
+	for plural, singular := range map[string]string{
+		"users":      "user",
+		"categories": "category",
+		"addresses":  "address",
+		"boxes":      "box",
+		"matches":    "match",
+		"people":     "person",
+		"children":   "child",
+		"statuses":   "status",
+		"status":     "status",
+		"data":       "data",
+		"cacti":      "cactus",
+		"sheep":      "sheep",
+		"user":       "user",
+	} {
+		assert.Equal(t, singular, s.singular(plural), plural)
+	}
+
+	for singular, plural := range map[string]string{
+		"user":     "users",
+		"category": "categories",
+		"day":      "days",
+		"address":  "addresses",
+		"box":      "boxes",
+		"person":   "people",
+		"cactus":   "cacti",
+		"sheep":    "sheep",
+		"news":     "news",
+	} {
+		assert.Equal(t, plural, s.plural(singular), singular)
+	}
+}
+
+func Test_namingStrategy_fillNames(t *testing.T) {
+	yes := true
+	s := newNamingStrategy(&model.NamingConfig{
+		TablePrefixes:  []string{"t_"},
+		ColumnPrefixes: []string{"f_"},
+		Singularize:    &yes,
+		Overrides:      map[string]string{"t_user_accounts.f_usr_nm": "userName"},
+	})
+	table := &model.Table{
+		Name:    "t_user_accounts",
+		Columns: []*model.Column{{Name: "f_usr_nm"}, {Name: "f_created_at"}},
+	}
+
+	s.fillNames(table, nil)
+
+	assert.Equal(t, &model.Names{
+		Pascal:   "UserAccount",
+		Camel:    "userAccount",
+		Snake:    "user_account",
+		Kebab:    "user-account",
+		Plural:   "user_accounts",
+		Singular: "user_account",
+	}, table.Names)
+	assert.Equal(t, "UserName", table.Columns[0].Names.Pascal)
+	assert.Equal(t, "user_name", table.Columns[0].Names.Snake)
+	assert.Equal(t, "createdAt", table.Columns[1].Names.Camel)
+	assert.Equal(t, "created-at", table.Columns[1].Names.Kebab)
+}
+
+func Test_namingStrategy_fillNames_whenNotSingularized_thenShouldKeepPlural(t *testing.T) {
+	s := newNamingStrategy(&model.NamingConfig{})
+	table := &model.Table{Name: "user_accounts"}
+
+	s.fillNames(table, nil)
+
+	assert.Equal(t, "UserAccounts", table.Names.Pascal)
+	assert.Equal(t, "user_accounts", table.Names.Plural)
+	assert.Equal(t, "user_account", table.Names.Singular)
+}
+
+func Test_getNamingConfig(t *testing.T) {
+	yes, no := true, false
+	cfg := &model.Config{Naming: &model.NamingConfig{
+		TablePrefixes: []string{"t_"},
+		Singularize:   &yes,
+		Overrides:     map[string]string{"a": "x", "b": "y"},
+	}}
+	dbCfg := &model.DatabaseConfig{Naming: &model.NamingConfig{
+		ColumnPrefixes: []string{"f_"},
+		Singularize:    &no,
+		Overrides:      map[string]string{"b": "z"},
+	}}
+
+	naming := getNamingConfig(cfg, dbCfg)
+
+	assert.Equal(t, []string{"t_"}, naming.TablePrefixes)
+	assert.Equal(t, []string{"f_"}, naming.ColumnPrefixes)
+	assert.False(t, *naming.Singularize)
+	assert.Equal(t, map[string]string{"a": "x", "b": "z"}, naming.Overrides)
+	assert.Equal(t, map[string]string{"a": "x", "b": "y"}, cfg.Naming.Overrides)
+}
+
+func Test_getNamingConfig_whenSingularizeIsNotSet_thenSingularizeIfConfigured(t *testing.T) {
+	no := false
+
+	assert.Nil(t, getNamingConfig(&model.Config{}, &model.DatabaseConfig{}).Singularize)
+
+	naming := getNamingConfig(&model.Config{}, &model.DatabaseConfig{Naming: &model.NamingConfig{TablePrefixes: []string{"t_"}}})
+	assert.True(t, *naming.Singularize)
+
+	naming = getNamingConfig(&model.Config{Naming: &model.NamingConfig{Singularize: &no}}, &model.DatabaseConfig{Naming: &model.NamingConfig{TablePrefixes: []string{"t_"}}})
+	assert.False(t, *naming.Singularize)
+}
+
+func TestCollectRenderContexts_whenNamingIsConfigured_thenShouldFillNames(t *testing.T) {
+	ddl := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(ddl, []byte(`CREATE TABLE t_user_accounts (id BIGINT PRIMARY KEY, display_name TEXT);`), 0644))
+
+	cfg := &model.Config{
+		Naming: &model.NamingConfig{TablePrefixes: []string{"t_"}},
+		Databases: []*model.DatabaseConfig{
+			{Dialect: "postgres", DDL: []string{ddl}, Tables: []*model.TableConfig{{Name: "t_user_accounts"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	table := contexts[0].Table
+	assert.Equal(t, "UserAccount", table.Names.Pascal)
+	assert.Equal(t, "user_accounts", table.Names.Plural)
+	assert.Equal(t, "displayName", table.Columns[1].Names.Camel)
+}
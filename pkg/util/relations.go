@@ -0,0 +1,312 @@
+package util
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// relationGraph collects the relations between the tables of a database config.
+type relationGraph struct {
+	tables map[string]*model.Table // by schema.name
+}
+
+// linkRelations fills Table.Relations from the foreign keys, the declared relations of the table configs,
+// and the relations inferred from column names if enabled, each relation is also set on the referenced table in the opposite direction.
+func linkRelations(dbCfg *model.DatabaseConfig, contexts []*model.RenderContext) {
+	g := &relationGraph{tables: make(map[string]*model.Table, len(contexts))}
+	for _, ctx := range contexts {
+		g.tables[ctx.Table.Schema+"."+ctx.Table.Name] = ctx.Table
+	}
+
+	// Tables are collected concurrently, keep the order stable
+	sorted := slices.Clone(contexts)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i].Table, sorted[j].Table
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Name < b.Name
+	})
+
+	for _, ctx := range sorted {
+		for _, fk := range ctx.Table.ForeignKeys {
+			if target := g.find(fk.ReferencedSchema, fk.ReferencedTable); target != nil {
+				g.addManyToOne(ctx.Table, target, fk.Columns, fk.ReferencedColumns, model.RelationSourceForeignKey)
+			}
+		}
+	}
+
+	for _, ctx := range sorted {
+		if ctx.TableConfig == nil {
+			continue
+		}
+		for _, relCfg := range ctx.TableConfig.Relations {
+			if err := g.addDeclared(ctx.Table, relCfg); err != nil {
+				log.Fatalf("table %s.%s: %v", ctx.Table.Schema, ctx.Table.Name, err)
+			}
+		}
+	}
+
+	if dbCfg.InferRelations {
+		for _, ctx := range sorted {
+			g.addInferred(ctx.Table)
+		}
+	}
+
+	for _, t := range g.tables {
+		sort.SliceStable(t.Relations, func(i, j int) bool {
+			return !t.Relations[i].IsInverse && t.Relations[j].IsInverse
+		})
+	}
+}
+
+func (g *relationGraph) find(schema, name string) *model.Table {
+	return g.tables[schema+"."+name]
+}
+
+// addManyToOne adds a many-to-one relation, one-to-one if the columns are unique, and its inverse.
+func (g *relationGraph) addManyToOne(table, target *model.Table, columns, referencedColumns []string, source string) {
+	typ := model.RelationTypeManyToOne
+	if isUniqueColumns(table, columns) {
+		typ = model.RelationTypeOneToOne
+	}
+	g.add(table, target, &model.Relation{
+		Name:              manyToOneName(columns, target.Name),
+		Type:              typ,
+		Source:            source,
+		Columns:           columns,
+		ReferencedColumns: referencedColumns,
+	})
+}
+
+func (g *relationGraph) addDeclared(table *model.Table, relCfg *model.RelationConfig) error {
+	schema := relCfg.ReferencedSchema
+	if schema == "" {
+		schema = table.Schema
+	}
+	target := g.find(schema, relCfg.ReferencedTable)
+	if target == nil {
+		return fmt.Errorf("relation to %s.%s: referenced table is not configured", schema, relCfg.ReferencedTable)
+	}
+
+	rel := &model.Relation{
+		Name:              relCfg.Name,
+		Type:              relCfg.Type,
+		Source:            model.RelationSourceDeclared,
+		Columns:           relCfg.Columns,
+		ReferencedColumns: relCfg.ReferencedColumns,
+	}
+	switch relCfg.Type {
+	case model.RelationTypeManyToOne, model.RelationTypeOneToOne:
+		if len(rel.ReferencedColumns) == 0 {
+			rel.ReferencedColumns = primaryKeyColumns(target)
+		}
+		if rel.Name == "" {
+			rel.Name = manyToOneName(rel.Columns, target.Name)
+		}
+	case model.RelationTypeOneToMany, model.RelationTypeManyToMany:
+		if len(rel.Columns) == 0 {
+			rel.Columns = primaryKeyColumns(table)
+		}
+		if len(rel.ReferencedColumns) == 0 && relCfg.Type == model.RelationTypeManyToMany {
+			rel.ReferencedColumns = primaryKeyColumns(target)
+		}
+		if rel.Name == "" {
+			rel.Name = target.Name
+		}
+	default:
+		return fmt.Errorf("relation to %s: unknown type %q, must be one of (many_to_one, one_to_many, one_to_one, many_to_many)", target.Name, relCfg.Type)
+	}
+
+	if len(rel.Columns) == 0 || len(rel.Columns) != len(rel.ReferencedColumns) {
+		return fmt.Errorf("relation %s: columns %v and referenced columns %v do not match", rel.Name, rel.Columns, rel.ReferencedColumns)
+	}
+	if err := checkColumns(table, rel.Columns); err != nil {
+		return fmt.Errorf("relation %s: %w", rel.Name, err)
+	}
+	if err := checkColumns(target, rel.ReferencedColumns); err != nil {
+		return fmt.Errorf("relation %s: %w", rel.Name, err)
+	}
+
+	if relCfg.Type == model.RelationTypeManyToMany {
+		through := relCfg.Through
+		if through == nil || through.Table == "" {
+			return fmt.Errorf("relation %s: many-to-many relation requires a through table", rel.Name)
+		}
+		if len(through.Columns) != len(rel.Columns) || len(through.ReferencedColumns) != len(rel.ReferencedColumns) {
+			return fmt.Errorf("relation %s: through columns do not match the columns of the relation", rel.Name)
+		}
+		rel.Through = &model.RelationThrough{
+			Schema:            through.Schema,
+			Table:             through.Table,
+			Columns:           through.Columns,
+			ReferencedColumns: through.ReferencedColumns,
+		}
+		if rel.Through.Schema == "" {
+			rel.Through.Schema = table.Schema
+		}
+	} else if relCfg.Through != nil {
+		return fmt.Errorf("relation %s: through is only supported by many-to-many relations", rel.Name)
+	}
+
+	g.add(table, target, rel)
+	return nil
+}
+
+// addInferred adds a many-to-one relation for each column named after a configured table with a single column primary key,
+// e.g. user_id references user.id or users.id, unless the column already has a relation.
+func (g *relationGraph) addInferred(table *model.Table) {
+	for _, col := range table.Columns {
+		prefix, ok := strings.CutSuffix(strings.ToLower(col.Name), "_id")
+		if !ok || prefix == "" || hasRelationOn(table, col.Name) {
+			continue
+		}
+		for _, name := range []string{prefix, prefix + "s", prefix + "es"} {
+			target := g.findFold(table.Schema, name)
+			if target == nil {
+				continue
+			}
+			if pk := primaryKeyColumns(target); len(pk) == 1 {
+				g.addManyToOne(table, target, []string{col.Name}, pk, model.RelationSourceInferred)
+				break
+			}
+		}
+	}
+}
+
+func (g *relationGraph) findFold(schema, name string) *model.Table {
+	if t := g.find(schema, name); t != nil {
+		return t
+	}
+	for _, t := range g.tables {
+		if t.Schema == schema && strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// add sets the relation on the table and its inverse on the target, relations already known are skipped.
+func (g *relationGraph) add(table, target *model.Table, rel *model.Relation) {
+	rel.ReferencedSchema = target.Schema
+	rel.ReferencedTable = target.Name
+	if slices.ContainsFunc(table.Relations, func(r *model.Relation) bool { return sameRelation(r, rel) }) {
+		return
+	}
+	table.Relations = append(table.Relations, rel)
+
+	inverse := &model.Relation{
+		Name:              table.Name,
+		Type:              inverseRelationType(rel.Type),
+		Source:            rel.Source,
+		IsInverse:         true,
+		Columns:           rel.ReferencedColumns,
+		ReferencedSchema:  table.Schema,
+		ReferencedTable:   table.Name,
+		ReferencedColumns: rel.Columns,
+	}
+	if rel.Through != nil {
+		inverse.Through = &model.RelationThrough{
+			Schema:            rel.Through.Schema,
+			Table:             rel.Through.Table,
+			Columns:           rel.Through.ReferencedColumns,
+			ReferencedColumns: rel.Through.Columns,
+		}
+	}
+	if !slices.ContainsFunc(target.Relations, func(r *model.Relation) bool { return sameRelation(r, inverse) }) {
+		target.Relations = append(target.Relations, inverse)
+	}
+}
+
+func sameRelation(a, b *model.Relation) bool {
+	if a.Type != b.Type || a.ReferencedSchema != b.ReferencedSchema || a.ReferencedTable != b.ReferencedTable ||
+		!slices.Equal(a.Columns, b.Columns) || !slices.Equal(a.ReferencedColumns, b.ReferencedColumns) {
+		return false
+	}
+	if a.Through == nil || b.Through == nil {
+		return a.Through == b.Through
+	}
+	return a.Through.Schema == b.Through.Schema && a.Through.Table == b.Through.Table
+}
+
+func inverseRelationType(typ string) string {
+	switch typ {
+	case model.RelationTypeManyToOne:
+		return model.RelationTypeOneToMany
+	case model.RelationTypeOneToMany:
+		return model.RelationTypeManyToOne
+	default:
+		return typ
+	}
+}
+
+// manyToOneName returns the column without the _id suffix for a single column, otherwise the referenced table.
+func manyToOneName(columns []string, referencedTable string) string {
+	if len(columns) == 1 {
+		if name, ok := strings.CutSuffix(strings.ToLower(columns[0]), "_id"); ok && name != "" {
+			return columns[0][:len(name)]
+		}
+	}
+	return referencedTable
+}
+
+func hasRelationOn(table *model.Table, column string) bool {
+	for _, r := range table.Relations {
+		if !r.IsInverse && r.Through == nil && slices.Contains(r.Columns, column) {
+			return true
+		}
+	}
+	return false
+}
+
+func primaryKeyColumns(table *model.Table) []string {
+	var columns []string
+	for _, col := range table.Columns {
+		if col.IsPrimaryKey {
+			columns = append(columns, col.Name)
+		}
+	}
+	return columns
+}
+
+// isUniqueColumns reports whether the columns are the primary key or have a unique index or constraint.
+func isUniqueColumns(table *model.Table, columns []string) bool {
+	sameSet := func(names []string) bool {
+		return len(names) == len(columns) && !slices.ContainsFunc(columns, func(c string) bool { return !slices.Contains(names, c) })
+	}
+	if sameSet(primaryKeyColumns(table)) {
+		return true
+	}
+	for _, idx := range table.Indexes {
+		if !idx.IsUnique {
+			continue
+		}
+		var names []string
+		for _, c := range idx.Columns {
+			names = append(names, c.Name)
+		}
+		if sameSet(names) {
+			return true
+		}
+	}
+	for _, c := range table.Constraints {
+		if (c.Type == model.ConstraintTypeUnique || c.Type == model.ConstraintTypePrimaryKey) && sameSet(c.Columns) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkColumns(table *model.Table, columns []string) error {
+	for _, name := range columns {
+		if !slices.ContainsFunc(table.Columns, func(c *model.Column) bool { return c.Name == name }) {
+			return fmt.Errorf("column %s.%s does not exist", table.Name, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectRenderContexts_whenRelationsAreDeclaredOrInferred_thenShouldLinkBothSides(t *testing.T) {
+	ddl := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(ddl, []byte(`
+		CREATE TABLE user (id BIGINT PRIMARY KEY, name VARCHAR(64));
+		CREATE TABLE post (id BIGINT PRIMARY KEY, user_id BIGINT, author_id BIGINT);
+		CREATE TABLE profile (user_id BIGINT PRIMARY KEY, bio TEXT);
+		CREATE TABLE roles (id BIGINT PRIMARY KEY);
+		CREATE TABLE user_role (user_id BIGINT, role_id BIGINT, PRIMARY KEY (user_id, role_id));`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Dialect:        "mysql",
+				Schema:         "app",
+				DDL:            []string{ddl},
+				InferRelations: true,
+				Tables: []*model.TableConfig{
+					{Name: "user", Relations: []*model.RelationConfig{
+						{Type: model.RelationTypeManyToMany, Name: "roles", ReferencedTable: "roles",
+							Through: &model.ThroughConfig{Table: "user_role", Columns: []string{"user_id"}, ReferencedColumns: []string{"role_id"}}},
+					}},
+					{Name: "post", Relations: []*model.RelationConfig{
+						{Type: model.RelationTypeManyToOne, Name: "author", Columns: []string{"author_id"}, ReferencedTable: "user"},
+					}},
+					{Name: "profile"},
+					{Name: "roles"},
+					{Name: "user_role"},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Table.Name < contexts[j].Table.Name })
+	require.Len(t, contexts, 5)
+	post, profile, roles, user := contexts[0].Table, contexts[1].Table, contexts[2].Table, contexts[3].Table
+
+	require.Len(t, post.Relations, 2)
+	assert.Equal(t, &model.Relation{
+		Name:              "author",
+		Type:              model.RelationTypeManyToOne,
+		Source:            model.RelationSourceDeclared,
+		Columns:           []string{"author_id"},
+		ReferencedSchema:  "app",
+		ReferencedTable:   "user",
+		ReferencedColumns: []string{"id"},
+	}, post.Relations[0])
+	assert.Equal(t, "user", post.Relations[1].Name)
+	assert.Equal(t, model.RelationSourceInferred, post.Relations[1].Source)
+
+	require.Len(t, profile.Relations, 1)
+	assert.Equal(t, model.RelationTypeOneToOne, profile.Relations[0].Type) // user_id is the primary key
+
+	var userRelations []string
+	for _, r := range user.Relations {
+		userRelations = append(userRelations, r.Type+" "+r.Name)
+	}
+	assert.Equal(t, []string{
+		"many_to_many roles",
+		"one_to_many post", // inverse of post.author
+		"one_to_many post", // inverse of post.user
+		"one_to_one profile",
+		"one_to_many user_role",
+	}, userRelations)
+	assert.True(t, user.Relations[1].IsInverse)
+	assert.Equal(t, []string{"author_id"}, user.Relations[1].ReferencedColumns)
+
+	require.Len(t, roles.Relations, 2)
+	assert.Equal(t, &model.Relation{
+		Name:              "user",
+		Type:              model.RelationTypeManyToMany,
+		Source:            model.RelationSourceDeclared,
+		IsInverse:         true,
+		Columns:           []string{"id"},
+		ReferencedSchema:  "app",
+		ReferencedTable:   "user",
+		ReferencedColumns: []string{"id"},
+		Through:           &model.RelationThrough{Schema: "app", Table: "user_role", Columns: []string{"role_id"}, ReferencedColumns: []string{"user_id"}},
+	}, roles.Relations[0])
+	assert.Equal(t, "user_role", roles.Relations[1].Name) // role_id is inferred to roles.id
+}
+
+func Test_relationGraph_addDeclared_whenConfigIsInvalid_thenReturnError(t *testing.T) {
+	user := &model.Table{Schema: "app", Name: "user", Columns: []*model.Column{{Name: "id", IsPrimaryKey: true}}}
+	post := &model.Table{Schema: "app", Name: "post", Columns: []*model.Column{{Name: "id", IsPrimaryKey: true}, {Name: "user_id"}}}
+	g := &relationGraph{tables: map[string]*model.Table{"app.user": user, "app.post": post}}
+
+	tests := []struct {
+		name    string
+		relCfg  *model.RelationConfig
+		wantErr string
+	}{
+		{name: "unknown table", relCfg: &model.RelationConfig{Type: model.RelationTypeManyToOne, Columns: []string{"user_id"}, ReferencedTable: "users"}, wantErr: "not configured"},
+		{name: "unknown type", relCfg: &model.RelationConfig{Type: "belongs_to", Columns: []string{"user_id"}, ReferencedTable: "user"}, wantErr: "unknown type"},
+		{name: "unknown column", relCfg: &model.RelationConfig{Type: model.RelationTypeManyToOne, Columns: []string{"owner_id"}, ReferencedTable: "user"}, wantErr: "post.owner_id does not exist"},
+		{name: "missing columns", relCfg: &model.RelationConfig{Type: model.RelationTypeManyToOne, ReferencedTable: "user"}, wantErr: "do not match"},
+		{name: "missing through", relCfg: &model.RelationConfig{Type: model.RelationTypeManyToMany, ReferencedTable: "user"}, wantErr: "through table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, g.addDeclared(post, tt.relCfg), tt.wantErr)
+		})
+	}
+	assert.Empty(t, post.Relations)
+}
+
+func Test_manyToOneName(t *testing.T) {
+	assert.Equal(t, "user", manyToOneName([]string{"user_id"}, "users"))
+	assert.Equal(t, "Owner", manyToOneName([]string{"Owner_ID"}, "users"))
+	assert.Equal(t, "users", manyToOneName([]string{"tenant_id", "user_id"}, "users"))
+	assert.Equal(t, "users", manyToOneName([]string{"email"}, "users"))
+}
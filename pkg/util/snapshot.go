@@ -0,0 +1,122 @@
+package util
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// snapshotTable is a table of a snapshot file, database is the name of the database config it was introspected from.
+type snapshotTable struct {
+	database string
+	table    *model.Table
+}
+
+// readSnapshot reads a snapshot file, either the render contexts printed by `gencoder introspect -o json|yaml`
+// or a plain list of tables, YAML being a superset of JSON, both formats are read by the YAML decoder.
+func readSnapshot(path string) ([]*snapshotTable, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []*model.RenderContext
+	if err := yaml.Unmarshal(b, &contexts); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	var result []*snapshotTable
+	for _, ctx := range contexts {
+		if ctx == nil || ctx.Table == nil {
+			continue
+		}
+		st := &snapshotTable{table: ctx.Table}
+		if ctx.DatabaseConfig != nil {
+			st.database = ctx.DatabaseConfig.Name
+		}
+		result = append(result, st)
+	}
+	if len(result) > 0 {
+		return result, nil
+	}
+
+	var tables []*model.Table
+	if err := yaml.Unmarshal(b, &tables); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	for _, t := range tables {
+		if t != nil && t.Name != "" {
+			result = append(result, &snapshotTable{table: t})
+		}
+	}
+	return result, nil
+}
+
+// loadSnapshotSource serves the tables of the snapshot file, when the snapshot holds several databases,
+// only the tables introspected from the database config with the same name are used.
+func loadSnapshotSource(dbCfg *model.DatabaseConfig) *schemaSource {
+	snapshot, err := readSnapshot(dbCfg.Snapshot)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tables, err := snapshotTables(snapshot, dbCfg.Name)
+	if err != nil {
+		log.Fatalf("snapshot %s: %v", dbCfg.Snapshot, err)
+	}
+
+	defaultSchema := dbCfg.Schema
+	if defaultSchema == "" && len(tables) > 0 {
+		defaultSchema = tables[0].Schema
+	}
+
+	source := newStaticSource(tables, defaultSchema)
+	source.dialect = declaredDialect(dbCfg)
+	return source
+}
+
+// snapshotTables returns the tables introspected from the database with the given name and those without database,
+// all tables if the name is empty, it fails if the snapshot has databases but none with the name.
+func snapshotTables(snapshot []*snapshotTable, database string) ([]*model.Table, error) {
+	var tables []*model.Table
+	var databases []string
+	for _, st := range snapshot {
+		if st.database == "" || database == "" || st.database == database {
+			tables = append(tables, st.table)
+		}
+		if st.database != "" && !slices.Contains(databases, st.database) {
+			databases = append(databases, st.database)
+		}
+	}
+	if len(tables) == 0 && len(databases) > 0 {
+		sort.Strings(databases)
+		return nil, fmt.Errorf("no tables of database %q, the snapshot has databases %s", database, strings.Join(databases, ", "))
+	}
+	return tables, nil
+}
+
+// ApplySchemaSnapshot makes every database of the config read its tables from the snapshot file instead of connecting,
+// if the config has no database, one that selects all tables of the snapshot is added.
+func ApplySchemaSnapshot(cfg *model.Config, path string) {
+	for _, dbCfg := range cfg.Databases {
+		dbCfg.Snapshot = path
+	}
+	if len(cfg.Databases) > 0 {
+		return
+	}
+
+	snapshot, err := readSnapshot(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	dbCfg := &model.DatabaseConfig{Snapshot: path}
+	for _, st := range snapshot {
+		dbCfg.Tables = append(dbCfg.Tables, &model.TableConfig{Schema: st.table.Schema, Name: st.table.Name})
+	}
+	cfg.Databases = append(cfg.Databases, dbCfg)
+}
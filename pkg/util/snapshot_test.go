@@ -0,0 +1,118 @@
+package util
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectRenderContexts_whenSnapshotIsConfigured_thenShouldBeSameAsLive(t *testing.T) {
+	dir := t.TempDir()
+	dbFile := filepath.Join(dir, "test.db")
+	conn, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	defer func(conn *sql.DB) {
+		err := conn.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(conn)
+
+	_, err = conn.Exec(`
+		CREATE TABLE user (id INTEGER PRIMARY KEY, name VARCHAR(64) NOT NULL UNIQUE);
+		CREATE TABLE post (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES user (id), title TEXT);`)
+	require.NoError(t, err)
+
+	live := CollectRenderContexts(&model.Config{
+		Databases: []*model.DatabaseConfig{
+			{Name: "testdb", Dsn: "sqlite:" + dbFile, Tables: []*model.TableConfig{{Name: "*"}}},
+		},
+	}, nil)
+	require.Len(t, live, 2)
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			snapshotFile := filepath.Join(dir, "snapshot."+format)
+			content := ToJson(live)
+			if format == "yaml" {
+				content = ToYaml(live)
+			}
+			require.NoError(t, os.WriteFile(snapshotFile, []byte(content), 0644))
+
+			offline := CollectRenderContexts(&model.Config{
+				Databases: []*model.DatabaseConfig{
+					{Name: "testdb", Snapshot: snapshotFile, Tables: []*model.TableConfig{{Name: "*"}}},
+				},
+			}, nil)
+
+			require.Len(t, offline, 2)
+			sortByTableName(live)
+			sortByTableName(offline)
+			for i := range live {
+				assert.Equal(t, ToYaml(live[i].Table), ToYaml(offline[i].Table))
+			}
+		})
+	}
+}
+
+func TestApplySchemaSnapshot_whenConfigHasNoDatabase_thenShouldSelectAllTables(t *testing.T) {
+	snapshotFile := filepath.Join(t.TempDir(), "tables.yaml")
+	require.NoError(t, os.WriteFile(snapshotFile, []byte(`
+- name: user
+  schema: public
+  columns:
+    - name: id
+      ordinal: 1
+      type: bigint
+- name: post
+  schema: blog
+  columns:
+    - name: id
+      ordinal: 1
+      type: bigint
+`), 0644))
+
+	cfg := &model.Config{}
+	ApplySchemaSnapshot(cfg, snapshotFile)
+
+	require.Len(t, cfg.Databases, 1)
+	assert.Equal(t, []*model.TableConfig{{Schema: "public", Name: "user"}, {Schema: "blog", Name: "post"}}, cfg.Databases[0].Tables)
+
+	contexts := CollectRenderContexts(cfg, nil)
+	sortByTableName(contexts)
+	require.Len(t, contexts, 2)
+	assert.Equal(t, "blog", contexts[0].Table.Schema)
+	assert.Equal(t, "bigint", contexts[1].Table.Columns[0].Type)
+}
+
+func sortByTableName(contexts []*model.RenderContext) {
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Table.Name < contexts[j].Table.Name })
+}
+
+func Test_snapshotTables(t *testing.T) {
+	user := &model.Table{Name: "user"}
+	account := &model.Table{Name: "account"}
+	plain := &model.Table{Name: "plain"}
+	snapshot := []*snapshotTable{
+		{database: "shop", table: user},
+		{database: "auth", table: account},
+		{table: plain},
+	}
+
+	tables, err := snapshotTables(snapshot, "shop")
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Table{user, plain}, tables)
+
+	tables, err = snapshotTables(snapshot, "")
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Table{user, account, plain}, tables)
+
+	_, err = snapshotTables(snapshot[:2], "shpo")
+	assert.EqualError(t, err, `no tables of database "shpo", the snapshot has databases auth, shop`)
+}
@@ -0,0 +1,264 @@
+package util
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/db"
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/xo/dburl"
+)
+
+// schemaSource is where the tables of a database config are read from, a live database or offline files.
+type schemaSource struct {
+	introspector db.Introspector
+	conn         *sql.DB // nil for offline sources
+	schema       func(tbCfg *model.TableConfig) string
+	dialect      string                    // dburl driver name of the SQL dialect, empty if unknown
+	messages     map[string]*model.Message // Protobuf messages of the tables by schema.name, nil for other sources
+}
+
+func (s *schemaSource) Close() {
+	if s.conn == nil {
+		return
+	}
+	if err := s.conn.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func openSchemaSource(dbCfg *model.DatabaseConfig) *schemaSource {
+	if dbCfg.Snapshot != "" {
+		return loadSnapshotSource(dbCfg)
+	}
+	if dbCfg.OpenAPI != "" {
+		return loadOpenAPISource(dbCfg)
+	}
+	if len(dbCfg.Proto) > 0 {
+		return loadProtoSource(dbCfg)
+	}
+	if dbCfg.DBML != "" {
+		return loadDBMLSource(dbCfg)
+	}
+	if len(dbCfg.DDL) > 0 || dbCfg.Migrations != "" {
+		return loadDDLSource(dbCfg)
+	}
+
+	u, err := dburl.Parse(dbCfg.Dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn, err := sql.Open(getGoDriver(u), u.DSN)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	introspector, ok := db.GetIntrospector(u.Driver)
+	if !ok {
+		log.Fatalf("unsupported driver: %s", u.Driver)
+	}
+
+	return &schemaSource{
+		introspector: introspector,
+		conn:         conn,
+		schema:       func(tbCfg *model.TableConfig) string { return getSchema(tbCfg, dbCfg, u) },
+		dialect:      u.Driver,
+	}
+}
+
+// loadDDLSource parses the DDL files of the database config into an in-memory schema,
+// then replays the migrations on it, so DDL files can serve as the baseline of the migrations.
+func loadDDLSource(dbCfg *model.DatabaseConfig) *schemaSource {
+	dialect := getDialect(dbCfg)
+	defaultSchema := getOfflineSchema(dbCfg, dialect)
+
+	schema := db.NewDDLSchema(dialect, defaultSchema)
+	if len(dbCfg.DDL) > 0 {
+		if _, err := schema.Exec(readDDLFiles(dbCfg.DDL)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if dbCfg.Migrations != "" {
+		applyMigrations(schema, dbCfg.Migrations)
+	}
+
+	source := newStaticSource(schema.Tables(), defaultSchema)
+	source.dialect = dialect
+	return source
+}
+
+// loadDBMLSource reads the tables of a DBML document,
+// the dialect defaults to the driver of the dsn, then to the database_type of the DBML project.
+func loadDBMLSource(dbCfg *model.DatabaseConfig) *schemaSource {
+	b, err := os.ReadFile(dbCfg.DBML)
+	if err != nil {
+		log.Fatal(err)
+	}
+	content := string(b)
+
+	dialect := db.DBMLDialect(content)
+	if dbCfg.Dialect != "" || dbCfg.Dsn != "" {
+		dialect = getDialect(dbCfg)
+	}
+	defaultSchema := getOfflineSchema(dbCfg, dialect)
+
+	schema := db.NewDDLSchema(dialect, defaultSchema)
+	if err := schema.ExecDBML(content); err != nil {
+		log.Fatalf("failed to parse DBML document %s: %v", dbCfg.DBML, err)
+	}
+	source := newStaticSource(schema.Tables(), defaultSchema)
+	source.dialect = dialect
+	return source
+}
+
+// loadOpenAPISource reads the object schemas of an OpenAPI document as tables.
+func loadOpenAPISource(dbCfg *model.DatabaseConfig) *schemaSource {
+	b, err := os.ReadFile(dbCfg.OpenAPI)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tables, err := db.ParseOpenAPI(b, dbCfg.Schema)
+	if err != nil {
+		log.Fatalf("failed to parse OpenAPI document %s: %v", dbCfg.OpenAPI, err)
+	}
+	source := newStaticSource(tables, dbCfg.Schema)
+	source.dialect = declaredDialect(dbCfg)
+	return source
+}
+
+// loadProtoSource reads the messages of the .proto files as tables, nested messages included.
+func loadProtoSource(dbCfg *model.DatabaseConfig) *schemaSource {
+	schema := db.NewProtoSchema()
+	for _, pattern := range dbCfg.Proto {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(files) == 0 {
+			log.Fatalf("no proto files match %s", pattern)
+		}
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := schema.Add(file, string(b)); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	tables, messages, err := protoTables(schema, dbCfg.Schema)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	source := newStaticSource(tables, dbCfg.Schema)
+	source.dialect = declaredDialect(dbCfg)
+	source.messages = messages
+	return source
+}
+
+// protoTables maps the messages of the proto schema to tables of the given schema, nested messages included,
+// messages by table key (schema.name) are returned along, it fails if messages of different packages map to the same table.
+func protoTables(schema *db.ProtoSchema, dbSchema string) ([]*model.Table, map[string]*model.Message, error) {
+	var tables []*model.Table
+	messages := make(map[string]*model.Message)
+	var add func(msgs []*model.Message) error
+	add = func(msgs []*model.Message) error {
+		for _, msg := range msgs {
+			t := schema.MessageTable(msg, dbSchema)
+			key := t.Schema + "." + t.Name
+			if existing, ok := messages[key]; ok {
+				return fmt.Errorf("proto messages %s and %s are both read as table %s, configure their files in separate databases", existing.FullName, msg.FullName, t.Name)
+			}
+			tables = append(tables, t)
+			messages[key] = msg
+			if err := add(msg.Messages); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(schema.Messages()); err != nil {
+		return nil, nil, err
+	}
+	return tables, messages, nil
+}
+
+// newStaticSource serves tables that are already loaded into memory, tables without a configured schema use defaultSchema.
+func newStaticSource(tables []*model.Table, defaultSchema string) *schemaSource {
+	return &schemaSource{
+		introspector: db.StaticIntrospector{Schema: defaultSchema, Tables: tables},
+		schema: func(tbCfg *model.TableConfig) string {
+			if tbCfg.Schema != "" {
+				return tbCfg.Schema
+			}
+			return defaultSchema
+		},
+	}
+}
+
+// getDialect returns the SQL dialect of offline schema files, defaults to the driver of the dsn.
+func getDialect(dbCfg *model.DatabaseConfig) string {
+	dialect := declaredDialect(dbCfg)
+	if dialect == "" {
+		log.Fatalf("database %s: dialect is required when dsn is not set", dbCfg.Name)
+	}
+	return dialect
+}
+
+// declaredDialect returns the dialect of the database config, defaults to the driver of the dsn, empty if neither is set.
+func declaredDialect(dbCfg *model.DatabaseConfig) string {
+	if dbCfg.Dialect != "" {
+		return dbCfg.Dialect
+	}
+	if dbCfg.Dsn != "" {
+		u, err := dburl.Parse(dbCfg.Dsn)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return u.Driver
+	}
+	return ""
+}
+
+// getOfflineSchema returns the schema of unqualified tables of offline schema files, defaults to the default schema of the dialect.
+func getOfflineSchema(dbCfg *model.DatabaseConfig, dialect string) string {
+	if dbCfg.Schema != "" {
+		return dbCfg.Schema
+	}
+	if introspector, ok := db.GetIntrospector(dialect); ok {
+		return introspector.DefaultSchema(nil)
+	}
+	return ""
+}
+
+// readDDLFiles reads and concatenates the files matched by the patterns in order,
+// files matched by a pattern are read in lexical order.
+func readDDLFiles(patterns []string) string {
+	var sb strings.Builder
+	for _, pattern := range patterns {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(files) == 0 {
+			log.Fatalf("no DDL files match %s", pattern)
+		}
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			sb.Write(b)
+			sb.WriteString("\n;\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,191 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/db"
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectRenderContexts_whenDDLFilesAreConfigured_thenShouldNotConnect(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "01_user.sql"), []byte(`
+		CREATE TABLE "user" (
+			id BIGSERIAL PRIMARY KEY,
+			username VARCHAR(64) NOT NULL,
+			deleted_at TIMESTAMP
+		);
+		COMMENT ON TABLE "user" IS 'User account';`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "02_post.sql"), []byte(`
+		CREATE TABLE post (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES "user" (id)
+		);
+		CREATE INDEX idx_post_user_id ON post (user_id);`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Name: "testdb",
+				// The DSN only tells the dialect, it is never connected
+				Dsn: "postgres://nobody@127.0.0.1:1/none",
+				DDL: []string{filepath.Join(dir, "*.sql")},
+				Tables: []*model.TableConfig{
+					{Name: "*", IgnoreColumns: []string{"deleted_at"}},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Table.Name < contexts[j].Table.Name })
+
+	require.Len(t, contexts, 2)
+	post, user := contexts[0].Table, contexts[1].Table
+	assert.Equal(t, "public", user.Schema)
+	assert.Equal(t, "User account", *user.Comment)
+	assert.Len(t, user.Columns, 2)
+	assert.Equal(t, "bigint", user.Columns[0].Type)
+	assert.Len(t, user.ReferencedBy, 1)
+	assert.Equal(t, "post_user_id_fkey", post.ForeignKeys[0].Name)
+	assert.Equal(t, "idx_post_user_id", post.Indexes[1].Name)
+}
+
+func Test_getDialect(t *testing.T) {
+	assert.Equal(t, "mysql", getDialect(&model.DatabaseConfig{Dialect: "mysql", Dsn: "postgres://localhost/db"}))
+	assert.Equal(t, "postgres", getDialect(&model.DatabaseConfig{Dsn: "postgres://localhost/db"}))
+	assert.Equal(t, "sqlite3", getDialect(&model.DatabaseConfig{Dsn: "sqlite:test.db"}))
+}
+
+func TestCollectRenderContexts_whenOpenAPIIsConfigured_thenReadComponentSchemas(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+openapi: 3.0.3
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+    Post:
+      type: object
+      properties:
+        id:
+          type: integer
+        author:
+          $ref: '#/components/schemas/User'
+`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{OpenAPI: file, Tables: []*model.TableConfig{{Name: "*"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Table.Name < contexts[j].Table.Name })
+
+	require.Len(t, contexts, 2)
+	post, user := contexts[0].Table, contexts[1].Table
+	assert.Equal(t, "Post_author_fkey", post.ForeignKeys[0].Name)
+	assert.Len(t, user.ReferencedBy, 1)
+	assert.True(t, user.Columns[0].IsPrimaryKey)
+}
+
+func TestCollectRenderContexts_whenProtoIsConfigured_thenTablesCarryTheMessage(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "user.proto")
+	require.NoError(t, os.WriteFile(file, []byte(`
+syntax = "proto3";
+package account;
+
+// A registered user
+message User {
+  int64 id = 1;
+  string email = 2;
+
+  message Address {
+    string city = 1;
+  }
+}`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{Proto: []string{file}, Tables: []*model.TableConfig{{Name: "User*"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Table.Name < contexts[j].Table.Name })
+
+	require.Len(t, contexts, 2)
+	user, address := contexts[0], contexts[1]
+	assert.Equal(t, "User", user.Table.Name)
+	assert.Equal(t, "A registered user", *user.Table.Comment)
+	assert.Equal(t, "account.User", user.Message.FullName)
+	assert.Equal(t, 2, user.Message.Fields[1].Number)
+	assert.Equal(t, "User_Address", address.Table.Name)
+	assert.Equal(t, "account.User.Address", address.Message.FullName)
+}
+
+func Test_protoTables_whenPackagesHaveSameMessage_thenReturnError(t *testing.T) {
+	schema := db.NewProtoSchema()
+	require.NoError(t, schema.Add("user.proto", `syntax = "proto3"; package user.v1; message User { int64 id = 1; }`))
+	require.NoError(t, schema.Add("admin.proto", `syntax = "proto3"; package admin.v1; message User { int64 id = 1; } message Role { string name = 1; }`))
+
+	_, _, err := protoTables(schema, "")
+
+	assert.ErrorContains(t, err, "user.v1.User and admin.v1.User are both read as table User")
+}
+
+func Test_protoTables_whenPackagesHaveDifferentMessages_thenMapEachMessage(t *testing.T) {
+	schema := db.NewProtoSchema()
+	require.NoError(t, schema.Add("user.proto", `syntax = "proto3"; package user.v1; message User { int64 id = 1; }`))
+	require.NoError(t, schema.Add("admin.proto", `syntax = "proto3"; package admin.v1; message Role { string name = 1; }`))
+
+	tables, messages, err := protoTables(schema, "public")
+
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	assert.Equal(t, "user.v1.User", messages["public.User"].FullName)
+	assert.Equal(t, "admin.v1.Role", messages["public.Role"].FullName)
+}
+
+func TestCollectRenderContexts_whenDBMLIsConfigured_thenReadDesignDocument(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "schema.dbml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+Project shop { database_type: 'MySQL' }
+
+Table users {
+  id int [pk, increment]
+  name varchar(64) [not null, note: 'Display name']
+}
+
+Table orders {
+  id int [pk]
+  user_id int [ref: > users.id]
+}
+`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{DBML: file, Tables: []*model.TableConfig{{Name: "*"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Table.Name < contexts[j].Table.Name })
+
+	require.Len(t, contexts, 2)
+	orders, users := contexts[0].Table, contexts[1].Table
+	assert.Equal(t, "orders_ibfk_1", orders.ForeignKeys[0].Name)
+	assert.True(t, users.Columns[0].IsAutoIncrement)
+	assert.Equal(t, "Display name", *users.Columns[1].Comment)
+	assert.Len(t, users.ReferencedBy, 1)
+}
@@ -0,0 +1,202 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// isNamePattern reports whether the name is a glob pattern (order_*) or a regex wrapped in slashes (/^t_.*/).
+func isNamePattern(name string) bool {
+	return isRegexPattern(name) || strings.ContainsAny(name, "*?[")
+}
+
+func isRegexPattern(name string) bool {
+	return len(name) > 1 && strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/")
+}
+
+// matchName reports whether the name matches the exact name, glob pattern or regex pattern.
+func matchName(pattern, name string) (bool, error) {
+	if isRegexPattern(pattern) {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+		}
+		return re.MatchString(name), nil
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+		return matched, nil
+	}
+	return pattern == name, nil
+}
+
+func matchAny(patterns []string, name string) (bool, error) {
+	for _, p := range patterns {
+		matched, err := matchName(p, name)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+// resolveTableConfigs expands the table entries of the database config into one TableConfig per real table.
+//
+// Pattern entries are resolved against the table names returned by listTables,
+// their properties, ignoreColumns and columns apply to every matching table in declaration order,
+// exact-name entries are applied last, so they take precedence over patterns.
+func resolveTableConfigs(dbCfg *model.DatabaseConfig, getSchema func(tbCfg *model.TableConfig) string, listTables func(schema string) ([]string, error)) ([]*model.TableConfig, error) {
+	type tableKey struct{ schema, name string }
+
+	var keys []tableKey
+	matched := make(map[tableKey][]*model.TableConfig)
+	add := func(key tableKey, tbCfg *model.TableConfig) {
+		if _, ok := matched[key]; !ok {
+			keys = append(keys, key)
+		}
+		matched[key] = append(matched[key], tbCfg)
+	}
+
+	catalog := make(map[string][]string)
+	for _, tbCfg := range dbCfg.Tables {
+		if !isNamePattern(tbCfg.Name) {
+			continue
+		}
+		schema := getSchema(tbCfg)
+		tables, ok := catalog[schema]
+		if !ok {
+			var err error
+			tables, err = listTables(schema)
+			if err != nil {
+				return nil, err
+			}
+			catalog[schema] = tables
+		}
+		for _, table := range tables {
+			ok, err := matchName(tbCfg.Name, table)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+			excluded, err := matchAny(tbCfg.Exclude, table)
+			if err != nil {
+				return nil, err
+			}
+			if !excluded {
+				add(tableKey{schema, table}, tbCfg)
+			}
+		}
+	}
+
+	for _, tbCfg := range dbCfg.Tables {
+		if !isNamePattern(tbCfg.Name) {
+			add(tableKey{getSchema(tbCfg), tbCfg.Name}, tbCfg)
+		}
+	}
+
+	result := make([]*model.TableConfig, 0, len(keys))
+	for _, key := range keys {
+		cfgs := matched[key]
+		if len(cfgs) == 1 && cfgs[0].Name == key.name {
+			result = append(result, cfgs[0])
+			continue
+		}
+		result = append(result, mergeTableConfigs(key.schema, key.name, cfgs))
+	}
+	return result, nil
+}
+
+func mergeTableConfigs(schema, name string, cfgs []*model.TableConfig) *model.TableConfig {
+	merged := &model.TableConfig{
+		Schema:     schema,
+		Name:       name,
+		Properties: make(map[string]string),
+	}
+	for _, c := range cfgs {
+		for k, v := range c.Properties {
+			merged.Properties[k] = v
+		}
+		for _, col := range c.IgnoreColumns {
+			if !slices.Contains(merged.IgnoreColumns, col) {
+				merged.IgnoreColumns = append(merged.IgnoreColumns, col)
+			}
+		}
+		merged.Relations = append(merged.Relations, c.Relations...)
+		for name, colCfg := range c.Columns {
+			if merged.Columns == nil {
+				merged.Columns = make(map[string]*model.ColumnConfig)
+			}
+			merged.Columns[name] = mergeColumnConfig(merged.Columns[name], colCfg)
+		}
+	}
+	return merged
+}
+
+// mergeColumnConfig returns the column config with the fields set in override replacing those of base, properties are merged.
+func mergeColumnConfig(base, override *model.ColumnConfig) *model.ColumnConfig {
+	merged := &model.ColumnConfig{Properties: make(map[string]string)}
+	for _, c := range []*model.ColumnConfig{base, override} {
+		if c == nil {
+			continue
+		}
+		for k, v := range c.Properties {
+			merged.Properties[k] = v
+		}
+		if c.Type != "" {
+			merged.Type = c.Type
+		}
+		if c.LogicalName != "" {
+			merged.LogicalName = c.LogicalName
+		}
+		if c.Comment != nil {
+			merged.Comment = c.Comment
+		}
+		if c.Nullable != nil {
+			merged.Nullable = c.Nullable
+		}
+	}
+	return merged
+}
+
+// resolveRoutineConfigs expands the routine entries of the database config into one RoutineConfig per real routine,
+// patterns are resolved like in resolveTableConfigs.
+func resolveRoutineConfigs(dbCfg *model.DatabaseConfig, getSchema func(tbCfg *model.TableConfig) string, listRoutines func(schema string) ([]string, error)) ([]*model.RoutineConfig, error) {
+	routines := &model.DatabaseConfig{}
+	for _, rtCfg := range dbCfg.Routines {
+		routines.Tables = append(routines.Tables, &model.TableConfig{
+			Schema:     rtCfg.Schema,
+			Name:       rtCfg.Name,
+			Exclude:    rtCfg.Exclude,
+			Properties: rtCfg.Properties,
+		})
+	}
+
+	tbCfgs, err := resolveTableConfigs(routines, getSchema, listRoutines)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.RoutineConfig, 0, len(tbCfgs))
+	for _, tbCfg := range tbCfgs {
+		if idx := slices.Index(routines.Tables, tbCfg); idx >= 0 {
+			result = append(result, dbCfg.Routines[idx])
+			continue
+		}
+		result = append(result, &model.RoutineConfig{
+			Schema:     tbCfg.Schema,
+			Name:       tbCfg.Name,
+			Properties: tbCfg.Properties,
+		})
+	}
+	return result, nil
+}
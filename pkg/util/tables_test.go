@@ -0,0 +1,136 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_matchName(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		target  string
+		want    bool
+	}{
+		{name: "exact name", pattern: "user", target: "user", want: true},
+		{name: "exact name not match", pattern: "user", target: "users", want: false},
+		{name: "glob", pattern: "order_*", target: "order_item", want: true},
+		{name: "glob not match", pattern: "order_*", target: "orders", want: false},
+		{name: "glob all", pattern: "*", target: "anything", want: true},
+		{name: "glob single char", pattern: "t?", target: "t1", want: true},
+		{name: "regex", pattern: "/^t_.*/", target: "t_user", want: true},
+		{name: "regex not match", pattern: "/^t_.*/", target: "user_t_", want: false},
+		{name: "regex is not anchored", pattern: "/log/", target: "audit_log_2024", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := matchName(tt.pattern, tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_matchName_whenRegexIsInvalid_thenReturnError(t *testing.T) {
+	_, err := matchName("/(/", "user")
+	assert.Error(t, err)
+}
+
+func Test_resolveTableConfigs(t *testing.T) {
+	dbCfg := &model.DatabaseConfig{
+		Tables: []*model.TableConfig{
+			{
+				Name:          "*",
+				Exclude:       []string{"flyway_*", "/_bak$/"},
+				Properties:    map[string]string{"k1": "all", "k2": "all"},
+				IgnoreColumns: []string{"deleted_at"},
+			},
+			{
+				Name:          "order_*",
+				Properties:    map[string]string{"k2": "order"},
+				IgnoreColumns: []string{"version"},
+			},
+			{
+				Name:       "order_item",
+				Properties: map[string]string{"k2": "order_item"},
+			},
+			{
+				Schema: "other",
+				Name:   "user",
+			},
+		},
+	}
+	getSchema := func(tbCfg *model.TableConfig) string {
+		if tbCfg.Schema != "" {
+			return tbCfg.Schema
+		}
+		return "public"
+	}
+	var listed []string
+	listTables := func(schema string) ([]string, error) {
+		listed = append(listed, schema)
+		return []string{"flyway_schema_history", "order", "order_item", "user", "user_bak"}, nil
+	}
+
+	cfgs, err := resolveTableConfigs(dbCfg, getSchema, listTables)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"public"}, listed) // catalog is read once per schema
+	assert.Len(t, cfgs, 4)
+
+	assert.Equal(t, "public", cfgs[0].Schema)
+	assert.Equal(t, "order", cfgs[0].Name)
+	assert.Equal(t, map[string]string{"k1": "all", "k2": "all"}, cfgs[0].Properties)
+	assert.Equal(t, []string{"deleted_at"}, cfgs[0].IgnoreColumns)
+
+	assert.Equal(t, "order_item", cfgs[1].Name)
+	assert.Equal(t, map[string]string{"k1": "all", "k2": "order_item"}, cfgs[1].Properties) // exact name takes precedence
+	assert.Equal(t, []string{"deleted_at", "version"}, cfgs[1].IgnoreColumns)
+
+	assert.Equal(t, "public", cfgs[2].Schema)
+	assert.Equal(t, "user", cfgs[2].Name)
+
+	assert.Same(t, dbCfg.Tables[3], cfgs[3]) // exact entry without pattern match is used as is
+}
+
+func Test_resolveTableConfigs_whenNoPattern_thenShouldNotReadCatalog(t *testing.T) {
+	dbCfg := &model.DatabaseConfig{
+		Tables: []*model.TableConfig{{Name: "user"}, {Name: "order"}},
+	}
+
+	cfgs, err := resolveTableConfigs(dbCfg,
+		func(*model.TableConfig) string { return "public" },
+		func(string) ([]string, error) {
+			t.Fatal("should not list tables")
+			return nil, nil
+		},
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, dbCfg.Tables, cfgs)
+}
+
+func Test_resolveRoutineConfigs(t *testing.T) {
+	dbCfg := &model.DatabaseConfig{
+		Routines: []*model.RoutineConfig{
+			{Name: "sp_*", Exclude: []string{"sp_internal_*"}, Properties: map[string]string{"k": "sp"}},
+			{Name: "fn_total", Properties: map[string]string{"k": "fn"}},
+		},
+	}
+
+	cfgs, err := resolveRoutineConfigs(dbCfg,
+		func(*model.TableConfig) string { return "dbo" },
+		func(string) ([]string, error) {
+			return []string{"fn_total", "sp_get_user", "sp_internal_sync"}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, cfgs, 2)
+	assert.Equal(t, &model.RoutineConfig{Schema: "dbo", Name: "sp_get_user", Properties: map[string]string{"k": "sp"}}, cfgs[0])
+	assert.Same(t, dbCfg.Routines[1], cfgs[1])
+}
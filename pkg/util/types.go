@@ -0,0 +1,77 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+)
+
+// resolveTypeMappings sets the type of each column per target language from the first matching rule of the type mappings,
+// and collects the imports required by the column types of the table.
+func resolveTypeMappings(mappings map[string][]*model.TypeMapping, dialect string, table *model.Table) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	imports := make(map[string]map[string]bool)
+	for _, col := range table.Columns {
+		col.Types = make(map[string]string)
+		for target, rules := range mappings {
+			for _, rule := range rules {
+				ok, err := matchTypeMapping(rule, dialect, col)
+				if err != nil {
+					return fmt.Errorf("type mapping of %s: %w", target, err)
+				}
+				if !ok {
+					continue
+				}
+				col.Types[target] = rule.Type
+				if rule.Import != "" {
+					if imports[target] == nil {
+						imports[target] = make(map[string]bool)
+					}
+					imports[target][rule.Import] = true
+				}
+				break
+			}
+		}
+	}
+
+	table.Imports = make(map[string][]string)
+	for target := range mappings {
+		table.Imports[target] = []string{}
+		for imp := range imports[target] {
+			table.Imports[target] = append(table.Imports[target], imp)
+		}
+		sort.Strings(table.Imports[target])
+	}
+	return nil
+}
+
+func matchTypeMapping(rule *model.TypeMapping, dialect string, col *model.Column) (bool, error) {
+	if rule.Dialect != "" && !strings.EqualFold(rule.Dialect, dialect) {
+		return false, nil
+	}
+	if rule.LogicalType != "" && rule.LogicalType != col.LogicalType {
+		return false, nil
+	}
+	if rule.Nullable != nil && *rule.Nullable != col.IsNullable {
+		return false, nil
+	}
+	if rule.MinLength != nil && (col.MaxLength == nil || *col.MaxLength < *rule.MinLength) {
+		return false, nil
+	}
+	if rule.MaxLength != nil && (col.MaxLength == nil || *col.MaxLength > *rule.MaxLength) {
+		return false, nil
+	}
+	if rule.RawType != "" {
+		pattern := rule.RawType
+		if !isRegexPattern(pattern) {
+			pattern = strings.ToLower(pattern)
+		}
+		return matchName(pattern, strings.ToLower(col.Type))
+	}
+	return true, nil
+}
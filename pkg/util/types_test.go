@@ -0,0 +1,89 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DanielLiu1123/gencoder/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_resolveTypeMappings(t *testing.T) {
+	yes, no := true, false
+	length := func(n int) *int { return &n }
+	mappings := map[string][]*model.TypeMapping{
+		"java": {
+			{Dialect: "mysql", RawType: "tinyint(1)", Type: "Boolean"},
+			{LogicalType: model.LogicalTypeInt64, Nullable: &no, Type: "long"},
+			{LogicalType: model.LogicalTypeInt64, Type: "Long"},
+			{RawType: "/^(var)?char/", MaxLength: length(1), Type: "Character"},
+			{RawType: "/^(var)?char/", Type: "String"},
+			{LogicalType: model.LogicalTypeTimestamp, Type: "LocalDateTime", Import: "java.time.LocalDateTime"},
+			{LogicalType: model.LogicalTypeDecimal, Type: "BigDecimal", Import: "java.math.BigDecimal"},
+		},
+		"ts": {
+			{RawType: "VARCHAR*", MinLength: length(1000), Type: "Text"},
+			{Nullable: &yes, Type: "unknown | null"},
+		},
+	}
+	table := &model.Table{Columns: []*model.Column{
+		{Name: "id", Type: "bigint", LogicalType: model.LogicalTypeInt64},
+		{Name: "parent_id", Type: "bigint", LogicalType: model.LogicalTypeInt64, IsNullable: true},
+		{Name: "enabled", Type: "tinyint(1)", LogicalType: model.LogicalTypeBool},
+		{Name: "flag", Type: "char(1)", LogicalType: model.LogicalTypeString, MaxLength: length(1)},
+		{Name: "bio", Type: "varchar(2000)", LogicalType: model.LogicalTypeString, MaxLength: length(2000), IsNullable: true},
+		{Name: "created_at", Type: "datetime", LogicalType: model.LogicalTypeTimestamp},
+	}}
+
+	require.NoError(t, resolveTypeMappings(mappings, "mysql", table))
+
+	types := make(map[string]map[string]string)
+	for _, col := range table.Columns {
+		types[col.Name] = col.Types
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"id":         {"java": "long"},
+		"parent_id":  {"java": "Long", "ts": "unknown | null"},
+		"enabled":    {"java": "Boolean"},
+		"flag":       {"java": "Character"},
+		"bio":        {"java": "String", "ts": "Text"},
+		"created_at": {"java": "LocalDateTime"},
+	}, types)
+	assert.Equal(t, map[string][]string{"java": {"java.time.LocalDateTime"}, "ts": {}}, table.Imports)
+
+	require.NoError(t, resolveTypeMappings(mappings, "postgres", table))
+	assert.Empty(t, table.Columns[2].Types) // tinyint(1) is only mapped for mysql
+}
+
+func Test_resolveTypeMappings_whenRegexIsInvalid_thenReturnError(t *testing.T) {
+	mappings := map[string][]*model.TypeMapping{"go": {{RawType: "/(/", Type: "string"}}}
+	err := resolveTypeMappings(mappings, "mysql", &model.Table{Columns: []*model.Column{{Name: "id", Type: "int"}}})
+	assert.ErrorContains(t, err, "type mapping of go")
+}
+
+func TestCollectRenderContexts_whenTypeMappingsAreConfigured_thenShouldResolveColumnTypes(t *testing.T) {
+	ddl := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(ddl, []byte(`CREATE TABLE "user" (id UUID PRIMARY KEY, created_at TIMESTAMPTZ);`), 0644))
+
+	cfg := &model.Config{
+		TypeMappings: map[string][]*model.TypeMapping{
+			"go": {
+				{Dialect: "postgres", RawType: "uuid", Type: "uuid.UUID", Import: "github.com/google/uuid"},
+				{LogicalType: model.LogicalTypeTimestamptz, Type: "time.Time", Import: "time"},
+			},
+		},
+		Databases: []*model.DatabaseConfig{
+			{Dialect: "postgres", DDL: []string{ddl}, Tables: []*model.TableConfig{{Name: "user"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	user := contexts[0].Table
+	assert.Equal(t, "uuid.UUID", user.Columns[0].Types["go"])
+	assert.Equal(t, "time.Time", user.Columns[1].Types["go"])
+	assert.Equal(t, []string{"github.com/google/uuid", "time"}, user.Imports["go"])
+}
@@ -3,7 +3,6 @@ package util
 import (
 	"bufio"
 	"context"
-	"database/sql"
 	"fmt"
 	"io/fs"
 	"log"
@@ -11,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -127,6 +127,10 @@ func loadFilesFromPath(rootPath string, cfg *model.Config) ([]*model.File, error
 			if output != "" {
 				f.Type = model.FileTypeTemplate
 				f.Output = output
+				f.Scope, err = getTemplateScope(content, cfg)
+				if err != nil {
+					return fmt.Errorf("%s: %w", rel, err)
+				}
 			} else {
 				f.Type = model.FileTypePartial
 			}
@@ -148,7 +152,17 @@ func CollectRenderContexts(cfg *model.Config, commandLineProperties map[string]s
 		renderContexts = append(renderContexts, contexts...)
 	}
 
+	var allTables []*model.Table
+	seen := make(map[*model.Database]bool)
 	for _, rc := range renderContexts {
+		if rc.Database != nil && !seen[rc.Database] {
+			seen[rc.Database] = true
+			allTables = append(allTables, rc.Database.Tables...)
+		}
+	}
+
+	for _, rc := range renderContexts {
+		rc.AllTables = allTables
 		for k, v := range commandLineProperties {
 			rc.Properties[k] = v
 		}
@@ -158,44 +172,60 @@ func CollectRenderContexts(cfg *model.Config, commandLineProperties map[string]s
 }
 
 func getFileNameTemplate(content string, cfg *model.Config) string {
+	return getMarkerValue(content, cfg.GetOutputMarker())
+}
+
+// getTemplateScope returns the scope set by the scope marker of the template, table if there is none.
+func getTemplateScope(content string, cfg *model.Config) (string, error) {
+	switch scope := getMarkerValue(content, cfg.GetScopeMarker()); scope {
+	case "", model.FileScopeTable:
+		return model.FileScopeTable, nil
+	case model.FileScopeRoutine, model.FileScopeDatabase, model.FileScopeGlobal:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown template scope %q, must be one of (table, routine, database, global)", scope)
+	}
+}
+
+// getMarkerValue returns the trimmed text after the marker on the first line containing it.
+func getMarkerValue(content, marker string) string {
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.Contains(line, cfg.GetOutputMarker()) {
-			return strings.TrimSpace(line[strings.LastIndex(line, cfg.GetOutputMarker())+len(cfg.GetOutputMarker()):])
+		if strings.Contains(line, marker) {
+			return strings.TrimSpace(line[strings.LastIndex(line, marker)+len(marker):])
 		}
 	}
 	return ""
 }
 
 func collectRenderContextsForDBConfig(cfg *model.Config, dbCfg *model.DatabaseConfig) []*model.RenderContext {
-	u, err := dburl.Parse(dbCfg.Dsn)
+	source := openSchemaSource(dbCfg)
+	defer source.Close()
+
+	tbCfgs, err := resolveTableConfigs(dbCfg, source.schema,
+		func(schema string) ([]string, error) {
+			return source.introspector.ListTables(context.Background(), source.conn, schema)
+		},
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	conn, err := sql.Open(u.Driver, u.DSN)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer func(conn *sql.DB) {
-		err := conn.Close()
-		if err != nil {
-			log.Fatal(err)
-		}
-	}(conn)
+	annotationPattern := getAnnotationPattern(cfg)
+	naming := newNamingStrategy(getNamingConfig(cfg, dbCfg))
 
 	var mu sync.Mutex
 	var contexts []*model.RenderContext
 	var wg sync.WaitGroup
 
-	for _, tbCfg := range dbCfg.Tables {
+	for _, tbCfg := range tbCfgs {
 		wg.Add(1)
 		go func(tbCfg *model.TableConfig) {
 			defer wg.Done()
 
-			schema := getSchema(tbCfg, dbCfg, u)
-			table, err := generateTable(conn, u.Driver, schema, tbCfg)
+			schema := source.schema(tbCfg)
+			table, err := db.GenTable(context.Background(), source.introspector, source.conn, schema, tbCfg.Name, tbCfg.IgnoreColumns)
 			if err != nil {
 				log.Fatal(err)
 			}
@@ -205,7 +235,15 @@ func collectRenderContextsForDBConfig(cfg *model.Config, dbCfg *model.DatabaseCo
 				return
 			}
 
+			applyColumnConfigs(tbCfg, source.dialect, table)
+			db.FillAnnotations(table, annotationPattern)
+			naming.fillNames(table, tbCfg.Columns)
+			if err := resolveTypeMappings(cfg.TypeMappings, source.dialect, table); err != nil {
+				log.Fatal(err)
+			}
+
 			ctx := createRenderContext(cfg, dbCfg, tbCfg, table)
+			ctx.Message = source.messages[table.Schema+"."+table.Name]
 
 			mu.Lock()
 			contexts = append(contexts, ctx)
@@ -215,65 +253,193 @@ func collectRenderContextsForDBConfig(cfg *model.Config, dbCfg *model.DatabaseCo
 
 	wg.Wait()
 
+	linkReferencedBy(contexts)
+	linkRelations(dbCfg, contexts)
+
+	routineContexts, err := collectRoutineRenderContexts(cfg, dbCfg, source)
+	if err != nil {
+		log.Fatal(err)
+	}
+	contexts = append(contexts, routineContexts...)
+
+	database := newDatabase(dbCfg, source.dialect, contexts)
+	for _, ctx := range contexts {
+		ctx.Database = database
+	}
 	return contexts
 }
 
-func getSchema(tbCfg *model.TableConfig, dbCfg *model.DatabaseConfig, u *dburl.URL) string {
-	if tbCfg.Schema != "" {
-		return tbCfg.Schema
+// newDatabase returns the whole-database view of the tables of the contexts.
+func newDatabase(dbCfg *model.DatabaseConfig, dialect string, contexts []*model.RenderContext) *model.Database {
+	database := &model.Database{
+		Name:     dbCfg.Name,
+		Dialect:  dialect,
+		Tables:   []*model.Table{},
+		TableMap: make(map[string]*model.Table),
 	}
-	if dbCfg.Schema != "" {
-		return dbCfg.Schema
+	names := make(map[string]int)
+	for _, ctx := range contexts {
+		if ctx.Table != nil {
+			database.Tables = append(database.Tables, ctx.Table)
+			names[ctx.Table.Name]++
+		}
 	}
-	if u.Driver == "mysql" {
-		arr := strings.Split(u.Path, "/")
-		if len(arr) > 1 {
-			return arr[1] // database name as schema in MySQL
+
+	sort.Slice(database.Tables, func(i, j int) bool {
+		a, b := database.Tables[i], database.Tables[j]
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Name < b.Name
+	})
+	for _, t := range database.Tables {
+		if names[t.Name] > 1 {
+			database.TableMap[t.Schema+"."+t.Name] = t
+		} else {
+			database.TableMap[t.Name] = t
 		}
 	}
-	if u.Driver == "postgres" {
-		return "public" // default schema in PostgreSQL
+	return database
+}
+
+// collectRoutineRenderContexts collects one render context per procedure or function of the routine entries,
+// overloaded functions get one context each, routines that do not exist are skipped like tables.
+func collectRoutineRenderContexts(cfg *model.Config, dbCfg *model.DatabaseConfig, source *schemaSource) ([]*model.RenderContext, error) {
+	if len(dbCfg.Routines) == 0 {
+		return nil, nil
 	}
-	if u.Driver == "sqlserver" {
-		return "dbo" // default schema in SQL Server
+
+	introspector, ok := source.introspector.(db.RoutineIntrospector)
+	if !ok {
+		return nil, fmt.Errorf("routines are not supported by %T", source.introspector)
 	}
-	return ""
+
+	rtCfgs, err := resolveRoutineConfigs(dbCfg, source.schema,
+		func(schema string) ([]string, error) {
+			return introspector.ListRoutines(context.Background(), source.conn, schema)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var contexts []*model.RenderContext
+	for _, rtCfg := range rtCfgs {
+		schema := source.schema(&model.TableConfig{Schema: rtCfg.Schema, Name: rtCfg.Name})
+		routines, err := db.GenRoutines(context.Background(), source.introspector, source.conn, schema, rtCfg.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(routines) == 0 {
+			log.Printf("routine %s.%s not found, skipping", schema, rtCfg.Name)
+			continue
+		}
+
+		for _, routine := range routines {
+			contexts = append(contexts, &model.RenderContext{
+				Routine:        routine,
+				Properties:     mergeContextProperties(cfg, dbCfg, rtCfg.Properties),
+				Config:         cfg,
+				DatabaseConfig: dbCfg,
+				RoutineConfig:  rtCfg,
+			})
+		}
+	}
+	return contexts, nil
 }
 
-func generateTable(conn *sql.DB, driver, schema string, tbCfg *model.TableConfig) (*model.Table, error) {
-	switch driver {
-	case "mysql":
-		return db.GenMySQLTable(context.Background(), conn, schema, tbCfg.Name, tbCfg.IgnoreColumns)
-	case "postgres":
-		return db.GenPostgresTable(context.Background(), conn, schema, tbCfg.Name, tbCfg.IgnoreColumns)
-	case "sqlserver":
-		return db.GenMssqlTable(context.Background(), conn, schema, tbCfg.Name, tbCfg.IgnoreColumns)
-	default:
-		return nil, fmt.Errorf("unsupported driver: %s", driver)
+// getAnnotationPattern compiles the annotation regex of the config, it must have a name group.
+func getAnnotationPattern(cfg *model.Config) *regexp.Regexp {
+	pattern, err := regexp.Compile(cfg.GetAnnotation())
+	if err != nil {
+		log.Fatalf("invalid annotation pattern %q: %v", cfg.GetAnnotation(), err)
 	}
+	if pattern.SubexpIndex("name") < 0 {
+		log.Fatalf("annotation pattern %q has no name group", cfg.GetAnnotation())
+	}
+	return pattern
 }
 
-func createRenderContext(cfg *model.Config, dbCfg *model.DatabaseConfig, tbCfg *model.TableConfig, table *model.Table) *model.RenderContext {
-	properties := make(map[string]string)
-	for k, v := range cfg.Properties {
-		properties[k] = v
+// linkReferencedBy fills Table.ReferencedBy with the foreign keys of the other collected tables that reference it.
+func linkReferencedBy(contexts []*model.RenderContext) {
+	tables := make(map[string]*model.Table, len(contexts))
+	for _, ctx := range contexts {
+		tables[ctx.Table.Schema+"."+ctx.Table.Name] = ctx.Table
 	}
-	for k, v := range dbCfg.Properties {
-		properties[k] = v
+
+	for _, ctx := range contexts {
+		for _, fk := range ctx.Table.ForeignKeys {
+			if target, ok := tables[fk.ReferencedSchema+"."+fk.ReferencedTable]; ok {
+				target.ReferencedBy = append(target.ReferencedBy, fk)
+			}
+		}
 	}
-	for k, v := range tbCfg.Properties {
-		properties[k] = v
+
+	// Tables are collected concurrently, keep the order stable
+	for _, t := range tables {
+		sort.Slice(t.ReferencedBy, func(i, j int) bool {
+			a, b := t.ReferencedBy[i], t.ReferencedBy[j]
+			if a.Schema != b.Schema {
+				return a.Schema < b.Schema
+			}
+			if a.Table != b.Table {
+				return a.Table < b.Table
+			}
+			return a.Name < b.Name
+		})
 	}
+}
 
+func getSchema(tbCfg *model.TableConfig, dbCfg *model.DatabaseConfig, u *dburl.URL) string {
+	if tbCfg.Schema != "" {
+		return tbCfg.Schema
+	}
+	if dbCfg.Schema != "" {
+		return dbCfg.Schema
+	}
+	if introspector, ok := db.GetIntrospector(u.Driver); ok {
+		return introspector.DefaultSchema(u)
+	}
+	return ""
+}
+
+// getGoDriver returns the registered database/sql driver name for the given URL,
+// the one of the introspector if it implements db.DriverNamer, otherwise the dburl driver name.
+func getGoDriver(u *dburl.URL) string {
+	if introspector, ok := db.GetIntrospector(u.Driver); ok {
+		if namer, ok := introspector.(db.DriverNamer); ok {
+			return namer.DriverName()
+		}
+	}
+	return u.Driver
+}
+
+func createRenderContext(cfg *model.Config, dbCfg *model.DatabaseConfig, tbCfg *model.TableConfig, table *model.Table) *model.RenderContext {
 	return &model.RenderContext{
 		Table:          table,
-		Properties:     properties,
+		Properties:     mergeContextProperties(cfg, dbCfg, tbCfg.Properties),
 		Config:         cfg,
 		DatabaseConfig: dbCfg,
 		TableConfig:    tbCfg,
 	}
 }
 
+// mergeContextProperties merges the global, database and table or routine properties, later ones take precedence.
+func mergeContextProperties(cfg *model.Config, dbCfg *model.DatabaseConfig, properties map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for k, v := range cfg.Properties {
+		merged[k] = v
+	}
+	for k, v := range dbCfg.Properties {
+		merged[k] = v
+	}
+	for k, v := range properties {
+		merged[k] = v
+	}
+	return merged
+}
+
 // WriteFile writes the content to the given file, creating directories if necessary
 func WriteFile(filename string, content []byte) error {
 	dir := filepath.Dir(filename)
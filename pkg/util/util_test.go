@@ -9,12 +9,14 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/DanielLiu1123/gencoder/pkg/db"
 	"github.com/DanielLiu1123/gencoder/pkg/model"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/mysql"
 	"github.com/xo/dburl"
+	_ "modernc.org/sqlite"
 )
 
 func TestReadConfig(t *testing.T) {
@@ -242,3 +244,404 @@ func Test_getSchema_whenDBUsingMSSQL(t *testing.T) {
 	schema = getSchema(d.Tables[1], d, u)
 	assert.Equal(t, "schema1", schema)
 }
+
+func TestCollectRenderContexts_whenDBUsingSQLite(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`
+	CREATE TABLE user (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        username VARCHAR(64) NOT NULL, -- Username, required
+        created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP -- Record creation timestamp
+    );
+	`)
+	require.NoError(t, err)
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Name: "testdb",
+				Dsn:  "sqlite:" + dbFile,
+				Tables: []*model.TableConfig{
+					{
+						Name: "user",
+					},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	assert.Len(t, contexts, 1)
+	assert.Equal(t, "user", contexts[0].Table.Name)
+	assert.Equal(t, "main", contexts[0].Table.Schema)
+	assert.Len(t, contexts[0].Table.Columns, 3)
+	assert.Equal(t, "Username, required", *contexts[0].Table.Columns[1].Comment)
+}
+
+func Test_getSchema_whenDBUsingSQLite(t *testing.T) {
+	d := &model.DatabaseConfig{
+		Name: "sqlite",
+		Dsn:  "file:test.db",
+		Tables: []*model.TableConfig{
+			{
+				Name: "user1",
+			},
+		},
+	}
+	u, err := dburl.Parse(d.Dsn)
+	require.NoError(t, err)
+
+	schema := getSchema(d.Tables[0], d, u)
+	assert.Equal(t, "main", schema) // Default schema in SQLite is "main" if not specified.
+	assert.Equal(t, "sqlite", getGoDriver(u))
+}
+
+type pgxIntrospector struct {
+	db.PostgresIntrospector
+}
+
+func (pgxIntrospector) DriverName() string {
+	return "pgx"
+}
+
+func Test_getGoDriver_whenIntrospectorNamesDriver_thenUseIt(t *testing.T) {
+	u, err := dburl.Parse("postgres://localhost/testdb")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", getGoDriver(u))
+
+	db.RegisterIntrospector("postgres", pgxIntrospector{})
+	defer db.RegisterIntrospector("postgres", db.PostgresIntrospector{})
+	assert.Equal(t, "pgx", getGoDriver(u))
+}
+
+func TestCollectRenderContexts_whenTablesHaveForeignKeys_thenShouldLinkReferencedBy(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`
+	CREATE TABLE user (id INTEGER PRIMARY KEY);
+	CREATE TABLE post (id INTEGER PRIMARY KEY, author_id INTEGER REFERENCES user (id));
+	CREATE TABLE comment (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES user (id));
+	`)
+	require.NoError(t, err)
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Dsn: "sqlite:" + dbFile,
+				Tables: []*model.TableConfig{
+					{Name: "user"},
+					{Name: "post"},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	assert.Len(t, contexts, 2)
+
+	tables := map[string]*model.Table{}
+	for _, ctx := range contexts {
+		tables[ctx.Table.Name] = ctx.Table
+	}
+
+	// comment is not configured, so it is not part of the reverse side
+	assert.Len(t, tables["user"].ReferencedBy, 1)
+	assert.Equal(t, "post", tables["user"].ReferencedBy[0].Table)
+	assert.Equal(t, []string{"author_id"}, tables["user"].ReferencedBy[0].Columns)
+	assert.Empty(t, tables["post"].ReferencedBy)
+	assert.Len(t, tables["post"].ForeignKeys, 1)
+}
+
+func TestCollectRenderContexts_whenTableNameIsPattern_thenShouldResolveTablesFromCatalog(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(db)
+
+	_, err = db.Exec(`
+	CREATE TABLE t_user (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP);
+	CREATE TABLE t_order (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP);
+	CREATE TABLE t_order_bak (id INTEGER PRIMARY KEY);
+	CREATE TABLE audit_log (id INTEGER PRIMARY KEY);
+	`)
+	require.NoError(t, err)
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Dsn: "sqlite:" + dbFile,
+				Tables: []*model.TableConfig{
+					{
+						Name:          "/^t_/",
+						Exclude:       []string{"*_bak"},
+						IgnoreColumns: []string{"deleted_at"},
+						Properties:    map[string]string{"prefix": "t_"},
+					},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+	assert.Len(t, contexts, 2)
+
+	names := []string{}
+	for _, ctx := range contexts {
+		names = append(names, ctx.Table.Name)
+		assert.Equal(t, ctx.Table.Name, ctx.TableConfig.Name)
+		assert.Equal(t, "t_", ctx.Properties["prefix"])
+		assert.Len(t, ctx.Table.Columns, 1)
+	}
+	assert.ElementsMatch(t, []string{"t_user", "t_order"}, names)
+}
+
+type ignoreTimestampsIntrospector struct {
+	db.SQLiteIntrospector
+}
+
+func (i ignoreTimestampsIntrospector) GetColumns(ctx context.Context, conn *sql.DB, schema, table string, ignoreColumns []string) ([]*model.Column, error) {
+	return i.SQLiteIntrospector.GetColumns(ctx, conn, schema, table, append(ignoreColumns, "created_at"))
+}
+
+func TestCollectRenderContexts_whenIntrospectorIsRegistered_thenShouldUseIt(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	conn, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	defer func(conn *sql.DB) {
+		err := conn.Close()
+		if err != nil {
+			t.Fatalf("failed to close database: %s", err)
+		}
+	}(conn)
+
+	_, err = conn.Exec(`CREATE TABLE user (id INTEGER PRIMARY KEY, created_at TIMESTAMP);`)
+	require.NoError(t, err)
+
+	db.RegisterIntrospector("sqlite3", ignoreTimestampsIntrospector{})
+	defer db.RegisterIntrospector("sqlite3", db.SQLiteIntrospector{})
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{
+				Name:   "testdb",
+				Dsn:    "sqlite:" + dbFile,
+				Tables: []*model.TableConfig{{Name: "user"}},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	assert.Len(t, contexts[0].Table.Columns, 1)
+	assert.Equal(t, "id", contexts[0].Table.Columns[0].Name)
+}
+
+func TestLoadTemplates_whenScopeMarkerIsSet_thenShouldSetScope(t *testing.T) {
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "dao.go.hbs"), []byte("// @gencoder.generated: {{routine.name}}.go\n// @gencoder.scope: routine\n"), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tempDir, "entity.go.hbs"), []byte("// @gencoder.generated: {{table.name}}.go\n"), 0644)
+	require.NoError(t, err)
+
+	templates, err := LoadFiles(&model.Config{Templates: tempDir})
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, model.FileScopeRoutine, templates[0].Scope)
+	assert.Equal(t, model.FileScopeTable, templates[1].Scope)
+
+	err = os.WriteFile(filepath.Join(tempDir, "bad.go.hbs"), []byte("// @gencoder.generated: x.go\n// @gencoder.scope: view\n"), 0644)
+	require.NoError(t, err)
+	_, err = LoadFiles(&model.Config{Templates: tempDir})
+	assert.ErrorContains(t, err, "unknown template scope")
+}
+
+type routinesIntrospector struct {
+	db.SQLiteIntrospector
+}
+
+func (routinesIntrospector) ListRoutines(context.Context, *sql.DB, string) ([]string, error) {
+	return []string{"add", "sp_get_user", "sp_internal"}, nil
+}
+
+func (routinesIntrospector) GetRoutines(_ context.Context, _ *sql.DB, schema, name string) ([]*model.Routine, error) {
+	if name == "sp_dropped" {
+		return nil, nil
+	}
+	if name == "add" {
+		return []*model.Routine{
+			{Name: name, Schema: schema, SpecificName: "add_1", Kind: model.RoutineKindFunction},
+			{Name: name, Schema: schema, SpecificName: "add_2", Kind: model.RoutineKindFunction},
+		}, nil
+	}
+	return []*model.Routine{{Name: name, Schema: schema, SpecificName: name, Kind: model.RoutineKindProcedure}}, nil
+}
+
+func TestCollectRenderContexts_whenRoutinesAreConfigured_thenShouldCollectRoutineContexts(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	conn, err := sql.Open("sqlite", dbFile)
+	require.NoError(t, err)
+	_, err = conn.Exec(`CREATE TABLE user (id INTEGER PRIMARY KEY);`)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	db.RegisterIntrospector("sqlite3", routinesIntrospector{})
+	defer db.RegisterIntrospector("sqlite3", db.SQLiteIntrospector{})
+
+	cfg := &model.Config{
+		Properties: map[string]string{"k": "global"},
+		Databases: []*model.DatabaseConfig{
+			{
+				Dsn:    "sqlite:" + dbFile,
+				Tables: []*model.TableConfig{{Name: "user"}},
+				Routines: []*model.RoutineConfig{
+					{Name: "sp_*", Exclude: []string{"sp_internal"}, Properties: map[string]string{"k": "sp"}},
+					{Name: "add"},
+				},
+			},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 4)
+	assert.Equal(t, "user", contexts[0].Table.Name)
+	assert.Nil(t, contexts[0].Routine)
+
+	assert.Nil(t, contexts[1].Table)
+	assert.Equal(t, "sp_get_user", contexts[1].Routine.Name)
+	assert.Equal(t, "main", contexts[1].Routine.Schema)
+	assert.Equal(t, "sp", contexts[1].Properties["k"])
+	assert.Equal(t, "sp_get_user", contexts[1].RoutineConfig.Name)
+
+	assert.Equal(t, "add_1", contexts[2].Routine.SpecificName) // overloads get one context each
+	assert.Equal(t, "add_2", contexts[3].Routine.SpecificName)
+	assert.Equal(t, "global", contexts[3].Properties["k"])
+}
+
+func Test_collectRoutineRenderContexts_whenRoutineIsMissing_thenShouldSkipIt(t *testing.T) {
+	source := &schemaSource{
+		introspector: routinesIntrospector{},
+		schema:       func(*model.TableConfig) string { return "main" },
+	}
+	dbCfg := &model.DatabaseConfig{Routines: []*model.RoutineConfig{
+		{Name: "sp_dropped"},
+		{Name: "fn_*"}, // matches nothing
+		{Name: "sp_get_user"},
+	}}
+
+	contexts, err := collectRoutineRenderContexts(&model.Config{}, dbCfg, source)
+
+	require.NoError(t, err)
+	require.Len(t, contexts, 1)
+	assert.Equal(t, "sp_get_user", contexts[0].Routine.Name)
+}
+
+func Test_collectRoutineRenderContexts_whenIntrospectorHasNoRoutines_thenReturnError(t *testing.T) {
+	source := &schemaSource{
+		introspector: db.SQLiteIntrospector{},
+		schema:       func(*model.TableConfig) string { return "main" },
+	}
+	dbCfg := &model.DatabaseConfig{Routines: []*model.RoutineConfig{{Name: "sp_get_user"}}}
+
+	_, err := collectRoutineRenderContexts(&model.Config{}, dbCfg, source)
+
+	assert.EqualError(t, err, "routines are not supported by db.SQLiteIntrospector")
+}
+
+func TestCollectRenderContexts_whenCommentsHaveAnnotations_thenShouldParseThem(t *testing.T) {
+	ddl := filepath.Join(t.TempDir(), "schema.sql")
+	require.NoError(t, os.WriteFile(ddl, []byte(`
+		CREATE TABLE "user" (
+			id BIGINT PRIMARY KEY,
+			name VARCHAR(64) NOT NULL
+		);
+		COMMENT ON TABLE "user" IS 'User account @entity(Account)';
+		COMMENT ON COLUMN "user".name IS 'User name @json(userName) @mask';`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{Dialect: "postgres", DDL: []string{ddl}, Tables: []*model.TableConfig{{Name: "user"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 1)
+	user := contexts[0].Table
+	assert.Equal(t, "User account", *user.Description)
+	assert.Equal(t, map[string]string{"entity": "Account"}, user.Annotations)
+	assert.Equal(t, "User name", *user.Columns[1].Description)
+	assert.Equal(t, map[string]string{"json": "userName", "mask": "true"}, user.Columns[1].Annotations)
+	assert.Empty(t, user.Columns[0].Annotations)
+
+	cfg.Annotation = `#(?P<name>\w+)`
+	contexts = CollectRenderContexts(cfg, nil)
+	assert.Equal(t, "User account @entity(Account)", *contexts[0].Table.Description)
+	assert.Empty(t, contexts[0].Table.Annotations)
+}
+
+func TestCollectRenderContexts_whenMultipleDatabases_thenShouldExposeWholeDatabaseViews(t *testing.T) {
+	dir := t.TempDir()
+	shop := filepath.Join(dir, "shop.sql")
+	require.NoError(t, os.WriteFile(shop, []byte(`
+		CREATE TABLE customer (id BIGINT PRIMARY KEY);
+		CREATE TABLE "order" (id BIGINT PRIMARY KEY, customer_id BIGINT);
+		CREATE SCHEMA archive;
+		CREATE TABLE archive."order" (id BIGINT PRIMARY KEY);`), 0644))
+	auth := filepath.Join(dir, "auth.sql")
+	require.NoError(t, os.WriteFile(auth, []byte(`CREATE TABLE account (id INT PRIMARY KEY);`), 0644))
+
+	cfg := &model.Config{
+		Databases: []*model.DatabaseConfig{
+			{Name: "shop", Dialect: "postgres", DDL: []string{shop}, Tables: []*model.TableConfig{{Name: "*"}, {Schema: "archive", Name: "order"}}},
+			{Name: "auth", Dsn: "mysql://nobody@127.0.0.1:1/auth", DDL: []string{auth}, Tables: []*model.TableConfig{{Name: "account"}}},
+		},
+	}
+
+	contexts := CollectRenderContexts(cfg, nil)
+
+	require.Len(t, contexts, 4)
+	shopDB := contexts[0].Database
+	assert.Equal(t, "shop", shopDB.Name)
+	assert.Equal(t, "postgres", shopDB.Dialect)
+	var names []string
+	for _, table := range shopDB.Tables {
+		names = append(names, table.Schema+"."+table.Name)
+	}
+	assert.Equal(t, []string{"archive.order", "public.customer", "public.order"}, names)
+	assert.Same(t, shopDB.Tables[1], shopDB.TableMap["customer"])
+	assert.Same(t, shopDB.Tables[0], shopDB.TableMap["archive.order"]) // order is used in two schemas
+	assert.NotContains(t, shopDB.TableMap, "order")
+
+	authDB := contexts[3].Database
+	assert.Equal(t, "mysql", authDB.Dialect)
+	assert.Len(t, authDB.Tables, 1)
+	for _, ctx := range contexts {
+		assert.Len(t, ctx.AllTables, 4)
+	}
+	assert.Same(t, shopDB, contexts[1].Database)
+}